@@ -2,6 +2,7 @@ package action
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/gopasspw/gopass/internal/backend"
 	"github.com/urfave/cli/v2"
@@ -132,6 +133,19 @@ func (s *Action) GetCommands() []*cli.Command {
 				},
 			},
 		},
+		{
+			Name:      "combine",
+			Usage:     "Restore a secret from its shares",
+			ArgsUsage: "[secret]",
+			Description: "" +
+				"This command reconstructs a secret that was split with 'gopass split'. " +
+				"All shares that can be decrypted with the available keys are used, " +
+				"any missing shares are requested interactively. The restored secret " +
+				"is printed to stdout and not written to the store.",
+			Before:       s.IsInitialized,
+			Action:       s.Combine,
+			BashComplete: s.Complete,
+		},
 		{
 			Name:      "config",
 			Usage:     "Display and edit the configuration file",
@@ -140,9 +154,16 @@ func (s *Action) GetCommands() []*cli.Command {
 				"This command allows for easy printing and editing of the configuration. " +
 				"Without argument, the entire config is printed. " +
 				"With a single argument, a single key can be printed. " +
-				"With two arguments a setting specified by key can be set to value.",
+				"With two arguments a setting specified by key can be set to value. " +
+				"With --store the settings of a single mount are displayed or overridden.",
 			Action:       s.Config,
 			BashComplete: s.ConfigComplete,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "store",
+					Usage: "Display or override the settings of this mount. Use an empty value to remove an override",
+				},
+			},
 		},
 		{
 			Name:        "convert",
@@ -240,6 +261,52 @@ func (s *Action) GetCommands() []*cli.Command {
 				},
 			},
 		},
+		{
+			Name:  "docker-credential",
+			Usage: "Use gopass as docker credential helper",
+			Description: "" +
+				"These commands implement the docker credential helper protocol. Credentials " +
+				"are stored below the prefix, named after the registry. Install gopass (or a " +
+				"symlink to it) as docker-credential-gopass and set \"credsStore\": \"gopass\" " +
+				"in ~/.docker/config.json to use it.",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "prefix",
+					Usage: "Subtree containing the credentials",
+					Value: "docker",
+				},
+			},
+			Subcommands: []*cli.Command{
+				{
+					Name:        "store",
+					Usage:       "Store the credentials read from stdin",
+					Description: "Reads a JSON encoded credential from stdin and stores it in the password store.",
+					Before:      s.IsInitialized,
+					Action:      s.DockerCredentialStore,
+				},
+				{
+					Name:        "get",
+					Usage:       "Print the credentials for a registry",
+					Description: "Reads a registry URL from stdin and prints the JSON encoded credential.",
+					Before:      s.IsInitialized,
+					Action:      s.DockerCredentialGet,
+				},
+				{
+					Name:        "erase",
+					Usage:       "Remove the credentials for a registry",
+					Description: "Reads a registry URL from stdin and removes the stored credential.",
+					Before:      s.IsInitialized,
+					Action:      s.DockerCredentialErase,
+				},
+				{
+					Name:        "list",
+					Usage:       "List all registries with stored credentials",
+					Description: "Prints a JSON object mapping registry URLs to usernames.",
+					Before:      s.IsInitialized,
+					Action:      s.DockerCredentialList,
+				},
+			},
+		},
 		{
 			Name:      "edit",
 			Usage:     "Edit new or existing secrets",
@@ -265,17 +332,44 @@ func (s *Action) GetCommands() []*cli.Command {
 					Aliases: []string{"c"},
 					Usage:   "Create a new secret if none found",
 				},
+				&cli.BoolFlag{
+					Name:    "force",
+					Aliases: []string{"f"},
+					Usage:   "Override the password policy if the store allows it",
+				},
 			},
 		},
 		{
-			Name:         "env",
-			Usage:        "Run a subprocess with a pre-populated environment",
-			ArgsUsage:    "[secret] [command and args...]",
-			Description:  "This command runs a sub process with the environment populated from the keys of a secret.",
+			Name:      "env",
+			Usage:     "Run a subprocess with a pre-populated environment",
+			ArgsUsage: "[secret] [command and args...]",
+			Description: "" +
+				"This command runs a sub process with the environment populated from the keys of a secret. " +
+				"Fields of secrets can be mapped to environment variables with --map or a manifest file. " +
+				"If neither a secret nor a mapping is given the manifest is read from .gopass-env in the current directory. " +
+				"With --export or --dotenv the variables are printed instead of running a command.",
 			Before:       s.IsInitialized,
 			Action:       s.Env,
 			BashComplete: s.Complete,
 			Hidden:       true,
+			Flags: []cli.Flag{
+				&cli.StringSliceFlag{
+					Name:  "map",
+					Usage: "Map a field of a secret to an environment variable, e.g. DB_USER=prod/db:username. Can be given multiple times",
+				},
+				&cli.StringFlag{
+					Name:  "manifest",
+					Usage: "Read mappings (one KEY=secret:field per line) from FILE",
+				},
+				&cli.BoolFlag{
+					Name:  "export",
+					Usage: "Print shell export statements instead of running a command, e.g. for eval or .envrc",
+				},
+				&cli.BoolFlag{
+					Name:  "dotenv",
+					Usage: "Print the variables in dotenv format instead of running a command",
+				},
+			},
 		},
 		{
 			Name:      "find",
@@ -317,6 +411,11 @@ func (s *Action) GetCommands() []*cli.Command {
 					Name:  "decrypt",
 					Usage: "Decrypt and reencryt during fsck.\nWARNING: This will update the secret content to the latest format. This might be incompatible with other implementations. Use with caution!",
 				},
+				&cli.IntFlag{
+					Name:  "expiry-days",
+					Usage: "Warn about recipient keys expiring within this many days",
+					Value: 30,
+				},
 			},
 		},
 		{
@@ -375,7 +474,7 @@ func (s *Action) GetCommands() []*cli.Command {
 				&cli.BoolFlag{
 					Name:    "force",
 					Aliases: []string{"f"},
-					Usage:   "Force to overwrite existing password",
+					Usage:   "Force to overwrite existing password and override the password policy if the store allows it",
 				},
 				&cli.BoolFlag{
 					Name:    "edit",
@@ -390,12 +489,37 @@ func (s *Action) GetCommands() []*cli.Command {
 				&cli.StringFlag{
 					Name:    "generator",
 					Aliases: []string{"g"},
-					Usage:   "Choose a password generator, use one of: cryptic, memorable, xkcd or external. Default: cryptic",
+					Usage:   "Choose a password generator, use one of: cryptic, memorable, xkcd, pattern or external. Default: cryptic",
+				},
+				&cli.StringFlag{
+					Name:  "pattern",
+					Usage: "Pattern for the pattern generator, e.g. 'a{4}-d{4}' for four letters, a dash and four digits",
 				},
 				&cli.BoolFlag{
 					Name:  "strict",
 					Usage: "Require strict character class rules",
 				},
+				&cli.BoolFlag{
+					Name:  "derived",
+					Usage: "Store only the parameters of a password derived from --master, --site, --login and --counter. It's computed on the fly by show",
+				},
+				&cli.StringFlag{
+					Name:  "master",
+					Usage: "Secret holding the master secret of derived passwords",
+				},
+				&cli.StringFlag{
+					Name:  "site",
+					Usage: "Site of derived passwords. Default: the last element of the secret name",
+				},
+				&cli.StringFlag{
+					Name:  "login",
+					Usage: "Login of derived passwords",
+				},
+				&cli.IntFlag{
+					Name:  "counter",
+					Usage: "Counter of derived passwords, increment it to change the password",
+					Value: 1,
+				},
 				&cli.StringFlag{
 					Name:    "sep",
 					Aliases: []string{"xkcdsep", "xs"},
@@ -527,6 +651,44 @@ func (s *Action) GetCommands() []*cli.Command {
 				},
 			},
 		},
+		{
+			Name:  "git-credential",
+			Usage: "Use gopass as git credential helper",
+			Description: "" +
+				"These commands implement the git credential helper protocol. Credentials " +
+				"are stored as <prefix>/<protocol>/<host>[/<path>]/<username>. Configure git " +
+				"with 'git config --global credential.helper \"!gopass git-credential\"'.",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "prefix",
+					Usage: "Subtree containing the credentials",
+					Value: "git",
+				},
+			},
+			Subcommands: []*cli.Command{
+				{
+					Name:        "get",
+					Usage:       "Print the credential for the host read from stdin",
+					Description: "Reads a git credential request from stdin and prints the matching username and password.",
+					Before:      s.IsInitialized,
+					Action:      s.GitCredentialGet,
+				},
+				{
+					Name:        "store",
+					Usage:       "Store the credential read from stdin",
+					Description: "Reads a git credential from stdin and stores it in the password store.",
+					Before:      s.IsInitialized,
+					Action:      s.GitCredentialStore,
+				},
+				{
+					Name:        "erase",
+					Usage:       "Remove the credential read from stdin",
+					Description: "Reads a git credential from stdin and removes it from the password store.",
+					Before:      s.IsInitialized,
+					Action:      s.GitCredentialErase,
+				},
+			},
+		},
 		{
 			Name:      "grep",
 			Usage:     "Search for secrets files containing search-string when decrypted.",
@@ -617,7 +779,7 @@ func (s *Action) GetCommands() []*cli.Command {
 				&cli.BoolFlag{
 					Name:    "force",
 					Aliases: []string{"f"},
-					Usage:   "Overwrite any existing secret and do not prompt to confirm recipients",
+					Usage:   "Overwrite any existing secret, do not prompt to confirm recipients and override the password policy if the store allows it",
 				},
 				&cli.BoolFlag{
 					Name:    "append",
@@ -626,6 +788,63 @@ func (s *Action) GetCommands() []*cli.Command {
 				},
 			},
 		},
+		{
+			Name:  "k8s",
+			Usage: "Convert secrets to and from Kubernetes manifests",
+			Description: "" +
+				"These commands render Kubernetes Secret or ConfigMap manifests from " +
+				"secrets and import existing manifests into the store.",
+			Subcommands: []*cli.Command{
+				{
+					Name:      "export",
+					Usage:     "Print a Secret or ConfigMap manifest",
+					ArgsUsage: "<secret|folder>",
+					Description: "" +
+						"This command prints a manifest containing the password and all fields " +
+						"of a secret, or of all secrets below a folder. Keys of secrets below " +
+						"a folder are prefixed with their relative name.",
+					Before:       s.IsInitialized,
+					Action:       s.K8sExport,
+					BashComplete: s.Complete,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "name",
+							Usage: "Name of the Secret. Defaults to the name of the secret or folder",
+						},
+						&cli.StringFlag{
+							Name:  "namespace",
+							Usage: "Namespace of the Secret",
+						},
+						&cli.StringFlag{
+							Name:  "type",
+							Usage: "Type of the Secret",
+							Value: "Opaque",
+						},
+						&cli.BoolFlag{
+							Name:  "configmap",
+							Usage: "Render a ConfigMap instead of a Secret",
+						},
+					},
+				},
+				{
+					Name:      "import",
+					Usage:     "Import Secrets and ConfigMaps from a manifest",
+					ArgsUsage: "<manifest|-> [folder]",
+					Description: "" +
+						"This command creates a secret for every Secret or ConfigMap found in the " +
+						"manifest. Every key becomes a field, the password key becomes the password.",
+					Before: s.IsInitialized,
+					Action: s.K8sImport,
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:    "force",
+							Aliases: []string{"f"},
+							Usage:   "Overwrite existing secrets",
+						},
+					},
+				},
+			},
+		},
 		{
 			Name:      "link",
 			Usage:     "Create a symlink",
@@ -673,6 +892,27 @@ func (s *Action) GetCommands() []*cli.Command {
 				},
 			},
 		},
+		{
+			Name:  "log",
+			Usage: "Show audit logs",
+			Description: "" +
+				"This command displays the audit logs of the password stores.",
+			Subcommands: []*cli.Command{
+				{
+					Name:      "access",
+					Usage:     "Show and verify the access log",
+					ArgsUsage: "[secret]",
+					Description: "" +
+						"This command decrypts, verifies and displays the access log. " +
+						"The log must be enabled with 'gopass config accesslog local' or " +
+						"'gopass config accesslog store'. If a secret is given only " +
+						"accesses to this secret (or folder) are shown.",
+					Before:       s.IsInitialized,
+					Action:       s.LogAccess,
+					BashComplete: s.Complete,
+				},
+			},
+		},
 		{
 			Name:      "move",
 			Aliases:   []string{"mv"},
@@ -754,6 +994,119 @@ func (s *Action) GetCommands() []*cli.Command {
 					Usage:   "Only display the token",
 				},
 			},
+			Subcommands: []*cli.Command{
+				{
+					Name:      "enroll",
+					Usage:     "Store an OTP URL read from a QR code image",
+					ArgsUsage: "[secret]",
+					Description: "" +
+						"Decodes the QR code in a PNG, JPEG or GIF image and stores the OTP URL " +
+						"in the given secret. The first token is printed to confirm the enrollment. " +
+						"Google Authenticator exports (otpauth-migration://) are imported into one " +
+						"secret per token below the given name.",
+					Before:       s.IsInitialized,
+					Action:       s.OTPEnroll,
+					BashComplete: s.Complete,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "qr-image",
+							Usage: "Read the QR code from FILE",
+						},
+						&cli.BoolFlag{
+							Name:    "force",
+							Aliases: []string{"f"},
+							Usage:   "Overwrite existing OTP URLs",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:  "pwrules",
+			Usage: "Manage password rules",
+			Description: "" +
+				"This command lists the custom password rules of the user and of all stores. " +
+				"Password rules constrain the passwords generated for a domain, e.g. " +
+				"'minlength: 8; maxlength: 16; required: digit;' or 'pattern: a{4}-d{4};'. " +
+				"Rules of the user take precedence over rules of the stores, which take " +
+				"precedence over the built-in rules.",
+			Action: s.PwrulesList,
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "all",
+					Usage: "Include the built-in rules",
+				},
+			},
+			Subcommands: []*cli.Command{
+				{
+					Name:        "list",
+					Usage:       "List password rules",
+					Description: "Lists the custom password rules of the user and of all stores.",
+					Action:      s.PwrulesList,
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:  "all",
+							Usage: "Include the built-in rules",
+						},
+					},
+				},
+				{
+					Name:      "add",
+					Usage:     "Add or replace a password rule",
+					ArgsUsage: "<domain> <rule>",
+					Description: "" +
+						"Adds a password rule for the domain to the rules of the user or, " +
+						"with --store, to the rules of a store. The store rules are committed " +
+						"to the store so all members of a team share them.",
+					Action: s.PwrulesAdd,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "store",
+							Usage: "Store to operate on",
+						},
+					},
+				},
+				{
+					Name:        "remove",
+					Aliases:     []string{"rm"},
+					Usage:       "Remove a password rule",
+					ArgsUsage:   "<domain>",
+					Description: "Removes a password rule of the user or, with --store, of a store.",
+					Action:      s.PwrulesRemove,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "store",
+							Usage: "Store to operate on",
+						},
+					},
+				},
+				{
+					Name:        "test",
+					Usage:       "Show the rule for a domain and a sample password",
+					ArgsUsage:   "<domain> [length]",
+					Description: "Shows the effective password rule for the domain, where it comes from and a sample password.",
+					Action:      s.PwrulesTest,
+				},
+			},
+		},
+		{
+			Name:  "rekey",
+			Usage: "Rotate the data key of a store",
+			Description: "" +
+				"This command generates a new data key and re-encrypts all secrets of a store " +
+				"with it. If the store does not use envelope encryption yet, it is converted. " +
+				"With envelope encryption secrets are encrypted with a symmetric data key and " +
+				"only that key is encrypted for the recipients, so adding or removing recipients " +
+				"does not require re-encrypting every secret. Run this after removing a recipient " +
+				"to make sure they can't decrypt any new or changed secrets.",
+			Before: s.IsInitialized,
+			Action: s.Rekey,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "store",
+					Usage: "Store to operate on",
+				},
+			},
 		},
 		{
 			Name:  "recipients",
@@ -814,6 +1167,168 @@ func (s *Action) GetCommands() []*cli.Command {
 						},
 					},
 				},
+				{
+					Name:  "group",
+					Usage: "Manage named recipient groups",
+					Description: "" +
+						"Recipient groups are defined once per store and can be referenced " +
+						"from any recipients file as @name. Changing the members of a group " +
+						"will re-encrypt all secrets in folders that use this group.",
+					Before: s.IsInitialized,
+					Action: s.RecipientsGroupList,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "store",
+							Usage: "Store to operate on",
+						},
+					},
+					Subcommands: []*cli.Command{
+						{
+							Name:        "list",
+							Aliases:     []string{"ls"},
+							Usage:       "List all recipient groups",
+							Description: "List all recipient groups of a store and their members",
+							Before:      s.IsInitialized,
+							Action:      s.RecipientsGroupList,
+							Flags: []cli.Flag{
+								&cli.StringFlag{
+									Name:  "store",
+									Usage: "Store to operate on",
+								},
+							},
+						},
+						{
+							Name:      "add",
+							Usage:     "Add recipients to a group",
+							ArgsUsage: "[group] [recipient...]",
+							Description: "" +
+								"Add any number of recipients to a group, creating the group if " +
+								"it doesn't exist yet.",
+							Before: s.IsInitialized,
+							Action: s.RecipientsGroupAdd,
+							Flags: []cli.Flag{
+								&cli.StringFlag{
+									Name:  "store",
+									Usage: "Store to operate on",
+								},
+								&cli.BoolFlag{
+									Name:  "force",
+									Usage: "Force adding non-existing keys",
+								},
+							},
+						},
+						{
+							Name:      "remove",
+							Aliases:   []string{"rm"},
+							Usage:     "Remove recipients from a group",
+							ArgsUsage: "[group] [recipient...]",
+							Description: "" +
+								"Remove any number of recipients from a group. A group that is " +
+								"still referenced can not be emptied.",
+							Before: s.IsInitialized,
+							Action: s.RecipientsGroupRemove,
+							Flags: []cli.Flag{
+								&cli.StringFlag{
+									Name:  "store",
+									Usage: "Store to operate on",
+								},
+							},
+						},
+					},
+				},
+				{
+					Name:  "sign",
+					Usage: "Sign the recipients files",
+					Description: "" +
+						"This command signs all recipients files of a store with your key. " +
+						"Use it to accept changes made by someone else after reviewing them.",
+					Before: s.IsInitialized,
+					Action: s.RecipientsSign,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "store",
+							Usage: "Store to operate on",
+						},
+					},
+				},
+				{
+					Name:  "verify",
+					Usage: "Verify the signatures of the recipients files",
+					Description: "" +
+						"This command checks that all recipients files of a store are signed " +
+						"by a trusted recipient. Secrets can not be written as long as any of " +
+						"them is invalid.",
+					Before: s.IsInitialized,
+					Action: s.RecipientsVerify,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "store",
+							Usage: "Store to operate on",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:      "render",
+			Usage:     "Render a template with secrets to a file",
+			ArgsUsage: "<template>",
+			Description: "" +
+				"This command renders a Go text/template against the store, e.g. to write " +
+				"configuration files containing secrets. Secrets are accessed with the " +
+				"template functions get, getpw, getval and getvals. Any missing secret fails " +
+				"the rendering. The output is written with mode 0600. With --watch the " +
+				"template is rendered again whenever the store or the template changes.",
+			Before: s.IsInitialized,
+			Action: s.Render,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    "output",
+					Aliases: []string{"o"},
+					Usage:   "Write the output to FILE instead of stdout",
+				},
+				&cli.BoolFlag{
+					Name:    "watch",
+					Aliases: []string{"w"},
+					Usage:   "Render again whenever the store changes",
+				},
+				&cli.DurationFlag{
+					Name:  "interval",
+					Usage: "How often to check the store for changes in watch mode",
+					Value: 2 * time.Second,
+				},
+			},
+		},
+		{
+			Name:  "serve",
+			Usage: "Serve the store through an HTTP API",
+			Description: "" +
+				"This command exposes the store through a HashiCorp Vault KV v2 compatible " +
+				"HTTP API on localhost, so tools that speak the Vault API can read and write " +
+				"secrets. Requests must carry the token printed on startup. Versions are " +
+				"mapped to the revisions of the storage backend.",
+			Before: s.IsInitialized,
+			Action: s.Serve,
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "vault-compat",
+					Usage: "Serve the Vault KV v2 API",
+				},
+				&cli.StringFlag{
+					Name:  "addr",
+					Usage: "Address to listen on. Only loopback addresses are allowed",
+					Value: "127.0.0.1:8200",
+				},
+				&cli.StringFlag{
+					Name:    "token",
+					Usage:   "Token clients must provide. A random token is generated if empty",
+					EnvVars: []string{"GOPASS_SERVE_TOKEN"},
+				},
+				&cli.StringFlag{
+					Name:  "mount",
+					Usage: "Path the secrets engine is mounted at",
+					Value: "secret",
+				},
 			},
 		},
 		{
@@ -822,7 +1337,8 @@ func (s *Action) GetCommands() []*cli.Command {
 			Description: "" +
 				"This command is automatically invoked if gopass is started without any " +
 				"existing password store. This command exists so users can be provided with " +
-				"simple one-command setup instructions.",
+				"simple one-command setup instructions. Use --answers to provision gopass " +
+				"without any prompts.",
 			Action: s.Setup,
 			Flags: []cli.Flag{
 				&cli.StringFlag{
@@ -853,6 +1369,10 @@ func (s *Action) GetCommands() []*cli.Command {
 					Name:  "storage",
 					Usage: fmt.Sprintf("Select storage backend %v", backend.StorageBackends()),
 				},
+				&cli.StringFlag{
+					Name:  "answers",
+					Usage: "Run unattended using the answers from this YAML file (- for stdin)",
+				},
 			},
 		},
 		{
@@ -867,6 +1387,71 @@ func (s *Action) GetCommands() []*cli.Command {
 			BashComplete: s.Complete,
 			Flags:        ShowFlags(),
 		},
+		{
+			Name:      "split",
+			Usage:     "Split a secret into shares for several recipients",
+			ArgsUsage: "[secret]",
+			Description: "" +
+				"This command splits a secret into shares using Shamir's secret sharing. " +
+				"Each share is encrypted for exactly one recipient and stored below " +
+				"'<secret>.shares/'. Any threshold of them can restore the secret " +
+				"with 'gopass combine'.",
+			Before:       s.IsInitialized,
+			Action:       s.Split,
+			BashComplete: s.Complete,
+			Flags: []cli.Flag{
+				&cli.IntFlag{
+					Name:  "shares",
+					Usage: "Number of shares (default: number of recipients)",
+				},
+				&cli.IntFlag{
+					Name:  "threshold",
+					Usage: "Number of shares required to restore the secret",
+					Value: 2,
+				},
+				&cli.StringSliceFlag{
+					Name:  "to",
+					Usage: "Recipient for one share, can be given multiple times",
+				},
+				&cli.BoolFlag{
+					Name:  "force",
+					Usage: "Use recipients even if their public key is not available",
+				},
+				&cli.BoolFlag{
+					Name:  "delete",
+					Usage: "Remove the original secret after splitting it",
+				},
+			},
+		},
+		{
+			Name:  "ssh-agent",
+			Usage: "Serve SSH keys from the store",
+			Description: "" +
+				"This command runs an ssh-agent that serves the private keys stored below " +
+				"a prefix. Keys are decrypted on demand and never written to disk. " +
+				"Point SSH_AUTH_SOCK to the socket printed on startup to use it.",
+			Before: s.IsInitialized,
+			Action: s.SSHAgent,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "prefix",
+					Usage: "Subtree containing the private keys",
+					Value: "ssh",
+				},
+				&cli.StringFlag{
+					Name:  "socket",
+					Usage: "Path of the agent socket",
+				},
+				&cli.BoolFlag{
+					Name:  "confirm",
+					Usage: "Ask for confirmation before every use of a key",
+				},
+				&cli.DurationFlag{
+					Name:  "lifetime",
+					Usage: "Stop serving a key after it has been available for this long, e.g. 1h",
+				},
+			},
+		},
 		{
 			Name:      "sum",
 			Usage:     "Compute the SHA256 checksum",
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sort"
 
+	"github.com/gopasspw/gopass/internal/config"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 
@@ -14,6 +15,10 @@ import (
 // Config handles changes to the gopass configuration
 func (s *Action) Config(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
+	if c.IsSet("store") {
+		return s.configMount(ctx, c)
+	}
+
 	if c.Args().Len() < 1 {
 		s.printConfigValues(ctx)
 		return nil
@@ -34,9 +39,79 @@ func (s *Action) Config(c *cli.Context) error {
 	return nil
 }
 
+// configMount handles the per-mount overrides
+func (s *Action) configMount(ctx context.Context, c *cli.Context) error {
+	alias := c.String("store")
+	if _, found := s.cfg.Mounts[alias]; !found {
+		return ExitError(ExitMount, nil, "Mount %q does not exist", alias)
+	}
+
+	switch c.Args().Len() {
+	case 0:
+		s.printMountConfigValues(ctx, alias)
+		return nil
+	case 1:
+		s.printMountConfigValues(ctx, alias, c.Args().Get(0))
+		return nil
+	case 2:
+	default:
+		return ExitError(ExitUsage, nil, "Usage: %s config --store mount key value", s.Name)
+	}
+
+	key := c.Args().Get(0)
+	if err := s.cfg.SetMountConfigValue(alias, key, c.Args().Get(1)); err != nil {
+		return ExitError(ExitUnknown, err, "Error setting config value: %s", err)
+	}
+	s.printMountConfigValues(ctx, alias, key)
+	return nil
+}
+
+func (s *Action) printMountConfigValues(ctx context.Context, alias string, needles ...string) {
+	cfg, origins := s.effectiveConfig(ctx, alias)
+	m := cfg.MountConfigMap("")
+	for _, k := range filterMap(m, needles) {
+		if o, found := origins[k]; found {
+			out.Printf(ctx, "%s: %s (%s)", k, m[k], o)
+			continue
+		}
+		out.Printf(ctx, "%s: %s", k, m[k])
+	}
+}
+
+// effectiveConfig returns the config for the given mount and the origin of
+// every value that is not taken from the global config
+func (s *Action) effectiveConfig(ctx context.Context, alias string) (*config.Config, map[string]string) {
+	cfg := s.cfg.ForMount(alias)
+	origins := make(map[string]string, len(config.MountConfigKeys()))
+	for _, k := range s.cfg.Overrides[alias].Keys() {
+		origins[k] = "mount override"
+	}
+
+	// the policy is only available if the store is mounted
+	if alias != "" && !contains(s.Store.MountPoints(), alias) {
+		return cfg, origins
+	}
+	p, err := s.Store.Policy(ctx, alias)
+	if err != nil {
+		out.Warningf(ctx, "Failed to load store policy: %s", err)
+		return cfg, origins
+	}
+	for _, k := range p.Overrides(cfg) {
+		origins[k] = "store policy"
+	}
+	return p.Apply(cfg), origins
+}
+
 func (s *Action) printConfigValues(ctx context.Context, needles ...string) {
 	m := s.cfg.ConfigMap()
+	// the policy of the root store may override some values
+	cfg, origins := s.effectiveConfig(ctx, "")
+	eff := cfg.ConfigMap()
 	for _, k := range filterMap(m, needles) {
+		if o, found := origins[k]; found {
+			out.Printf(ctx, "%s: %s (%s, local: %s)", k, eff[k], o, m[k])
+			continue
+		}
 		out.Printf(ctx, "%s: %s", k, m[k])
 	}
 	for alias, path := range s.cfg.Mounts {
@@ -92,7 +167,11 @@ func (s *Action) configKeys() []string {
 
 // ConfigComplete will print the list of valid config keys
 func (s *Action) ConfigComplete(c *cli.Context) {
-	for _, k := range s.configKeys() {
+	keys := s.configKeys()
+	if c.IsSet("store") {
+		keys = config.MountConfigKeys()
+	}
+	for _, k := range keys {
 		fmt.Fprintln(stdout, k)
 	}
 }
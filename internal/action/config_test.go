@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"context"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store/leaf"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/tests/gptest"
 
@@ -38,10 +40,13 @@ func TestConfig(t *testing.T) {
 
 		c := gptest.CliCtx(ctx, t)
 		assert.NoError(t, act.Config(c))
-		want := `autoclip: true
+		want := `accesslog: 
+autoclip: true
 autoimport: true
+clipboard: 
 cliptimeout: 45
 exportkeys: true
+minentropy: 0
 nocolor: false
 nopager: false
 notifications: true
@@ -79,10 +84,13 @@ parsing: true
 		defer buf.Reset()
 
 		act.printConfigValues(ctx)
-		want := `autoclip: true
+		want := `accesslog: 
+autoclip: true
 autoimport: true
+clipboard: 
 cliptimeout: 45
 exportkeys: true
+minentropy: 0
 nocolor: false
 nopager: true
 notifications: true
@@ -115,10 +123,13 @@ parsing: true
 		defer buf.Reset()
 
 		act.ConfigComplete(gptest.CliCtx(ctx, t))
-		want := `autoclip
+		want := `accesslog
+autoclip
 autoimport
+clipboard
 cliptimeout
 exportkeys
+minentropy
 nocolor
 nopager
 notifications
@@ -136,4 +147,44 @@ safecontent
 		c := gptest.CliCtx(ctx, t, "autoimport", "false", "42")
 		assert.Error(t, act.Config(c))
 	})
+
+	t.Run("store policy", func(t *testing.T) {
+		defer buf.Reset()
+
+		fn := filepath.Join(u.StoreDir(""), leaf.PolicyFile)
+		require.NoError(t, os.WriteFile(fn, []byte("maxcliptimeout: 10\n"), 0600))
+		defer func() {
+			_ = os.Remove(fn)
+		}()
+
+		assert.NoError(t, act.Config(gptest.CliCtx(ctx, t, "cliptimeout")))
+		assert.Equal(t, "cliptimeout: 10 (store policy, local: 45)", strings.TrimSpace(buf.String()))
+	})
+
+	t.Run("per mount overrides", func(t *testing.T) {
+		defer buf.Reset()
+
+		act.cfg.Mounts["team"] = u.StoreDir("team")
+		defer delete(act.cfg.Mounts, "team")
+
+		assert.Error(t, act.Config(gptest.CliCtxWithFlags(ctx, t, map[string]string{"store": "other"})))
+		assert.Error(t, act.Config(gptest.CliCtxWithFlags(ctx, t, map[string]string{"store": "team"}, "path", "/tmp")))
+		buf.Reset()
+
+		assert.NoError(t, act.Config(gptest.CliCtxWithFlags(ctx, t, map[string]string{"store": "team"}, "cliptimeout", "10")))
+		assert.Equal(t, "cliptimeout: 10 (mount override)", strings.TrimSpace(buf.String()))
+		buf.Reset()
+
+		assert.NoError(t, act.Config(gptest.CliCtxWithFlags(ctx, t, map[string]string{"store": "team"})))
+		want := `autoclip: true
+cliptimeout: 10 (mount override)
+notifications: true
+parsing: true
+safecontent: false`
+		assert.Equal(t, want, strings.TrimSpace(buf.String()))
+		buf.Reset()
+
+		assert.NoError(t, act.Config(gptest.CliCtxWithFlags(ctx, t, map[string]string{"store": "team"}, "cliptimeout", "")))
+		assert.Equal(t, "cliptimeout: 45", strings.TrimSpace(buf.String()))
+	})
 }
@@ -17,6 +17,7 @@ import (
 	"github.com/gopasspw/gopass/pkg/gopass/secrets"
 	"github.com/gopasspw/gopass/pkg/pwgen"
 	"github.com/gopasspw/gopass/pkg/pwgen/pwrules"
+	"github.com/gopasspw/gopass/pkg/pwgen/xkcdgen"
 	"github.com/gopasspw/gopass/pkg/termio"
 	"github.com/martinhoefling/goxkcdpwgen/xkcdpwgen"
 	"github.com/urfave/cli/v2"
@@ -33,6 +34,7 @@ func fmtfn(d int, n string, t string) string {
 // Create displays the password creation wizard
 func (s *Action) Create(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
+	s.loadStoreRules(ctx)
 
 	out.Printf(ctx, "🌟 Welcome to the secret creation wizard (gopass create)!")
 	out.Printf(ctx, "🧪 Hint: Use 'gopass edit -c' for more control!")
@@ -103,7 +105,7 @@ func (s *Action) createWebsite(ctx context.Context, c *cli.Context) error {
 
 	var password string
 	if genPw {
-		password, err = s.createGeneratePassword(ctx, hostname)
+		password, err = s.createGeneratePassword(ctx, store, hostname)
 		if err != nil {
 			return err
 		}
@@ -176,7 +178,7 @@ func (s *Action) createPrintOrCopy(ctx context.Context, c *cli.Context, name, pa
 		return nil
 	}
 
-	if err := clipboard.CopyTo(ctx, name, []byte(password), s.cfg.ClipTimeout); err != nil {
+	if err := clipboard.CopyTo(ctx, name, []byte(password), s.Store.Config(ctx, name).ClipTimeout); err != nil {
 		return ExitError(ExitIO, err, "failed to copy to clipboard: %s", err)
 	}
 	return nil
@@ -291,7 +293,7 @@ func (s *Action) createGeneric(ctx context.Context, c *cli.Context) error {
 
 	var password string
 	if genPw {
-		password, err = s.createGeneratePassword(ctx, "")
+		password, err = s.createGeneratePassword(ctx, store, "")
 		if err != nil {
 			return err
 		}
@@ -356,14 +358,19 @@ func (s *Action) createGeneric(ctx context.Context, c *cli.Context) error {
 }
 
 // createGeneratePasssword will walk through the password generation steps
-func (s *Action) createGeneratePassword(ctx context.Context, hostname string) (string, error) {
+func (s *Action) createGeneratePassword(ctx context.Context, store, hostname string) (string, error) {
+	length := s.defaultLengthFor(ctx, store)
 	if _, found := pwrules.LookupRule(hostname); found {
 		out.Noticef(ctx, "Using password rules for %s ...", hostname)
-		length, err := termio.AskForInt(ctx, fmtfn(4, "b", "How long?"), defaultLength)
+		length, err := termio.AskForInt(ctx, fmtfn(4, "b", "How long?"), length)
 		if err != nil {
 			return "", err
 		}
-		return pwgen.NewCrypticForDomain(length, hostname).Password(), nil
+		g := pwgen.NewCrypticForDomain(length, hostname)
+		if err := s.checkEntropy(ctx, g.Entropy()); err != nil {
+			return "", err
+		}
+		return g.Password(), nil
 	}
 	xkcd, err := termio.AskForBool(ctx, fmtfn(4, "a", "Human-pronounceable passphrase?"), false)
 	if err != nil {
@@ -374,6 +381,13 @@ func (s *Action) createGeneratePassword(ctx context.Context, hostname string) (s
 		if err != nil {
 			return "", err
 		}
+		bits, err := xkcdgen.Entropy(length, "en")
+		if err != nil {
+			return "", err
+		}
+		if err := s.checkEntropy(ctx, bits); err != nil {
+			return "", err
+		}
 		g := xkcdpwgen.NewGenerator()
 		g.SetNumWords(length)
 		g.SetDelimiter(" ")
@@ -381,7 +395,7 @@ func (s *Action) createGeneratePassword(ctx context.Context, hostname string) (s
 		return string(g.GeneratePassword()), nil
 	}
 
-	length, err := termio.AskForInt(ctx, fmtfn(4, "b", "How long?"), defaultLength)
+	length, err = termio.AskForInt(ctx, fmtfn(4, "b", "How long?"), length)
 	if err != nil {
 		return "", err
 	}
@@ -396,9 +410,15 @@ func (s *Action) createGeneratePassword(ctx context.Context, hostname string) (s
 		return "", err
 	}
 	if corp {
+		if err := s.checkEntropy(ctx, pwgen.NewCrypticWithAllClasses(length, symbols).Entropy()); err != nil {
+			return "", err
+		}
 		return pwgen.GeneratePasswordWithAllClasses(length, symbols)
 	}
 
+	if err := s.checkEntropy(ctx, pwgen.PasswordEntropy(length, symbols)); err != nil {
+		return "", err
+	}
 	return pwgen.GeneratePassword(length, symbols), nil
 }
 
@@ -409,5 +429,7 @@ func (s *Action) createGeneratePIN(ctx context.Context) (string, error) {
 		return "", err
 	}
 
+	// PINs are short by design, so minentropy does not apply
+	out.Noticef(ctx, "Generating a PIN with %.1f bits of entropy", pwgen.CharsetEntropy(length, pwgen.Digits))
 	return pwgen.GeneratePasswordCharset(length, "0123456789"), nil
 }
@@ -0,0 +1,173 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets/secparse"
+	"github.com/gopasspw/gopass/pkg/pwgen"
+	"github.com/gopasspw/gopass/pkg/termio"
+	"github.com/urfave/cli/v2"
+)
+
+// Keys of the parameters of derived passwords. Derived secrets don't contain
+// a password, it's computed from these on demand.
+const (
+	derivedKey        = "derived"
+	derivedKeyMaster  = "derived-master"
+	derivedKeySite    = "derived-site"
+	derivedKeyLogin   = "derived-login"
+	derivedKeyCounter = "derived-counter"
+	derivedKeyLength  = "derived-length"
+	derivedKeySymbols = "derived-symbols"
+)
+
+// generateDerived stores the parameters of a derived password and returns
+// the password
+func (s *Action) generateDerived(ctx context.Context, c *cli.Context, name, key, length string, kvps map[string]string) (string, error) {
+	if key != "" {
+		return "", ExitError(ExitUsage, nil, "derived passwords can not be generated for keys")
+	}
+	master := c.String("master")
+	if master == "" {
+		return "", ExitError(ExitUsage, nil, "derived passwords require --master")
+	}
+	if master == name {
+		return "", ExitError(ExitUsage, nil, "the master secret can not be derived from itself")
+	}
+
+	site := c.String("site")
+	if site == "" {
+		site = path.Base(name)
+	}
+	counter := 1
+	if c.IsSet("counter") {
+		counter = c.Int("counter")
+	}
+
+	var pwlen int
+	if length == "" {
+		iv, err := termio.AskForInt(ctx, "How long should the password be?", s.defaultLengthFor(ctx, name))
+		if err != nil {
+			return "", ExitError(ExitUsage, err, "password length must be a number")
+		}
+		pwlen = iv
+	} else {
+		iv, err := strconv.Atoi(length)
+		if err != nil {
+			return "", ExitError(ExitUsage, err, "password length must be a number")
+		}
+		pwlen = iv
+	}
+
+	d := pwgen.NewDerived(site, c.String("login"), counter, pwlen, c.Bool("symbols"))
+	if err := s.checkEntropy(ctx, d.Entropy()); err != nil {
+		return "", err
+	}
+	password, err := s.derivePassword(ctx, master, d)
+	if err != nil {
+		return "", err
+	}
+
+	sec := secrets.New()
+	setMetadata(sec, kvps)
+	sec.Set(derivedKey, pwgen.DerivedVersion)
+	sec.Set(derivedKeyMaster, master)
+	sec.Set(derivedKeySite, d.Site)
+	sec.Set(derivedKeyLogin, d.Login)
+	sec.Set(derivedKeyCounter, strconv.Itoa(d.Counter))
+	sec.Set(derivedKeyLength, strconv.Itoa(d.Length))
+	sec.Set(derivedKeySymbols, strconv.FormatBool(d.Symbols))
+
+	// the password isn't stored, check the policy against a copy that has it
+	psec, err := secparse.Parse(sec.Bytes())
+	if err != nil {
+		return "", ExitError(ExitUnknown, err, "failed to parse secret: %s", err)
+	}
+	psec.SetPassword(password)
+	if err := s.checkPolicy(ctx, name, psec); err != nil {
+		return "", err
+	}
+
+	if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, "Generated derived password"), name, sec); err != nil {
+		return "", ExitError(ExitEncrypt, err, "failed to create %q: %s", name, err)
+	}
+	return password, nil
+}
+
+// derivePassword computes a derived password from the master secret
+func (s *Action) derivePassword(ctx context.Context, master string, d *pwgen.Derived) (string, error) {
+	msec, err := s.Store.Get(ctxutil.WithAccessAction(ctx, "derive"), master)
+	if err != nil {
+		return "", ExitError(ExitDecrypt, err, "failed to read master secret %q: %s", master, err)
+	}
+	if _, found := msec.Get(derivedKey); found {
+		return "", ExitError(ExitUsage, nil, "master secret %q must not be a derived secret", master)
+	}
+	pw, err := d.Password([]byte(msec.Password()))
+	if err != nil {
+		return "", ExitError(ExitUsage, err, "failed to derive password: %s", err)
+	}
+	return pw, nil
+}
+
+// resolveDerived sets the password of derived secrets. Other secrets are
+// returned unchanged.
+func (s *Action) resolveDerived(ctx context.Context, name string, sec gopass.Secret) (gopass.Secret, error) {
+	version, found := sec.Get(derivedKey)
+	if !found {
+		return sec, nil
+	}
+	if version != pwgen.DerivedVersion {
+		return sec, ExitError(ExitUnsupported, nil, "derived password %q uses unsupported version %q", name, version)
+	}
+
+	d, master, err := derivedFromSecret(sec)
+	if err != nil {
+		return sec, ExitError(ExitUsage, err, "invalid parameters of derived password %q: %s", name, err)
+	}
+	pw, err := s.derivePassword(ctx, master, d)
+	if err != nil {
+		return sec, err
+	}
+	if sec.Password() != "" {
+		out.Warningf(ctx, "Ignoring the stored password of derived secret %q", name)
+	}
+	sec.SetPassword(pw)
+	return sec, nil
+}
+
+func derivedFromSecret(sec gopass.Secret) (*pwgen.Derived, string, error) {
+	master, _ := sec.Get(derivedKeyMaster)
+	if master == "" {
+		return nil, "", fmt.Errorf("missing %s", derivedKeyMaster)
+	}
+	site, _ := sec.Get(derivedKeySite)
+	login, _ := sec.Get(derivedKeyLogin)
+
+	ints := make(map[string]int, 2)
+	for _, k := range []string{derivedKeyCounter, derivedKeyLength} {
+		v, _ := sec.Get(k)
+		iv, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid %s %q", k, v)
+		}
+		ints[k] = iv
+	}
+	var symbols bool
+	if v, found := sec.Get(derivedKeySymbols); found {
+		bv, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid %s %q", derivedKeySymbols, v)
+		}
+		symbols = bv
+	}
+
+	return pwgen.NewDerived(site, login, ints[derivedKeyCounter], ints[derivedKeyLength], symbols), master, nil
+}
@@ -0,0 +1,193 @@
+package action
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/tree"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/urfave/cli/v2"
+)
+
+// dockerCredentialsNotFound is the message docker expects on stdout if no
+// credentials are stored for a registry
+const dockerCredentialsNotFound = "credentials not found in native keychain"
+
+// dockerCredential is the JSON message of the docker credential helper
+// protocol
+type dockerCredential struct {
+	ServerURL string
+	Username  string
+	Secret    string
+}
+
+// dockerCredentialName maps a registry URL to a secret name, e.g.
+// https://index.docker.io/v1/ to docker/index.docker.io/v1. URLs that would
+// point outside of the prefix, e.g. https://x/../../prod/db, are rejected.
+func dockerCredentialName(prefix, serverURL string) (string, error) {
+	name := strings.TrimSpace(serverURL)
+	if u, err := url.Parse(name); err == nil && u.Host != "" {
+		name = u.Host + u.Path
+	}
+	name = strings.ReplaceAll(strings.Trim(name, "/"), ":", "_")
+	for _, p := range strings.Split(name, "/") {
+		if p == "." || p == ".." {
+			return "", fmt.Errorf("invalid server URL %q", serverURL)
+		}
+	}
+	name = path.Join(prefix, name)
+	if !underPrefix(prefix, name) {
+		return "", fmt.Errorf("invalid server URL %q", serverURL)
+	}
+	return name, nil
+}
+
+// DockerCredentialStore implements the store operation of the docker
+// credential helper protocol
+func (s *Action) DockerCredentialStore(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	var dc dockerCredential
+	if err := json.NewDecoder(stdin).Decode(&dc); err != nil {
+		return ExitError(ExitUsage, err, "failed to decode credentials: %s", err)
+	}
+	if strings.TrimSpace(dc.ServerURL) == "" {
+		return ExitError(ExitUsage, nil, "no server URL given")
+	}
+
+	name, err := dockerCredentialName(c.String("prefix"), dc.ServerURL)
+	if err != nil {
+		return ExitError(ExitUsage, err, "%s", err)
+	}
+	sec := secrets.NewKV()
+	sec.SetPassword(dc.Secret)
+	if err := sec.Set("username", dc.Username); err != nil {
+		return ExitError(ExitUnknown, err, "failed to set username: %s", err)
+	}
+	if err := sec.Set("url", dc.ServerURL); err != nil {
+		return ExitError(ExitUnknown, err, "failed to set url: %s", err)
+	}
+
+	if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, "Stored docker credential"), name, sec); err != nil {
+		return ExitError(ExitEncrypt, err, "failed to store %s: %s", name, err)
+	}
+	return nil
+}
+
+// DockerCredentialGet implements the get operation of the docker credential
+// helper protocol
+func (s *Action) DockerCredentialGet(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	serverURL, err := readDockerServerURL(stdin)
+	if err != nil {
+		return err
+	}
+
+	name, err := dockerCredentialName(c.String("prefix"), serverURL)
+	if err != nil {
+		return ExitError(ExitUsage, err, "%s", err)
+	}
+	if !s.Store.Exists(ctx, name) {
+		fmt.Fprintln(stdout, dockerCredentialsNotFound)
+		return ExitError(ExitNotFound, nil, "no credentials for %s", serverURL)
+	}
+	sec, err := s.Store.Get(ctx, name)
+	if err != nil {
+		return ExitError(ExitDecrypt, err, "failed to read %s: %s", name, err)
+	}
+
+	dc := dockerCredential{
+		ServerURL: serverURL,
+		Secret:    sec.Password(),
+	}
+	dc.Username, _ = sec.Get("username")
+	return writeDockerJSON(dc)
+}
+
+// DockerCredentialErase implements the erase operation of the docker
+// credential helper protocol
+func (s *Action) DockerCredentialErase(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	serverURL, err := readDockerServerURL(stdin)
+	if err != nil {
+		return err
+	}
+
+	name, err := dockerCredentialName(c.String("prefix"), serverURL)
+	if err != nil {
+		return ExitError(ExitUsage, err, "%s", err)
+	}
+	if !s.Store.Exists(ctx, name) {
+		fmt.Fprintln(stdout, dockerCredentialsNotFound)
+		return ExitError(ExitNotFound, nil, "no credentials for %s", serverURL)
+	}
+	if err := s.Store.Delete(ctxutil.WithCommitMessage(ctx, "Erased docker credential"), name); err != nil {
+		return ExitError(ExitIO, err, "failed to delete %s: %s", name, err)
+	}
+	return nil
+}
+
+// DockerCredentialList implements the list operation of the docker credential
+// helper protocol. It prints a map of server URLs to usernames.
+func (s *Action) DockerCredentialList(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	creds, err := s.dockerCredentials(ctx, c.String("prefix"))
+	if err != nil {
+		return err
+	}
+	return writeDockerJSON(creds)
+}
+
+func (s *Action) dockerCredentials(ctx context.Context, prefix string) (map[string]string, error) {
+	list, err := s.Store.List(ctx, tree.INF)
+	if err != nil {
+		return nil, ExitError(ExitList, err, "failed to list store: %s", err)
+	}
+
+	creds := make(map[string]string, len(list))
+	for _, name := range list {
+		if !strings.HasPrefix(name, prefix+"/") {
+			continue
+		}
+		sec, err := s.Store.Get(ctx, name)
+		if err != nil {
+			return nil, ExitError(ExitDecrypt, err, "failed to read %s: %s", name, err)
+		}
+		serverURL, found := sec.Get("url")
+		if !found {
+			debug.Log("skipping %s without url", name)
+			continue
+		}
+		creds[serverURL], _ = sec.Get("username")
+	}
+	return creds, nil
+}
+
+func readDockerServerURL(r io.Reader) (string, error) {
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return "", ExitError(ExitIO, err, "failed to read server URL: %s", err)
+	}
+	serverURL := strings.TrimSpace(string(buf))
+	if serverURL == "" {
+		return "", ExitError(ExitUsage, nil, "no server URL given")
+	}
+	return serverURL, nil
+}
+
+func writeDockerJSON(v interface{}) error {
+	if err := json.NewEncoder(stdout).Encode(v); err != nil {
+		return ExitError(ExitIO, err, "failed to encode response: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDockerCredential(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		stdout = os.Stdout
+		stdin = os.Stdin
+	}()
+
+	flags := map[string]string{"prefix": "docker"}
+	run := func(fn func(*testing.T) error, in string) (string, error) {
+		buf.Reset()
+		stdin = strings.NewReader(in)
+		err := fn(t)
+		return buf.String(), err
+	}
+	store := func(t *testing.T) error {
+		return act.DockerCredentialStore(gptest.CliCtxWithFlags(ctx, t, flags))
+	}
+	get := func(t *testing.T) error {
+		return act.DockerCredentialGet(gptest.CliCtxWithFlags(ctx, t, flags))
+	}
+	erase := func(t *testing.T) error {
+		return act.DockerCredentialErase(gptest.CliCtxWithFlags(ctx, t, flags))
+	}
+	list := func(t *testing.T) error {
+		return act.DockerCredentialList(gptest.CliCtxWithFlags(ctx, t, flags))
+	}
+
+	res, err := run(get, "https://index.docker.io/v1/\n")
+	assert.Error(t, err)
+	assert.Equal(t, dockerCredentialsNotFound+"\n", res)
+
+	_, err = run(store, `{"ServerURL":"https://index.docker.io/v1/","Username":"alice","Secret":"s3cret"}`)
+	require.NoError(t, err)
+	_, err = run(store, `{"ServerURL":"registry.example.com:5000","Username":"bob","Secret":"token"}`)
+	require.NoError(t, err)
+	assert.True(t, act.Store.Exists(ctx, "docker/index.docker.io/v1"))
+	assert.True(t, act.Store.Exists(ctx, "docker/registry.example.com_5000"))
+
+	res, err = run(get, "https://index.docker.io/v1/")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"ServerURL":"https://index.docker.io/v1/","Username":"alice","Secret":"s3cret"}`, res)
+
+	res, err = run(list, "")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"https://index.docker.io/v1/":"alice","registry.example.com:5000":"bob"}`, res)
+
+	_, err = run(erase, "registry.example.com:5000\n")
+	require.NoError(t, err)
+	assert.False(t, act.Store.Exists(ctx, "docker/registry.example.com_5000"))
+
+	_, err = run(erase, "registry.example.com:5000\n")
+	assert.Error(t, err)
+
+	_, err = run(store, `not json`)
+	assert.Error(t, err)
+
+	t.Run("path traversal", func(t *testing.T) {
+		_, err := run(store, `{"ServerURL":"https://x/../../foo","Username":"eve","Secret":"pwned"}`)
+		assert.Error(t, err)
+		sec, err := act.Store.Get(ctx, "foo")
+		require.NoError(t, err)
+		assert.Equal(t, "secret", sec.Password())
+
+		_, err = run(get, "../../foo\n")
+		assert.Error(t, err)
+		_, err = run(erase, "../../foo\n")
+		assert.Error(t, err)
+		assert.True(t, act.Store.Exists(ctx, "foo"))
+	})
+}
+
+func TestDockerCredentialName(t *testing.T) {
+	for in, want := range map[string]string{
+		"https://index.docker.io/v1/": "docker/index.docker.io/v1",
+		"registry.example.com":        "docker/registry.example.com",
+		"localhost:5000":              "docker/localhost_5000",
+		"https://ghcr.io":             "docker/ghcr.io",
+	} {
+		name, err := dockerCredentialName("docker", in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, name, in)
+	}
+
+	for _, in := range []string{
+		"https://x/../../prod/db",
+		"../../prod/db",
+		"registry.example.com/./v1",
+		"..",
+		"/",
+	} {
+		_, err := dockerCredentialName("docker", in)
+		assert.Error(t, err, in)
+	}
+}
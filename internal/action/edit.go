@@ -8,6 +8,7 @@ import (
 	"github.com/gopasspw/gopass/internal/audit"
 	"github.com/gopasspw/gopass/internal/editor"
 	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store/leaf"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/gopass/secrets"
 	"github.com/gopasspw/gopass/pkg/pwgen"
@@ -19,6 +20,7 @@ import (
 // Edit the content of a password file
 func (s *Action) Edit(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
+	ctx = leaf.WithPolicyOverride(ctx, c.Bool("force"))
 	name := c.Args().First()
 	if name == "" {
 		return ExitError(ExitUsage, nil, "Usage: %s edit secret", s.Name)
@@ -60,6 +62,10 @@ func (s *Action) editUpdate(ctx context.Context, name string, content, nContent
 		audit.Single(ctx, pw)
 	}
 
+	if err := s.checkPolicy(ctx, name, nSec); err != nil {
+		return err
+	}
+
 	// write result (back) to store
 	if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, fmt.Sprintf("Edited with %s", ed)), name, nSec); err != nil {
 		return ExitError(ExitEncrypt, err, "failed to encrypt secret %s: %s", name, err)
@@ -101,7 +107,7 @@ func (s *Action) editGetContent(ctx context.Context, name string, create bool) (
 	}
 
 	// load template if it exists
-	if content, found := s.renderTemplate(ctx, name, []byte(pwgen.GeneratePassword(defaultLength, false))); found {
+	if content, found := s.renderTemplate(ctx, name, []byte(pwgen.GeneratePassword(s.defaultLengthFor(ctx, name), false))); found {
 		return name, content, true, nil
 	}
 
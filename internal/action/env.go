@@ -1,12 +1,18 @@
 package action
 
 import (
+	"bufio"
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path"
+	"regexp"
+	"sort"
 	"strings"
 
+	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/internal/tree"
 
 	"github.com/gopasspw/gopass/pkg/ctxutil"
@@ -14,58 +20,254 @@ import (
 	"github.com/urfave/cli/v2"
 )
 
+// envManifest is read from the current directory if no secret or mapping is
+// given on the command line. It's not named .env to avoid reading the dotenv
+// files of other tools.
+const envManifest = ".gopass-env"
+
+var reEnvName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// envMapping maps a field of a secret to an environment variable
+type envMapping struct {
+	Name   string
+	Secret string
+	Field  string
+}
+
 // Env implements the env subcommand. It populates the environment of a subprocess with
 // a set of environment variables corresponding to the secret subtree specified on the
-// command line.
+// command line and any explicit mappings.
 func (s *Action) Env(c *cli.Context) error {
-	ctx := ctxutil.WithGlobalFlags(c)
-	name := c.Args().First()
-	args := c.Args().Tail()
+	ctx := ctxutil.WithAccessAction(ctxutil.WithGlobalFlags(c), "env")
+	args := c.Args().Slice()
+	manifest := c.String("manifest")
+	export := c.Bool("export")
+	dotenv := c.Bool("dotenv")
 
-	if len(args) == 0 {
-		return ExitError(ExitUsage, nil, "Missing subcommand to execute")
+	if export && dotenv {
+		return ExitError(ExitUsage, nil, "--export and --dotenv are mutually exclusive")
 	}
 
-	if !s.Store.Exists(ctx, name) && !s.Store.IsDir(ctx, name) {
-		return ExitError(ExitNotFound, nil, "Secret %s not found", name)
+	mappings := make([]envMapping, 0, len(c.StringSlice("map")))
+	for _, m := range c.StringSlice("map") {
+		em, err := parseEnvMapping(m)
+		if err != nil {
+			return ExitError(ExitUsage, err, "%s", err)
+		}
+		mappings = append(mappings, em)
 	}
 
-	keys := make([]string, 0, 1)
-	if s.Store.IsDir(ctx, name) {
-		debug.Log("%q is a dir, adding it's entries", name)
-
-		l, err := s.Store.Tree(ctx)
-		if err != nil {
-			return ExitError(ExitList, err, "failed to list store: %s", err)
+	var name string
+	if len(args) > 0 && (s.Store.Exists(ctx, args[0]) || s.Store.IsDir(ctx, args[0])) {
+		name = args[0]
+		args = args[1:]
+	}
+	if name == "" && len(mappings) == 0 && manifest == "" {
+		if _, err := os.Stat(envManifest); err != nil {
+			if len(args) > 0 {
+				return ExitError(ExitNotFound, nil, "Secret %s not found", args[0])
+			}
+			return ExitError(ExitUsage, nil, "Usage: %s env [secret] [--map KEY=secret:field] [command and args...]", s.Name)
 		}
+		manifest = envManifest
+	}
 
-		subtree, err := l.FindFolder(name)
+	if manifest != "" {
+		mm, err := readEnvManifest(manifest)
 		if err != nil {
-			return ExitError(ExitNotFound, nil, "Entry %q not found", name)
+			return ExitError(ExitIO, err, "failed to read %s: %s", manifest, err)
 		}
+		mappings = append(mm, mappings...)
+	}
 
-		for _, e := range subtree.List(tree.INF) {
-			debug.Log("found key: %s", e)
-			keys = append(keys, e)
-		}
-	} else {
-		keys = append(keys, name)
+	if len(args) == 0 && !export && !dotenv {
+		return ExitError(ExitUsage, nil, "Missing subcommand to execute")
+	}
+	if len(args) > 0 && (export || dotenv) {
+		return ExitError(ExitUsage, nil, "Can not execute a subcommand with --export or --dotenv")
 	}
 
-	env := make([]string, 0, 1)
-	for _, key := range keys {
-		debug.Log("exporting to environment key: %s", key)
-		sec, err := s.Store.Get(ctx, key)
-		if err != nil {
-			return fmt.Errorf("failed to get entry for env prefix %q: %w", name, err)
-		}
-		env = append(env, fmt.Sprintf("%s=%s", strings.ToUpper(path.Base(key)), sec.Password()))
+	env, err := s.envVars(ctx, name, mappings)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case export:
+		return writeEnv(stdout, env, "export %s=%s\n", shellQuote)
+	case dotenv:
+		return writeEnv(stdout, env, "%s=%s\n", dotenvQuote)
+	}
+
+	vars := make([]string, 0, len(env))
+	for _, k := range envNames(env) {
+		vars = append(vars, fmt.Sprintf("%s=%s", k, env[k]))
 	}
 
 	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
-	cmd.Env = append(os.Environ(), env...)
+	cmd.Env = append(os.Environ(), vars...)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = stdout
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
+
+// envVars collects the passwords of all secrets below name (if any) and the
+// values of the mapped fields. Mappings take precedence.
+func (s *Action) envVars(ctx context.Context, name string, mappings []envMapping) (map[string]string, error) {
+	env := make(map[string]string, len(mappings)+1)
+
+	if name != "" {
+		keys, err := s.envKeys(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			// the names are printed unquoted by --export, so they must never
+			// contain anything but a valid name
+			vn := strings.ToUpper(path.Base(key))
+			if !reEnvName.MatchString(vn) {
+				out.Warningf(ctx, "Skipping %s, %q is not a valid variable name. Use --map to set it.", key, vn)
+				continue
+			}
+			debug.Log("exporting to environment key: %s", key)
+			sec, err := s.Store.Get(ctx, key)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get entry for env prefix %q: %w", name, err)
+			}
+			env[vn] = sec.Password()
+		}
+	}
+
+	for _, m := range mappings {
+		debug.Log("exporting %s:%s as %s", m.Secret, m.Field, m.Name)
+		sec, err := s.Store.Get(ctx, m.Secret)
+		if err != nil {
+			return nil, ExitError(ExitNotFound, err, "failed to get entry %q for %s: %s", m.Secret, m.Name, err)
+		}
+		if m.Field == "" || m.Field == "password" {
+			env[m.Name] = sec.Password()
+			continue
+		}
+		v, found := sec.Get(m.Field)
+		if !found {
+			return nil, ExitError(ExitNotFound, nil, "entry %q has no field %q for %s", m.Secret, m.Field, m.Name)
+		}
+		env[m.Name] = v
+	}
+
+	if len(env) == 0 {
+		out.Warningf(ctx, "No environment variables to set")
+	}
+	return env, nil
+}
+
+func (s *Action) envKeys(ctx context.Context, name string) ([]string, error) {
+	if !s.Store.IsDir(ctx, name) {
+		return []string{name}, nil
+	}
+
+	debug.Log("%q is a dir, adding it's entries", name)
+
+	l, err := s.Store.Tree(ctx)
+	if err != nil {
+		return nil, ExitError(ExitList, err, "failed to list store: %s", err)
+	}
+
+	subtree, err := l.FindFolder(name)
+	if err != nil {
+		return nil, ExitError(ExitNotFound, nil, "Entry %q not found", name)
+	}
+
+	keys := make([]string, 0, 1)
+	for _, e := range subtree.List(tree.INF) {
+		debug.Log("found key: %s", e)
+		keys = append(keys, e)
+	}
+	return keys, nil
+}
+
+// parseEnvMapping parses a mapping in the form KEY=secret:field. Without a
+// field the password is used.
+func parseEnvMapping(in string) (envMapping, error) {
+	p := strings.SplitN(in, "=", 2)
+	if len(p) < 2 || p[1] == "" {
+		return envMapping{}, fmt.Errorf("invalid mapping %q, expected KEY=secret:field", in)
+	}
+
+	m := envMapping{
+		Name:   strings.TrimSpace(p[0]),
+		Secret: strings.TrimSpace(p[1]),
+	}
+	if !reEnvName.MatchString(m.Name) {
+		return envMapping{}, fmt.Errorf("invalid environment variable name %q", m.Name)
+	}
+	if i := strings.LastIndex(m.Secret, ":"); i >= 0 {
+		m.Field = m.Secret[i+1:]
+		m.Secret = m.Secret[:i]
+	}
+	if m.Secret == "" {
+		return envMapping{}, fmt.Errorf("invalid mapping %q, missing secret", in)
+	}
+	return m, nil
+}
+
+// readEnvManifest reads a file with one mapping per line, e.g.
+//
+//	# database
+//	DB_USER=prod/db:username
+//	DB_PASSWORD=prod/db
+func readEnvManifest(fn string) ([]envMapping, error) {
+	fh, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = fh.Close()
+	}()
+
+	var mappings []envMapping
+	sc := bufio.NewScanner(fh)
+	for lineNo := 1; sc.Scan(); lineNo++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		m, err := parseEnvMapping(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		mappings = append(mappings, m)
+	}
+	return mappings, sc.Err()
+}
+
+func envNames(env map[string]string) []string {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func writeEnv(w io.Writer, env map[string]string, format string, quote func(string) string) error {
+	for _, k := range envNames(env) {
+		if _, err := fmt.Fprintf(w, format, k, quote(env[k])); err != nil {
+			return ExitError(ExitIO, err, "failed to write environment: %s", err)
+		}
+	}
+	return nil
+}
+
+// shellQuote quotes a value so it can be evaluated by a POSIX shell
+func shellQuote(v string) string {
+	return "'" + strings.ReplaceAll(v, "'", `'\''`) + "'"
+}
+
+// dotenvQuote quotes a value for dotenv files. Variable expansion is escaped.
+func dotenvQuote(v string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "$", `\$`)
+	return `"` + r.Replace(v) + `"`
+}
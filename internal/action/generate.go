@@ -13,6 +13,7 @@ import (
 	"github.com/gopasspw/gopass/pkg/gopass/secrets"
 
 	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store/leaf"
 	"github.com/gopasspw/gopass/pkg/clipboard"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
@@ -46,6 +47,8 @@ func (s *Action) Generate(c *cli.Context) error {
 	key, length := keyAndLength(args)
 
 	ctx = ctxutil.WithForce(ctx, force)
+	ctx = leaf.WithPolicyOverride(ctx, force)
+	s.loadStoreRules(ctx)
 
 	// ask for name of the secret if it wasn't provided already
 	if name == "" {
@@ -63,6 +66,15 @@ func (s *Action) Generate(c *cli.Context) error {
 		}
 	}
 
+	// derived passwords only store their parameters
+	if c.Bool("derived") {
+		password, err := s.generateDerived(ctx, c, name, key, length, kvps)
+		if err != nil {
+			return err
+		}
+		return s.generateCopyOrPrint(ctx, c, name, key, password)
+	}
+
 	// generate password
 	password, err := s.generatePassword(ctx, c, length, name)
 	if err != nil {
@@ -117,16 +129,19 @@ func (s *Action) generateCopyOrPrint(ctx context.Context, c *cli.Context, name,
 
 	out.OKf(ctx, "Password for entry %q generated", entry)
 
+	cfg := s.Store.Config(ctx, name)
+	ctx = s.Store.WithMountContext(ctx, name)
+
 	// copy to clipboard if:
 	// - explicitly requested with -c
 	// - autoclip=true, but only if output is not being redirected
-	if IsClip(ctx) || (s.cfg.AutoClip && !ctxutil.IsTerminal(ctx)) {
-		if err := clipboard.CopyTo(ctx, name, []byte(password), s.cfg.ClipTimeout); err != nil {
+	if IsClip(ctx) || (cfg.AutoClip && !ctxutil.IsTerminal(ctx)) {
+		if err := clipboard.CopyTo(ctx, name, []byte(password), cfg.ClipTimeout); err != nil {
 			return ExitError(ExitIO, err, "failed to copy to clipboard: %s", err)
 		}
 		// if autoclip is on and we're not printing the password to the terminal
 		// at least leave a notice that we did indeed copy it
-		if s.cfg.AutoClip && !c.Bool("print") {
+		if cfg.AutoClip && !c.Bool("print") {
 			out.Print(ctx, "Copied to clipboard")
 			return nil
 		}
@@ -162,8 +177,15 @@ func hasPwRuleForSecret(name string) (string, pwrules.Rule) {
 
 // generatePassword will run through the password generation steps
 func (s *Action) generatePassword(ctx context.Context, c *cli.Context, length, name string) (string, error) {
+	if c.String("generator") == "pattern" {
+		return s.generatePasswordPattern(ctx, c.String("pattern"))
+	}
+
 	if domain, rule := hasPwRuleForSecret(name); domain != "" {
 		out.Printf(ctx, "Using password rules for %s ...", domain)
+		if rule.Pattern != "" {
+			return s.generatePasswordPattern(ctx, rule.Pattern)
+		}
 		wl := 16
 		if iv, err := strconv.Atoi(length); err == nil {
 			if iv < rule.Minlen {
@@ -181,7 +203,11 @@ func (s *Action) generatePassword(ctx context.Context, c *cli.Context, length, n
 			return "", ExitError(ExitUsage, err, "password length must be a number")
 		}
 
-		pw := pwgen.NewCrypticForDomain(iv, domain).Password()
+		g := pwgen.NewCrypticForDomain(iv, domain)
+		if err := s.checkEntropy(ctx, g.Entropy()); err != nil {
+			return "", err
+		}
+		pw := g.Password()
 		if pw == "" {
 			return "", fmt.Errorf("failed to generate password for %s", domain)
 		}
@@ -196,7 +222,7 @@ func (s *Action) generatePassword(ctx context.Context, c *cli.Context, length, n
 
 	var pwlen int
 	if length == "" {
-		candidateLength := defaultLength
+		candidateLength := s.defaultLengthFor(ctx, name)
 		question := "How long should the password be?"
 		iv, err := termio.AskForInt(ctx, question, candidateLength)
 		if err != nil {
@@ -219,17 +245,63 @@ func (s *Action) generatePassword(ctx context.Context, c *cli.Context, length, n
 	case "xkcd":
 		return s.generatePasswordXKCD(ctx, c, length)
 	case "memorable":
+		if err := s.checkEntropy(ctx, pwgen.MemorableEntropy(pwlen, symbols)); err != nil {
+			return "", err
+		}
 		return pwgen.GenerateMemorablePassword(pwlen, symbols), nil
 	case "external":
+		if s.cfg.MinEntropy > 0 {
+			out.Warningf(ctx, "The entropy of external generators is unknown, minentropy is not enforced")
+		}
 		return pwgen.GenerateExternal(pwlen)
 	default:
 		if c.Bool("strict") {
+			if err := s.checkEntropy(ctx, pwgen.NewCrypticWithAllClasses(pwlen, symbols).Entropy()); err != nil {
+				return "", err
+			}
 			return pwgen.GeneratePasswordWithAllClasses(pwlen, symbols)
 		}
+		if err := s.checkEntropy(ctx, pwgen.PasswordEntropy(pwlen, symbols)); err != nil {
+			return "", err
+		}
 		return pwgen.GeneratePassword(pwlen, symbols), nil
 	}
 }
 
+// checkEntropy reports the entropy of the password that is about to be
+// generated and refuses to generate it if it's below the configured minimum
+func (s *Action) checkEntropy(ctx context.Context, bits float64) error {
+	out.Noticef(ctx, "Generating a password with %.1f bits of entropy", bits)
+	if s.cfg.MinEntropy > 0 && bits < float64(s.cfg.MinEntropy) {
+		return ExitError(ExitUsage, nil, "a password with %.1f bits of entropy is too weak, at least %d bits are required (minentropy)", bits, s.cfg.MinEntropy)
+	}
+	return nil
+}
+
+// defaultLengthFor returns the default password length for the given secret.
+// The policy of its store may override the built-in default.
+func (s *Action) defaultLengthFor(ctx context.Context, name string) int {
+	if p, err := s.Store.Policy(ctx, s.Store.MountPoint(name)); err == nil && p.Password.Length > 0 {
+		return p.Password.Length
+	}
+	return defaultLength
+}
+
+// generatePasswordPattern generates a password matching the given pattern
+func (s *Action) generatePasswordPattern(ctx context.Context, pattern string) (string, error) {
+	if pattern == "" {
+		return "", ExitError(ExitUsage, nil, "the pattern generator requires --pattern")
+	}
+	p, err := pwgen.ParsePattern(pattern)
+	if err != nil {
+		return "", ExitError(ExitUsage, err, "invalid pattern %q: %s", pattern, err)
+	}
+	if err := s.checkEntropy(ctx, p.Entropy()); err != nil {
+		return "", err
+	}
+	return p.Password(), nil
+}
+
 // generatePasswordXKCD walks through the steps necessary to create an XKCD-style
 // password
 func (s *Action) generatePasswordXKCD(ctx context.Context, c *cli.Context, length string) (string, error) {
@@ -259,6 +331,13 @@ func (s *Action) generatePasswordXKCD(ctx context.Context, c *cli.Context, lengt
 		return "", ExitError(ExitUsage, nil, "password length must not be zero")
 	}
 
+	bits, err := xkcdgen.Entropy(pwlen, c.String("lang"))
+	if err != nil {
+		return "", ExitError(ExitUsage, err, "%s", err)
+	}
+	if err := s.checkEntropy(ctx, bits); err != nil {
+		return "", err
+	}
 	return xkcdgen.RandomLengthDelim(pwlen, xkcdSeparator, c.String("lang"))
 }
 
@@ -272,6 +351,9 @@ func (s *Action) generateSetPassword(ctx context.Context, name, key, password st
 		}
 		setMetadata(sec, kvps)
 		sec.Set(key, password)
+		if err := s.checkPolicy(ctx, name, sec); err != nil {
+			return ctx, err
+		}
 		if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, "Generated password for key"), name, sec); err != nil {
 			return ctx, ExitError(ExitEncrypt, err, "failed to set key %q of %q: %s", key, name, err)
 		}
@@ -280,9 +362,9 @@ func (s *Action) generateSetPassword(ctx context.Context, name, key, password st
 
 	// replace password in existing secret
 	if s.Store.Exists(ctx, name) {
-		ctx, err := s.generateReplaceExisting(ctx, name, key, password, kvps)
+		sec, err := s.Store.Get(ctx, name)
 		if err == nil {
-			return ctx, nil
+			return s.generateReplaceExisting(ctx, name, password, sec, kvps)
 		}
 		out.Errorf(ctx, "Failed to read existing secret. Creating anew. Error: %s", err.Error())
 	}
@@ -304,6 +386,9 @@ func (s *Action) generateSetPassword(ctx context.Context, name, key, password st
 		}
 	}
 
+	if err := s.checkPolicy(ctx, name, sec); err != nil {
+		return ctx, err
+	}
 	if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, "Generated Password"), name, sec); err != nil {
 		return ctx, ExitError(ExitEncrypt, err, "failed to create %q: %s", name, err)
 	}
@@ -320,16 +405,14 @@ func hasChangeURL(name string) string {
 	return ""
 }
 
-func (s *Action) generateReplaceExisting(ctx context.Context, name, key, password string, kvps map[string]string) (context.Context, error) {
-	sec, err := s.Store.Get(ctx, name)
-	if err != nil {
-		return ctx, ExitError(ExitEncrypt, err, "failed to set key %q of %q: %s", key, name, err)
-	}
-
+func (s *Action) generateReplaceExisting(ctx context.Context, name, password string, sec gopass.Secret, kvps map[string]string) (context.Context, error) {
 	setMetadata(sec, kvps)
 	sec.SetPassword(password)
+	if err := s.checkPolicy(ctx, name, sec); err != nil {
+		return ctx, err
+	}
 	if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, "Generated password for YAML key"), name, sec); err != nil {
-		return ctx, ExitError(ExitEncrypt, err, "failed to set key %q of %q: %s", key, name, err)
+		return ctx, ExitError(ExitEncrypt, err, "failed to update %q: %s", name, err)
 	}
 
 	return ctx, nil
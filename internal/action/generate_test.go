@@ -5,6 +5,7 @@ import (
 	"context"
 	"flag"
 	"os"
+	"path/filepath"
 	"regexp"
 	"runtime"
 	"strings"
@@ -12,7 +13,9 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store/leaf"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/pwgen"
 	"github.com/gopasspw/gopass/tests/gptest"
 	"github.com/urfave/cli/v2"
 
@@ -150,6 +153,66 @@ func TestGenerate(t *testing.T) {
 		assert.NoError(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true", "xkcd": "true", "print": "true", "lang": "en"}, "foobar", "baz")))
 		buf.Reset()
 	})
+
+	// generate --force --generator pattern --pattern a{4}-d{4} --print foobar
+	t.Run("generate --force --generator pattern --print foobar", func(t *testing.T) {
+		assert.NoError(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true", "generator": "pattern", "pattern": "a{4}-d{4}", "print": "true"}, "foobar")))
+		assert.Contains(t, buf.String(), "bits of entropy")
+		assert.Regexp(t, `[a-zA-Z]{4}-[0-9]{4}\n`, buf.String())
+		buf.Reset()
+
+		assert.Error(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true", "generator": "pattern"}, "foobar")))
+		assert.Error(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true", "generator": "pattern", "pattern": "d{"}, "foobar")))
+		buf.Reset()
+	})
+
+	t.Run("generate with minentropy", func(t *testing.T) {
+		defer func() {
+			act.cfg.MinEntropy = 0
+		}()
+		act.cfg.MinEntropy = 100
+
+		assert.Error(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true"}, "foobar", "16")))
+		assert.Contains(t, buf.String(), "95.3 bits of entropy")
+		buf.Reset()
+
+		assert.Error(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true", "xkcd": "true", "lang": "en"}, "foobar", "4")))
+		buf.Reset()
+
+		assert.NoError(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true"}, "foobar", "17")))
+		assert.Contains(t, buf.String(), "101.2 bits of entropy")
+		buf.Reset()
+	})
+
+	t.Run("generate derived", func(t *testing.T) {
+		want, err := pwgen.NewDerived("example.com", "alice", 1, 16, false).Password([]byte("secret"))
+		require.NoError(t, err)
+
+		assert.Error(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true", "derived": "true"}, "derived/example.com", "16")))
+		assert.Error(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true", "derived": "true", "master": "foo"}, "derived/example.com", "key", "16")))
+		buf.Reset()
+
+		assert.NoError(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true", "derived": "true", "master": "foo", "login": "alice", "print": "true"}, "derived/example.com", "16")))
+		assert.Contains(t, buf.String(), want)
+		buf.Reset()
+
+		sec, err := act.Store.Get(ctx, "derived/example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "", sec.Password())
+		site, _ := sec.Get("derived-site")
+		assert.Equal(t, "example.com", site)
+
+		assert.NoError(t, act.Show(gptest.CliCtxWithFlags(ctx, t, map[string]string{"password": "true"}, "derived/example.com")))
+		assert.Equal(t, want, strings.TrimSpace(buf.String()))
+		buf.Reset()
+
+		// a new counter changes the password
+		assert.NoError(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true", "derived": "true", "master": "foo", "login": "alice", "counter": "2"}, "derived/example.com", "16")))
+		buf.Reset()
+		assert.NoError(t, act.Show(gptest.CliCtxWithFlags(ctx, t, map[string]string{"password": "true"}, "derived/example.com")))
+		assert.NotEqual(t, want, strings.TrimSpace(buf.String()))
+		buf.Reset()
+	})
 }
 
 func passIsAlphaNum(t *testing.T, buf string, want bool) {
@@ -164,6 +227,51 @@ func passIsAlphaNum(t *testing.T, buf string, want bool) {
 	}
 }
 
+func TestGenerateDerivedPolicy(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+
+	require.NoError(t, os.WriteFile(filepath.Join(u.StoreDir(""), leaf.PolicyFile), []byte(`password:
+  minlength: 8
+  folders:
+    dev:
+      allowforce: true
+`), 0600))
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	stdout = buf
+	color.NoColor = true
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		stdout = os.Stdout
+	}()
+
+	// the derived password is checked although it's not stored
+	flags := map[string]string{"derived": "true", "master": "foo"}
+	assert.Error(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, flags, "prod/example.com", "6")))
+	assert.False(t, act.Store.Exists(ctx, "prod/example.com"))
+	assert.NoError(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, flags, "prod/example.com", "16")))
+	assert.True(t, act.Store.Exists(ctx, "prod/example.com"))
+
+	flags["force"] = "true"
+	assert.Error(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, flags, "prod/example.org", "6")))
+	buf.Reset()
+	assert.NoError(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, flags, "dev/example.com", "6")))
+	assert.Contains(t, buf.String(), "Overriding store policy violation")
+	assert.True(t, act.Store.Exists(ctx, "dev/example.com"))
+}
+
 func TestKeyAndLength(t *testing.T) {
 	app := cli.NewApp()
 
@@ -0,0 +1,289 @@
+package action
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/tree"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/gopass"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/urfave/cli/v2"
+)
+
+// gitCredential holds the attributes of the git credential helper protocol,
+// see gitcredentials(7)
+type gitCredential struct {
+	Protocol string
+	Host     string
+	Path     string
+	Username string
+	Password string
+}
+
+// parseGitCredential reads key=value lines until an empty line or EOF
+func parseGitCredential(r io.Reader) (*gitCredential, error) {
+	gc := &gitCredential{}
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSuffix(sc.Text(), "\r")
+		if line == "" {
+			break
+		}
+		p := strings.SplitN(line, "=", 2)
+		if len(p) < 2 {
+			return nil, fmt.Errorf("invalid line %q", line)
+		}
+		switch p[0] {
+		case "protocol":
+			gc.Protocol = p[1]
+		case "host":
+			gc.Host = p[1]
+		case "path":
+			gc.Path = p[1]
+		case "username":
+			gc.Username = p[1]
+		case "password":
+			gc.Password = p[1]
+		case "url":
+			if err := gc.setURL(p[1]); err != nil {
+				return nil, err
+			}
+		default:
+			debug.Log("ignoring unknown credential attribute %q", p[0])
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if gc.Protocol == "" || gc.Host == "" {
+		return nil, fmt.Errorf("protocol and host are required")
+	}
+	if err := gc.validate(); err != nil {
+		return nil, err
+	}
+	return gc, nil
+}
+
+// validate rejects attributes that would point outside of the folder of the
+// credential, e.g. path=../../prod/db. They are sent by the remote.
+func (gc *gitCredential) validate() error {
+	for _, v := range []string{gc.Protocol, gc.Host, gc.Username} {
+		if v == "." || v == ".." {
+			return fmt.Errorf("invalid credential attribute %q", v)
+		}
+	}
+	if gc.Path == "" {
+		return nil
+	}
+	for _, p := range strings.Split(strings.Trim(gc.Path, "/"), "/") {
+		if p == "" || p == "." || p == ".." {
+			return fmt.Errorf("invalid path %q", gc.Path)
+		}
+	}
+	return nil
+}
+
+func (gc *gitCredential) setURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid url %q: %w", raw, err)
+	}
+	gc.Protocol = u.Scheme
+	gc.Host = u.Host
+	gc.Path = strings.TrimPrefix(u.Path, "/")
+	if u.User != nil {
+		gc.Username = u.User.Username()
+		if pw, ok := u.User.Password(); ok {
+			gc.Password = pw
+		}
+	}
+	return nil
+}
+
+// dir returns the folder holding the credentials for this host (and path)
+func (gc *gitCredential) dir(prefix string, withPath bool) string {
+	parts := []string{prefix, gitCredentialEscape(gc.Protocol), gitCredentialEscape(gc.Host)}
+	if withPath && gc.Path != "" {
+		parts = append(parts, strings.Trim(gc.Path, "/"))
+	}
+	return path.Join(parts...)
+}
+
+// underPrefix returns true if the name built from the (untrusted) input of a
+// credential helper is inside the given prefix
+func underPrefix(prefix, name string) bool {
+	if p := path.Clean(prefix); p != "." && p != "/" {
+		return strings.HasPrefix(name, p+"/")
+	}
+	return name != ".." && !strings.HasPrefix(name, "../") && !strings.HasPrefix(name, "/")
+}
+
+// gitCredentialEscape replaces characters that can not be used in secret
+// names, e.g. the colon of host:port on Windows
+func gitCredentialEscape(s string) string {
+	return strings.NewReplacer(":", "_", "/", "_", "\\", "_").Replace(s)
+}
+
+// GitCredentialGet implements the get operation of the git credential helper
+// protocol. Nothing is printed if no matching credential exists, so git can
+// try other helpers.
+func (s *Action) GitCredentialGet(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	gc, err := parseGitCredential(stdin)
+	if err != nil {
+		return ExitError(ExitUsage, err, "failed to parse credential: %s", err)
+	}
+
+	name, sec, err := s.gitCredentialLookup(ctx, c.String("prefix"), gc)
+	if err != nil {
+		return err
+	}
+	if sec == nil {
+		debug.Log("no credential found for %s://%s", gc.Protocol, gc.Host)
+		return nil
+	}
+
+	username, found := sec.Get("username")
+	if !found {
+		username = path.Base(name)
+	}
+	fmt.Fprintf(stdout, "username=%s\npassword=%s\n", username, sec.Password())
+	return nil
+}
+
+// GitCredentialStore implements the store operation of the git credential
+// helper protocol. New secrets are created from the matching template.
+func (s *Action) GitCredentialStore(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	gc, err := parseGitCredential(stdin)
+	if err != nil {
+		return ExitError(ExitUsage, err, "failed to parse credential: %s", err)
+	}
+	if gc.Username == "" || gc.Password == "" {
+		return ExitError(ExitUsage, nil, "username and password are required")
+	}
+
+	name := path.Join(gc.dir(c.String("prefix"), true), gitCredentialEscape(gc.Username))
+	if !underPrefix(c.String("prefix"), name) {
+		return ExitError(ExitUsage, nil, "invalid credential for %s://%s", gc.Protocol, gc.Host)
+	}
+	var sec gopass.Secret
+	if s.Store.Exists(ctx, name) {
+		sec, err = s.Store.Get(ctx, name)
+		if err != nil {
+			return ExitError(ExitDecrypt, err, "failed to read %s: %s", name, err)
+		}
+		if sec.Password() == gc.Password {
+			debug.Log("credential %s is unchanged", name)
+			return nil
+		}
+		sec.SetPassword(gc.Password)
+	} else {
+		sec = secrets.NewKV()
+		if content, found := s.renderTemplate(ctx, name, []byte(gc.Password)); found {
+			kv, err := secrets.ParseKV(content)
+			if err != nil {
+				return ExitError(ExitUnknown, err, "failed to parse template output: %s", err)
+			}
+			sec = kv
+		}
+		sec.SetPassword(gc.Password)
+	}
+	if err := sec.Set("username", gc.Username); err != nil {
+		return ExitError(ExitUnknown, err, "failed to set username: %s", err)
+	}
+
+	if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, "Stored git credential"), name, sec); err != nil {
+		return ExitError(ExitEncrypt, err, "failed to store %s: %s", name, err)
+	}
+	return nil
+}
+
+// GitCredentialErase implements the erase operation of the git credential
+// helper protocol
+func (s *Action) GitCredentialErase(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	gc, err := parseGitCredential(stdin)
+	if err != nil {
+		return ExitError(ExitUsage, err, "failed to parse credential: %s", err)
+	}
+
+	name, sec, err := s.gitCredentialLookup(ctx, c.String("prefix"), gc)
+	if err != nil {
+		return err
+	}
+	if sec == nil {
+		return nil
+	}
+	// only remove the credential if it's the one git failed to use
+	if gc.Password != "" && sec.Password() != gc.Password {
+		debug.Log("not erasing %s, password does not match", name)
+		return nil
+	}
+
+	if err := s.Store.Delete(ctxutil.WithCommitMessage(ctx, "Erased git credential"), name); err != nil {
+		return ExitError(ExitIO, err, "failed to delete %s: %s", name, err)
+	}
+	return nil
+}
+
+// gitCredentialLookup finds the secret for the given credential. Without a
+// username the first entry for the host is used. Credentials stored for a
+// specific path take precedence over those for the whole host.
+func (s *Action) gitCredentialLookup(ctx context.Context, prefix string, gc *gitCredential) (string, gopass.Secret, error) {
+	dirs := []string{gc.dir(prefix, true)}
+	if gc.Path != "" {
+		dirs = append(dirs, gc.dir(prefix, false))
+	}
+
+	var list []string
+	for _, dir := range dirs {
+		if !underPrefix(prefix, dir) {
+			return "", nil, ExitError(ExitUsage, nil, "invalid credential for %s://%s", gc.Protocol, gc.Host)
+		}
+		name := ""
+		if gc.Username != "" {
+			name = path.Join(dir, gitCredentialEscape(gc.Username))
+			if !s.Store.Exists(ctx, name) {
+				continue
+			}
+		} else {
+			if list == nil {
+				var err error
+				list, err = s.Store.List(ctx, tree.INF)
+				if err != nil {
+					return "", nil, ExitError(ExitList, err, "failed to list store: %s", err)
+				}
+				sort.Strings(list)
+			}
+			for _, e := range list {
+				if strings.HasPrefix(e, dir+"/") && !strings.Contains(strings.TrimPrefix(e, dir+"/"), "/") {
+					name = e
+					break
+				}
+			}
+			if name == "" {
+				continue
+			}
+		}
+
+		debug.Log("using %s for %s://%s", name, gc.Protocol, gc.Host)
+		sec, err := s.Store.Get(ctx, name)
+		if err != nil {
+			return "", nil, ExitError(ExitDecrypt, err, "failed to read %s: %s", name, err)
+		}
+		return name, sec, nil
+	}
+	return "", nil, nil
+}
@@ -0,0 +1,159 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitCredential(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		stdout = os.Stdout
+		stdin = os.Stdin
+	}()
+
+	flags := map[string]string{"prefix": "git"}
+	run := func(fn func(ctx context.Context) error, in string) string {
+		t.Helper()
+		buf.Reset()
+		stdin = strings.NewReader(in)
+		require.NoError(t, fn(ctx))
+		return buf.String()
+	}
+	get := func(ctx context.Context) error {
+		return act.GitCredentialGet(gptest.CliCtxWithFlags(ctx, t, flags))
+	}
+	store := func(ctx context.Context) error {
+		return act.GitCredentialStore(gptest.CliCtxWithFlags(ctx, t, flags))
+	}
+	erase := func(ctx context.Context) error {
+		return act.GitCredentialErase(gptest.CliCtxWithFlags(ctx, t, flags))
+	}
+
+	t.Run("get unknown host", func(t *testing.T) {
+		assert.Equal(t, "", run(get, "protocol=https\nhost=example.com\n\n"))
+	})
+
+	t.Run("store", func(t *testing.T) {
+		run(store, "protocol=https\nhost=example.com:8443\nusername=alice\npassword=s3cret\n\n")
+
+		sec, err := act.Store.Get(ctx, "git/https/example.com_8443/alice")
+		require.NoError(t, err)
+		assert.Equal(t, "s3cret", sec.Password())
+		v, found := sec.Get("username")
+		assert.True(t, found)
+		assert.Equal(t, "alice", v)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		want := "username=alice\npassword=s3cret\n"
+		assert.Equal(t, want, run(get, "protocol=https\nhost=example.com:8443\n\n"))
+		assert.Equal(t, want, run(get, "protocol=https\nhost=example.com:8443\nusername=alice\n"))
+		// falls back to the credentials for the host
+		assert.Equal(t, want, run(get, "protocol=https\nhost=example.com:8443\npath=org/repo.git\n"))
+		assert.Equal(t, "", run(get, "protocol=https\nhost=example.com:8443\nusername=bob\n"))
+		assert.Equal(t, want, run(get, "url=https://example.com:8443/org/repo.git\n"))
+	})
+
+	t.Run("store with path", func(t *testing.T) {
+		run(store, "protocol=https\nhost=example.com\npath=org/repo.git\nusername=bob\npassword=token\n\n")
+		assert.True(t, act.Store.Exists(ctx, "git/https/example.com/org/repo.git/bob"))
+		assert.Equal(t, "username=bob\npassword=token\n", run(get, "protocol=https\nhost=example.com\npath=org/repo.git\n"))
+		assert.Equal(t, "", run(get, "protocol=https\nhost=example.com\n"))
+	})
+
+	t.Run("erase", func(t *testing.T) {
+		// wrong password, keep the credential
+		run(erase, "protocol=https\nhost=example.com:8443\nusername=alice\npassword=wrong\n")
+		assert.True(t, act.Store.Exists(ctx, "git/https/example.com_8443/alice"))
+
+		run(erase, "protocol=https\nhost=example.com:8443\nusername=alice\npassword=s3cret\n")
+		assert.False(t, act.Store.Exists(ctx, "git/https/example.com_8443/alice"))
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		stdin = strings.NewReader("host=example.com\n")
+		assert.Error(t, get(ctx))
+	})
+
+	t.Run("path traversal", func(t *testing.T) {
+		for _, in := range []string{
+			"protocol=https\nhost=example.com\npath=../../../foo\n",
+			"protocol=https\nhost=example.com\npath=org//repo.git\n",
+			"protocol=https\nhost=..\nusername=..\n",
+			"protocol=..\nhost=..\n",
+			"url=https://example.com/../../../foo\n",
+		} {
+			buf.Reset()
+			stdin = strings.NewReader(in)
+			assert.Error(t, get(ctx), in)
+			assert.NotContains(t, buf.String(), "password=", in)
+
+			stdin = strings.NewReader(in + "username=x\npassword=pwned\n")
+			assert.Error(t, store(ctx), in)
+			stdin = strings.NewReader(in)
+			assert.Error(t, erase(ctx), in)
+		}
+		sec, err := act.Store.Get(ctx, "foo")
+		require.NoError(t, err)
+		assert.NotEqual(t, "pwned", sec.Password())
+	})
+}
+
+func TestGitCredentialTemplate(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		stdout = os.Stdout
+		stdin = os.Stdin
+	}()
+
+	require.NoError(t, act.Store.SetTemplate(ctx, "git", []byte("{{ .Content }}\ntype: git\n")))
+
+	stdin = strings.NewReader("protocol=https\nhost=example.com\nusername=alice\npassword=s3cret\n")
+	require.NoError(t, act.GitCredentialStore(gptest.CliCtxWithFlags(ctx, t, map[string]string{"prefix": "git"})))
+
+	sec, err := act.Store.Get(ctx, "git/https/example.com/alice")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", sec.Password())
+	v, _ := sec.Get("type")
+	assert.Equal(t, "git", v)
+	v, _ = sec.Get("username")
+	assert.Equal(t, "alice", v)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/gopasspw/gopass/internal/audit"
 	"github.com/gopasspw/gopass/internal/editor"
 	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store/leaf"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
 	"github.com/gopasspw/gopass/pkg/gopass"
@@ -25,6 +26,7 @@ func (s *Action) Insert(c *cli.Context) error {
 	multiline := c.Bool("multiline")
 	force := c.Bool("force")
 	append := c.Bool("append")
+	ctx = leaf.WithPolicyOverride(ctx, force)
 
 	args, kvps := parseArgs(c)
 	name := args.Get(0)
@@ -116,6 +118,9 @@ func (s *Action) insertStdin(ctx context.Context, name string, content []byte, a
 		debug.Log("Created new plain secret with input")
 	}
 
+	if err := s.checkPolicy(ctx, name, sec); err != nil {
+		return err
+	}
 	if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, "Read secret from STDIN"), name, sec); err != nil {
 		return ExitError(ExitEncrypt, err, "failed to set %q: %s", name, err)
 	}
@@ -150,6 +155,9 @@ func (s *Action) insertSingle(ctx context.Context, name, pw string, kvps map[str
 		audit.Single(ctx, pw)
 	}
 
+	if err := s.checkPolicy(ctx, name, sec); err != nil {
+		return err
+	}
 	if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, "Inserted user supplied password"), name, sec); err != nil {
 		return ExitError(ExitEncrypt, err, "failed to write secret %q: %s", name, err)
 	}
@@ -179,6 +187,9 @@ func (s *Action) insertYAML(ctx context.Context, name, key string, content []byt
 	if err := sec.Set(key, string(content)); err != nil {
 		return ExitError(ExitUsage, err, "failed set key %q of %q: %q", key, name, err)
 	}
+	if err := s.checkPolicy(ctx, name, sec); err != nil {
+		return err
+	}
 	if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, "Inserted YAML value from STDIN"), name, sec); err != nil {
 		return ExitError(ExitEncrypt, err, "failed to set key %q of %q: %s", key, name, err)
 	}
@@ -205,8 +216,20 @@ func (s *Action) insertMultiline(ctx context.Context, c *cli.Context, name strin
 	if err != nil || n < 0 {
 		out.Errorf(ctx, "WARNING: Invalid secret: %s of len %d", err, n)
 	}
+	if err := s.checkPolicy(ctx, name, sec); err != nil {
+		return err
+	}
 	if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, fmt.Sprintf("Inserted user supplied password with %s", ed)), name, sec); err != nil {
 		return ExitError(ExitEncrypt, err, "failed to store secret %q: %s", name, err)
 	}
 	return nil
 }
+
+// checkPolicy rejects secrets that violate the policy of their store. Only
+// user supplied secrets are checked, not e.g. OTP counter updates.
+func (s *Action) checkPolicy(ctx context.Context, name string, sec gopass.Byter) error {
+	if err := s.Store.CheckPolicy(ctx, name, sec); err != nil {
+		return ExitError(ExitEncrypt, err, "failed to write secret %q: %s", name, err)
+	}
+	return nil
+}
@@ -4,10 +4,13 @@ import (
 	"bytes"
 	"context"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store/leaf"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
 	"github.com/gopasspw/gopass/tests/gptest"
 
 	"github.com/fatih/color"
@@ -185,3 +188,65 @@ func TestInsertStdin(t *testing.T) {
 	ibuf.Reset()
 	buf.Reset()
 }
+
+func TestInsertPolicy(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	ctx = ctxutil.WithStdin(ctx, true)
+
+	require.NoError(t, os.WriteFile(filepath.Join(u.StoreDir(""), leaf.PolicyFile), []byte(`password:
+  minlength: 8
+  forbidden: [hunter2]
+  folders:
+    dev:
+      allowforce: true
+`), 0600))
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	ibuf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	stdin = ibuf
+	color.NoColor = true
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		stdin = os.Stdin
+	}()
+
+	ibuf.WriteString("hunter2")
+	err = act.Insert(gptest.CliCtx(ctx, t, "prod/db"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "forbidden password")
+	assert.False(t, act.Store.Exists(ctx, "prod/db"))
+
+	// the policy does not allow to override it here
+	ibuf.WriteString("hunter2")
+	assert.Error(t, act.Insert(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true"}, "prod/db")))
+
+	ibuf.WriteString("hunter2")
+	assert.Error(t, act.Insert(gptest.CliCtx(ctx, t, "dev/db")))
+
+	buf.Reset()
+	ibuf.WriteString("hunter2")
+	assert.NoError(t, act.Insert(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true"}, "dev/db")))
+	assert.Contains(t, buf.String(), "Overriding store policy violation")
+	assert.True(t, act.Store.Exists(ctx, "dev/db"))
+
+	// unchanged passwords and other commands are not checked
+	sec := secrets.NewKV()
+	sec.SetPassword("hunter2")
+	require.NoError(t, sec.Set("user", "bob"))
+	require.NoError(t, act.Store.Set(ctx, "prod/old", sec))
+	assert.NoError(t, act.Delete(gptest.CliCtx(ctx, t, "prod/old", "user")))
+	ibuf.WriteString("hunter2")
+	assert.NoError(t, act.Insert(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true"}, "prod/old")))
+}
@@ -0,0 +1,288 @@
+package action
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/gopass"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+var reK8sInvalidKey = regexp.MustCompile(`[^-._a-zA-Z0-9]`)
+
+// k8sManifest is the subset of a Kubernetes Secret or ConfigMap manifest
+// gopass reads and writes
+type k8sManifest struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   k8sMetadata       `yaml:"metadata"`
+	Type       string            `yaml:"type,omitempty"`
+	Data       map[string]string `yaml:"data,omitempty"`
+	StringData map[string]string `yaml:"stringData,omitempty"`
+	BinaryData map[string]string `yaml:"binaryData,omitempty"`
+}
+
+type k8sMetadata struct {
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace,omitempty"`
+}
+
+// K8sExport renders a Kubernetes Secret (or ConfigMap) manifest from a secret
+// or all secrets below a folder
+func (s *Action) K8sExport(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	name := strings.TrimSuffix(c.Args().First(), "/")
+	if name == "" {
+		return ExitError(ExitUsage, nil, "Usage: %s k8s export <secret|folder> [--name NAME] [--namespace NS]", s.Name)
+	}
+	if !s.Store.Exists(ctx, name) && !s.Store.IsDir(ctx, name) {
+		return ExitError(ExitNotFound, nil, "Secret %s not found", name)
+	}
+
+	keys, err := s.envKeys(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	data := make(map[string]string, len(keys))
+	for _, key := range keys {
+		sec, err := s.Store.Get(ctx, key)
+		if err != nil {
+			return ExitError(ExitDecrypt, err, "failed to read %s: %s", key, err)
+		}
+		// a single secret uses its field names as keys, secrets below a
+		// folder are prefixed with their relative name
+		prefix := ""
+		if key != name {
+			prefix = strings.TrimPrefix(key, name+"/")
+		}
+		k8sAddSecret(data, prefix, sec)
+	}
+
+	m := k8sManifest{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Metadata: k8sMetadata{
+			Name:      c.String("name"),
+			Namespace: c.String("namespace"),
+		},
+	}
+	if m.Metadata.Name == "" {
+		m.Metadata.Name = k8sKey(path.Base(name))
+	}
+
+	if c.Bool("configmap") {
+		m.Kind = "ConfigMap"
+		for k, v := range data {
+			if utf8.ValidString(v) {
+				if m.Data == nil {
+					m.Data = map[string]string{}
+				}
+				m.Data[k] = v
+				continue
+			}
+			if m.BinaryData == nil {
+				m.BinaryData = map[string]string{}
+			}
+			m.BinaryData[k] = base64.StdEncoding.EncodeToString([]byte(v))
+		}
+	} else {
+		m.Type = c.String("type")
+		m.Data = make(map[string]string, len(data))
+		for k, v := range data {
+			m.Data[k] = base64.StdEncoding.EncodeToString([]byte(v))
+		}
+	}
+
+	enc := yaml.NewEncoder(stdout)
+	enc.SetIndent(2)
+	if err := enc.Encode(m); err != nil {
+		return ExitError(ExitIO, err, "failed to encode manifest: %s", err)
+	}
+	return enc.Close()
+}
+
+// k8sAddSecret adds the password and all fields of a secret to data
+func k8sAddSecret(data map[string]string, prefix string, sec gopass.Secret) {
+	key := func(k string) string {
+		if prefix == "" {
+			return k8sKey(k)
+		}
+		return k8sKey(prefix + "." + k)
+	}
+
+	if prefix == "" {
+		data[key("password")] = sec.Password()
+	} else {
+		data[k8sKey(prefix)] = sec.Password()
+	}
+	for _, k := range sec.Keys() {
+		if v, found := sec.Get(k); found {
+			data[key(k)] = v
+		}
+	}
+}
+
+// k8sKey turns a secret name into a valid key of a Secret or ConfigMap
+func k8sKey(name string) string {
+	return reK8sInvalidKey.ReplaceAllString(strings.ReplaceAll(name, "/", "."), "_")
+}
+
+// K8sImport creates one secret for every Secret or ConfigMap in a manifest.
+// Each key becomes a field, except for the password key.
+func (s *Action) K8sImport(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	fn := c.Args().Get(0)
+	prefix := strings.TrimSuffix(c.Args().Get(1), "/")
+	if fn == "" {
+		return ExitError(ExitUsage, nil, "Usage: %s k8s import <manifest|-> [folder]", s.Name)
+	}
+
+	var r io.Reader = stdin
+	if fn != "-" {
+		fh, err := os.Open(fn)
+		if err != nil {
+			return ExitError(ExitIO, err, "failed to open %s: %s", fn, err)
+		}
+		defer func() {
+			_ = fh.Close()
+		}()
+		r = fh
+	}
+
+	manifests, err := readK8sManifests(r)
+	if err != nil {
+		return ExitError(ExitUsage, err, "failed to read %s: %s", fn, err)
+	}
+	if len(manifests) < 1 {
+		return ExitError(ExitNotFound, nil, "no Secret or ConfigMap found in %s", fn)
+	}
+
+	for _, m := range manifests {
+		name := path.Join(prefix, m.Metadata.Name)
+		if s.Store.Exists(ctx, name) && !c.Bool("force") {
+			return ExitError(ExitAborted, nil, "Secret %s already exists. Use --force to overwrite it", name)
+		}
+
+		sec, err := m.secret()
+		if err != nil {
+			return ExitError(ExitUsage, err, "invalid %s %s: %s", m.Kind, m.Metadata.Name, err)
+		}
+
+		if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, fmt.Sprintf("Imported %s %s", m.Kind, m.Metadata.Name)), name, sec); err != nil {
+			return ExitError(ExitEncrypt, err, "failed to save %s: %s", name, err)
+		}
+		out.OKf(ctx, "Imported %s %s to %s", m.Kind, m.Metadata.Name, name)
+	}
+	return nil
+}
+
+// readK8sManifests reads all Secrets and ConfigMaps of a (multi-document)
+// manifest. Other kinds are ignored.
+func readK8sManifests(r io.Reader) ([]k8sManifest, error) {
+	var manifests []k8sManifest
+
+	dec := yaml.NewDecoder(r)
+	for {
+		var m k8sManifest
+		if err := dec.Decode(&m); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		if m.Kind != "Secret" && m.Kind != "ConfigMap" {
+			debug.Log("skipping %q", m.Kind)
+			continue
+		}
+		if m.Metadata.Name == "" {
+			return nil, fmt.Errorf("%s without name", m.Kind)
+		}
+		manifests = append(manifests, m)
+	}
+	return manifests, nil
+}
+
+// values returns the decoded data of the manifest
+func (m k8sManifest) values() (map[string]string, error) {
+	vals := make(map[string]string, len(m.Data)+len(m.StringData)+len(m.BinaryData))
+
+	decode := func(in map[string]string) error {
+		for k, v := range in {
+			buf, err := base64.StdEncoding.DecodeString(v)
+			if err != nil {
+				return fmt.Errorf("key %q is not base64 encoded: %w", k, err)
+			}
+			vals[k] = string(buf)
+		}
+		return nil
+	}
+
+	if m.Kind == "Secret" {
+		if err := decode(m.Data); err != nil {
+			return nil, err
+		}
+	} else {
+		for k, v := range m.Data {
+			vals[k] = v
+		}
+	}
+	if err := decode(m.BinaryData); err != nil {
+		return nil, err
+	}
+	// stringData takes precedence, like in the API server
+	for k, v := range m.StringData {
+		vals[k] = v
+	}
+	return vals, nil
+}
+
+// secret converts the manifest to a secret. Multi-line values (e.g. TLS
+// certificates and keys) can't be stored as KV fields, such manifests are
+// stored as YAML secrets instead.
+func (m k8sManifest) secret() (gopass.Secret, error) {
+	vals, err := m.values()
+	if err != nil {
+		return nil, err
+	}
+
+	var sec gopass.Secret = secrets.NewKV()
+	keys := make([]string, 0, len(vals))
+	for k, v := range vals {
+		keys = append(keys, k)
+		if isMultiline(v) {
+			sec = &secrets.YAML{}
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v := vals[k]
+		if k == "password" && !isMultiline(v) {
+			sec.SetPassword(v)
+			continue
+		}
+		// keys are lower case, like in KV secrets
+		if err := sec.Set(strings.ToLower(k), v); err != nil {
+			return nil, err
+		}
+	}
+	return sec, nil
+}
+
+func isMultiline(v string) bool {
+	return strings.ContainsAny(v, "\r\n")
+}
@@ -0,0 +1,154 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestK8s(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		stdout = os.Stdout
+	}()
+
+	sec := secrets.NewKV()
+	sec.SetPassword("s3cret")
+	require.NoError(t, sec.Set("username", "admin"))
+	require.NoError(t, act.Store.Set(ctx, "prod/db", sec))
+	require.NoError(t, act.Store.Set(ctx, "prod/api/token", secrets.ParsePlain([]byte("t0ken"))))
+
+	t.Run("export secret", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.K8sExport(gptest.CliCtxWithFlags(ctx, t, map[string]string{"namespace": "bar", "type": "Opaque"}, "prod/db")))
+		assert.Equal(t, `apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+  namespace: bar
+type: Opaque
+data:
+  password: czNjcmV0
+  username: YWRtaW4=
+`, buf.String())
+	})
+
+	t.Run("export folder as configmap", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.K8sExport(gptest.CliCtxWithFlags(ctx, t, map[string]string{"name": "foo", "configmap": "true"}, "prod")))
+		assert.Equal(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: foo
+data:
+  api.token: t0ken
+  db: s3cret
+  db.username: admin
+`, buf.String())
+	})
+
+	t.Run("export missing", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.K8sExport(gptest.CliCtx(ctx, t, "does/not/exist")))
+	})
+
+	t.Run("import", func(t *testing.T) {
+		defer buf.Reset()
+		fn := filepath.Join(u.Dir, "manifest.yaml")
+		require.NoError(t, os.WriteFile(fn, []byte(`apiVersion: v1
+kind: Secret
+metadata:
+  name: app
+type: Opaque
+data:
+  password: czNjcmV0
+  username: YWRtaW4=
+stringData:
+  host: db.example.com
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: ignored
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  level: debug
+`), 0644))
+
+		require.NoError(t, act.K8sImport(gptest.CliCtx(ctx, t, fn, "k8s")))
+
+		sec, err := act.Store.Get(ctx, "k8s/app")
+		require.NoError(t, err)
+		assert.Equal(t, "s3cret", sec.Password())
+		v, _ := sec.Get("username")
+		assert.Equal(t, "admin", v)
+		v, _ = sec.Get("host")
+		assert.Equal(t, "db.example.com", v)
+
+		sec, err = act.Store.Get(ctx, "k8s/settings")
+		require.NoError(t, err)
+		v, _ = sec.Get("level")
+		assert.Equal(t, "debug", v)
+		assert.False(t, act.Store.Exists(ctx, "k8s/ignored"))
+
+		// don't overwrite without --force
+		assert.Error(t, act.K8sImport(gptest.CliCtx(ctx, t, fn, "k8s")))
+		assert.NoError(t, act.K8sImport(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true"}, fn, "k8s")))
+	})
+
+	t.Run("import multi-line values", func(t *testing.T) {
+		defer buf.Reset()
+		crt := "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"
+		fn := filepath.Join(u.Dir, "tls.yaml")
+		require.NoError(t, os.WriteFile(fn, []byte(`apiVersion: v1
+kind: Secret
+metadata:
+  name: tls
+type: kubernetes.io/tls
+data:
+  tls.crt: `+base64.StdEncoding.EncodeToString([]byte(crt))+`
+  password: czNjcmV0
+`), 0644))
+
+		require.NoError(t, act.K8sImport(gptest.CliCtx(ctx, t, fn, "k8s")))
+
+		sec, err := act.Store.Get(ctx, "k8s/tls")
+		require.NoError(t, err)
+		assert.Equal(t, "s3cret", sec.Password())
+		v, _ := sec.Get("tls.crt")
+		assert.Equal(t, crt, v)
+
+		buf.Reset()
+		require.NoError(t, act.K8sExport(gptest.CliCtx(ctx, t, "k8s/tls")))
+		assert.Contains(t, buf.String(), base64.StdEncoding.EncodeToString([]byte(crt)))
+	})
+}
@@ -0,0 +1,34 @@
+package action
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/store/leaf"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/urfave/cli/v2"
+)
+
+// LogAccess displays and verifies the access log
+func (s *Action) LogAccess(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	name := strings.TrimSuffix(c.Args().First(), "/")
+
+	entries, err := s.Store.AccessLog(ctx)
+	if err != nil && !errors.Is(err, leaf.ErrAccessLogTampered) {
+		return ExitError(ExitUnknown, err, "Failed to read access log: %s", err)
+	}
+
+	for _, e := range entries {
+		if name != "" && e.Name != name && !strings.HasPrefix(e.Name, name+"/") {
+			continue
+		}
+		fmt.Fprintf(stdout, "%s %s@%s %s %s\n", e.Time.Local().Format("2006-01-02 15:04:05"), e.User, e.Host, e.Action, e.Name)
+	}
+
+	if err != nil {
+		return ExitError(ExitAudit, err, "Access log verification failed: %s", err)
+	}
+	return nil
+}
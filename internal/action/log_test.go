@@ -0,0 +1,79 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/appdir"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogAccess(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		stdout = os.Stdout
+	}()
+
+	t.Run("disabled", func(t *testing.T) {
+		assert.Error(t, act.LogAccess(gptest.CliCtx(ctx, t)))
+	})
+
+	act.cfg.AccessLog = "local"
+
+	t.Run("record reads", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.Show(gptest.CliCtx(ctx, t, "foo")))
+		assert.NoError(t, act.Show(gptest.CliCtxWithFlags(ctx, t, map[string]string{"password": "true"}, "foo")))
+		// so is showing an old revision
+		assert.NoError(t, act.Show(gptest.CliCtxWithFlags(ctx, t, map[string]string{"revision": "latest"}, "bar")))
+		// listing is not recorded
+		assert.NoError(t, act.List(gptest.CliCtx(ctx, t)))
+		// reading the master of a derived password is
+		assert.NoError(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"derived": "true", "master": "foo", "print": "true"}, "derived/example.com", "16")))
+	})
+
+	t.Run("display log", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.LogAccess(gptest.CliCtx(ctx, t)))
+		assert.Equal(t, 2, bytes.Count(buf.Bytes(), []byte(" show foo\n")), buf.String())
+		assert.Contains(t, buf.String(), " show bar\n")
+		assert.Contains(t, buf.String(), " derive foo\n")
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.LogAccess(gptest.CliCtx(ctx, t, "baz")))
+		assert.Equal(t, "", buf.String())
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		defer buf.Reset()
+		fn := filepath.Join(appdir.UserData(), "access-log", "root.log")
+		lines, err := os.ReadFile(fn)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(fn, lines[bytes.IndexByte(lines, '\n')+1:], 0600))
+		assert.Error(t, act.LogAccess(gptest.CliCtx(ctx, t)))
+	})
+}
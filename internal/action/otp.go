@@ -3,6 +3,9 @@ package action
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"path"
 	"strings"
 	"time"
 
@@ -10,17 +13,14 @@ import (
 	"github.com/gopasspw/gopass/internal/store"
 	"github.com/gopasspw/gopass/pkg/clipboard"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/fsutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
 	"github.com/gopasspw/gopass/pkg/otp"
 
+	"github.com/gokyle/twofactor"
 	"github.com/urfave/cli/v2"
 )
 
-const (
-	// we might want to replace this with the currently un-exported step value
-	// from twofactor.FromURL if it gets ever exported
-	otpPeriod = 30
-)
-
 // OTP implements OTP token handling for TOTP and HOTP
 func (s *Action) OTP(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
@@ -37,7 +37,8 @@ func (s *Action) OTP(c *cli.Context) error {
 }
 
 func (s *Action) otp(ctx context.Context, name, qrf string, clip, pw, recurse bool) error {
-	sec, err := s.Store.Get(ctx, name)
+	ctx = s.Store.WithMountContext(ctx, name)
+	sec, err := s.Store.Get(ctxutil.WithAccessAction(ctx, "otp"), name)
 	if err != nil {
 		return s.otpHandleError(ctx, name, qrf, clip, pw, recurse, err)
 	}
@@ -47,24 +48,28 @@ func (s *Action) otp(ctx context.Context, name, qrf string, clip, pw, recurse bo
 	}
 	token := two.OTP()
 
-	now := time.Now()
-	t := now.Add(otpPeriod * time.Second)
-
-	expiresAt := time.Unix(t.Unix()+otpPeriod-(t.Unix()%otpPeriod), 0)
-	secondsLeft := int(time.Until(expiresAt).Seconds())
-
-	if secondsLeft >= otpPeriod {
-		secondsLeft -= otpPeriod
+	if two.Type() == twofactor.OATH_HOTP {
+		// HOTP codes are only valid once, so we need to store the new counter
+		nsec, err := otp.UpdateCounter(sec, two)
+		if err != nil {
+			return ExitError(ExitUnknown, err, "failed to update HOTP counter for %s: %s", name, err)
+		}
+		if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, "Increment HOTP counter"), name, nsec); err != nil {
+			return ExitError(ExitEncrypt, err, "failed to save HOTP counter for %s: %s", name, err)
+		}
 	}
 
-	if pw {
+	switch {
+	case pw || two.Type() == twofactor.OATH_HOTP:
 		out.Printf(ctx, "%s", token)
-	} else {
-		out.Printf(ctx, "%s lasts %ds \t|%s%s|", token, secondsLeft, strings.Repeat("-", otpPeriod-secondsLeft), strings.Repeat("=", secondsLeft))
+	default:
+		period := int(two.Period().Seconds())
+		secondsLeft := period - int(time.Now().Unix()%int64(period))
+		out.Printf(ctx, "%s lasts %ds \t|%s%s|", token, secondsLeft, strings.Repeat("-", period-secondsLeft), strings.Repeat("=", secondsLeft))
 	}
 
 	if clip {
-		if err := clipboard.CopyTo(ctx, fmt.Sprintf("token for %s", name), []byte(token), s.cfg.ClipTimeout); err != nil {
+		if err := clipboard.CopyTo(ctx, fmt.Sprintf("token for %s", name), []byte(token), s.Store.Config(ctx, name).ClipTimeout); err != nil {
 			return ExitError(ExitIO, err, "failed to copy to clipboard: %s", err)
 		}
 		return nil
@@ -89,3 +94,116 @@ func (s *Action) otpHandleError(ctx context.Context, name, qrf string, clip, pw,
 	}
 	return nil
 }
+
+// OTPEnroll reads an otpauth URL from a QR code image and stores it in a
+// secret. Google Authenticator exports (otpauth-migration://) are imported
+// into one secret per token below the given name.
+func (s *Action) OTPEnroll(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	name := c.Args().First()
+	fn := c.String("qr-image")
+	if name == "" || fn == "" {
+		return ExitError(ExitUsage, nil, "Usage: %s otp enroll <NAME> --qr-image <FILE>", s.Name)
+	}
+	force := c.Bool("force")
+
+	fh, err := os.Open(fn)
+	if err != nil {
+		return ExitError(ExitIO, err, "failed to open %s: %s", fn, err)
+	}
+	defer func() {
+		_ = fh.Close()
+	}()
+
+	content, err := otp.DecodeQR(fh)
+	if err != nil {
+		return ExitError(ExitUsage, err, "failed to decode QR code from %s: %s", fn, err)
+	}
+
+	if strings.HasPrefix(content, otp.MigrationScheme+":") {
+		return s.otpImport(ctx, name, content, force)
+	}
+
+	// use the same parser as otp.Calculate, e.g. twofactor doesn't know
+	// Steam tokens
+	if _, err := otp.FromURL(content); err != nil {
+		return ExitError(ExitUsage, err, "QR code does not contain a valid OTP URL: %s", err)
+	}
+	return s.otpEnroll(ctx, name, content, force)
+}
+
+func (s *Action) otpImport(ctx context.Context, prefix, content string, force bool) error {
+	tokens, err := otp.FromMigrationURL(content)
+	if err != nil {
+		return ExitError(ExitUsage, err, "failed to parse Google Authenticator export: %s", err)
+	}
+
+	out.Printf(ctx, "Importing %d OTP tokens", len(tokens))
+	var failed int
+	for _, t := range tokens {
+		name := path.Join(prefix, otpEntryName(t))
+		if err := s.otpEnroll(ctx, name, t.URL(t.Label()), force); err != nil {
+			failed++
+			out.Errorf(ctx, "Failed to import %s: %s", name, err)
+		}
+	}
+	if failed > 0 {
+		return ExitError(ExitUnknown, nil, "failed to import %d of %d OTP tokens", failed, len(tokens))
+	}
+	return nil
+}
+
+func (s *Action) otpEnroll(ctx context.Context, name, otpURL string, force bool) error {
+	sec := secrets.New()
+	if s.Store.Exists(ctx, name) {
+		var err error
+		sec, err = s.Store.Get(ctx, name)
+		if err != nil {
+			return ExitError(ExitDecrypt, err, "failed to decrypt %s: %s", name, err)
+		}
+		if _, found := sec.Get("otpauth"); found && !force {
+			return ExitError(ExitAborted, nil, "%s already contains an OTP URL. Use --force to overwrite it", name)
+		}
+	}
+
+	// store it the same way as an otpauth:// line in the body would be parsed
+	if err := sec.Set("otpauth", strings.TrimPrefix(otpURL, "otpauth:")); err != nil {
+		w, ok := sec.(io.StringWriter)
+		if !ok {
+			return ExitError(ExitUnknown, err, "failed to add OTP URL to %s: %s", name, err)
+		}
+		_, _ = w.WriteString(otpURL + "\n")
+	}
+
+	if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, "Enrolled OTP"), name, sec); err != nil {
+		return ExitError(ExitEncrypt, err, "failed to save %s: %s", name, err)
+	}
+	out.OKf(ctx, "Stored OTP URL in %s", name)
+
+	// print the first code, most sites ask for it to confirm the enrollment
+	return s.otp(ctx, name, "", false, false, false)
+}
+
+// otpEntryName turns the label of a token (usually issuer:account) into
+// a secret name
+func otpEntryName(t *otp.Token) string {
+	issuer := t.Issuer()
+	account := t.Label()
+	if i := strings.Index(account, ":"); i >= 0 {
+		if issuer == "" {
+			issuer = account[:i]
+		}
+		account = account[i+1:]
+	}
+
+	parts := make([]string, 0, 2)
+	for _, p := range []string{issuer, account} {
+		if p := fsutil.CleanFilename(strings.TrimSpace(p)); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	if len(parts) < 1 {
+		return "otp"
+	}
+	return path.Join(parts...)
+}
@@ -3,6 +3,8 @@ package action
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
+	"net/url"
 	"os"
 	"path/filepath"
 	"testing"
@@ -15,6 +17,8 @@ import (
 	"github.com/gokyle/twofactor"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+	"rsc.io/qr"
 )
 
 func TestOTP(t *testing.T) {
@@ -62,3 +66,143 @@ func TestOTP(t *testing.T) {
 		assert.FileExists(t, fn)
 	})
 }
+
+func TestHOTP(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+	}()
+
+	sec := &secrets.Plain{}
+	sec.SetPassword("foo")
+	sec.WriteString("otpauth://hotp/foo?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ")
+	require.NoError(t, act.Store.Set(ctx, "bar", sec))
+
+	// the counter is persisted, so every call returns the next code
+	// (RFC 4226, Appendix D)
+	for _, code := range []string{"755224", "287082", "359152"} {
+		buf.Reset()
+		assert.NoError(t, act.OTP(gptest.CliCtxWithFlags(ctx, t, map[string]string{"password": "true"}, "bar")))
+		assert.Equal(t, code+"\n", buf.String())
+	}
+
+	sec2, err := act.Store.Get(ctx, "bar")
+	require.NoError(t, err)
+	assert.Contains(t, string(sec2.Bytes()), "counter=3")
+}
+
+func TestOTPEnroll(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+	}()
+
+	writeQR := func(content string) string {
+		code, err := qr.Encode(content, qr.Q)
+		require.NoError(t, err)
+		fn := filepath.Join(u.Dir, "qr.png")
+		require.NoError(t, os.WriteFile(fn, code.PNG(), 0o600))
+		return fn
+	}
+
+	t.Run("enroll w/o image", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.OTPEnroll(gptest.CliCtx(ctx, t, "site")))
+	})
+
+	t.Run("enroll invalid URL", func(t *testing.T) {
+		defer buf.Reset()
+		fn := writeQR("https://www.example.com/")
+		assert.Error(t, act.OTPEnroll(gptest.CliCtxWithFlags(ctx, t, map[string]string{"qr-image": fn}, "site")))
+	})
+
+	t.Run("enroll new secret", func(t *testing.T) {
+		defer buf.Reset()
+		fn := writeQR("otpauth://totp/example?secret=JBSWY3DPEHPK3PXP&issuer=Example")
+		assert.NoError(t, act.OTPEnroll(gptest.CliCtxWithFlags(ctx, t, map[string]string{"qr-image": fn}, "site")))
+		assert.Contains(t, buf.String(), "lasts")
+
+		sec, err := act.Store.Get(ctx, "site")
+		require.NoError(t, err)
+		v, found := sec.Get("otpauth")
+		assert.True(t, found)
+		assert.Equal(t, "//totp/example?secret=JBSWY3DPEHPK3PXP&issuer=Example", v)
+
+		// existing OTP URLs are not overwritten
+		assert.Error(t, act.OTPEnroll(gptest.CliCtxWithFlags(ctx, t, map[string]string{"qr-image": fn}, "site")))
+		assert.NoError(t, act.OTPEnroll(gptest.CliCtxWithFlags(ctx, t, map[string]string{"qr-image": fn, "force": "true"}, "site")))
+	})
+
+	t.Run("enroll existing secret", func(t *testing.T) {
+		defer buf.Reset()
+		fn := writeQR("otpauth://totp/example?secret=JBSWY3DPEHPK3PXP")
+		assert.NoError(t, act.OTPEnroll(gptest.CliCtxWithFlags(ctx, t, map[string]string{"qr-image": fn}, "foo")))
+
+		sec, err := act.Store.Get(ctx, "foo")
+		require.NoError(t, err)
+		assert.Equal(t, "secret", sec.Password())
+		assert.Contains(t, string(sec.Bytes()), "otpauth: //totp/example")
+	})
+
+	t.Run("enroll steam token", func(t *testing.T) {
+		defer buf.Reset()
+		fn := writeQR("otpauth://steam/Steam:alice?secret=JBSWY3DPEHPK3PXP")
+		assert.NoError(t, act.OTPEnroll(gptest.CliCtxWithFlags(ctx, t, map[string]string{"qr-image": fn}, "steam")))
+		assert.Contains(t, buf.String(), "Stored OTP URL in steam")
+
+		sec, err := act.Store.Get(ctx, "steam")
+		require.NoError(t, err)
+		v, found := sec.Get("otpauth")
+		assert.True(t, found)
+		assert.Equal(t, "//steam/Steam:alice?secret=JBSWY3DPEHPK3PXP", v)
+	})
+
+	t.Run("import Google Authenticator export", func(t *testing.T) {
+		defer buf.Reset()
+		// two TOTP tokens, Example:alice@example.com and bob
+		var payload []byte
+		for _, label := range []string{"Example:alice@example.com", "bob"} {
+			var p []byte
+			p = protowire.AppendTag(p, 1, protowire.BytesType)
+			p = protowire.AppendBytes(p, []byte("12345678901234567890"))
+			p = protowire.AppendTag(p, 2, protowire.BytesType)
+			p = protowire.AppendString(p, label)
+			payload = protowire.AppendTag(payload, 1, protowire.BytesType)
+			payload = protowire.AppendBytes(payload, p)
+		}
+		fn := writeQR("otpauth-migration://offline?data=" + url.QueryEscape(base64.StdEncoding.EncodeToString(payload)))
+		assert.NoError(t, act.OTPEnroll(gptest.CliCtxWithFlags(ctx, t, map[string]string{"qr-image": fn}, "2fa")))
+		assert.Contains(t, buf.String(), "Importing 2 OTP tokens")
+
+		for _, name := range []string{"2fa/Example/alice@example.com", "2fa/bob"} {
+			sec, err := act.Store.Get(ctx, name)
+			require.NoError(t, err, name)
+			_, found := sec.Get("otpauth")
+			assert.True(t, found, name)
+		}
+	})
+}
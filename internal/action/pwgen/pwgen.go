@@ -2,9 +2,11 @@ package pwgen
 
 import (
 	"fmt"
+	"os"
 	"strconv"
 
 	"github.com/gopasspw/gopass/internal/action"
+	"github.com/gopasspw/gopass/internal/config"
 	"github.com/gopasspw/gopass/pkg/pwgen"
 	"github.com/gopasspw/gopass/pkg/pwgen/xkcdgen"
 	"github.com/urfave/cli/v2"
@@ -35,14 +37,32 @@ func Pwgen(c *cli.Context) error {
 		}
 	}
 
+	minEntropy := config.LoadWithFallbackRelaxed().MinEntropy
 	if c.Bool("xkcd") {
-		return xkcdGen(c, pwNum)
+		return xkcdGen(c, pwNum, minEntropy)
 	}
 
-	return pwGen(c, pwLen, pwNum)
+	return pwGen(c, pwLen, pwNum, minEntropy)
 }
 
-func xkcdGen(c *cli.Context, num int) error {
+// checkEntropy prints the entropy of the passwords to stderr, so it does not
+// mix with the passwords, and refuses passwords below the configured minimum
+func checkEntropy(bits float64, minEntropy int) error {
+	fmt.Fprintf(os.Stderr, "Entropy: %.1f bits\n", bits)
+	if minEntropy > 0 && bits < float64(minEntropy) {
+		return action.ExitError(action.ExitUsage, nil, "passwords with %.1f bits of entropy are too weak, at least %d bits are required (minentropy)", bits, minEntropy)
+	}
+	return nil
+}
+
+func xkcdGen(c *cli.Context, num, minEntropy int) error {
+	bits, err := xkcdgen.Entropy(4, c.String("lang"))
+	if err != nil {
+		return action.ExitError(action.ExitUsage, err, "%s", err)
+	}
+	if err := checkEntropy(bits, minEntropy); err != nil {
+		return err
+	}
 	for i := 0; i < num; i++ {
 		s, err := xkcdgen.RandomLengthDelim(4, c.String("sep"), c.String("lang"))
 		if err != nil {
@@ -53,7 +73,7 @@ func xkcdGen(c *cli.Context, num int) error {
 	return nil
 }
 
-func pwGen(c *cli.Context, pwLen, pwNum int) error {
+func pwGen(c *cli.Context, pwLen, pwNum, minEntropy int) error {
 	perLine := numPerLine(pwLen)
 	if c.Bool("one-per-line") {
 		perLine = 1
@@ -70,6 +90,9 @@ func pwGen(c *cli.Context, pwLen, pwNum int) error {
 	if c.Bool("ambiguous") {
 		charset = pwgen.Prune(charset, pwgen.Ambiq)
 	}
+	if err := checkEntropy(pwgen.CharsetEntropy(pwLen, charset), minEntropy); err != nil {
+		return err
+	}
 	for i := 0; i < pwNum; i++ {
 		for j := 0; j < perLine; j++ {
 			fmt.Print(pwgen.GeneratePasswordCharset(pwLen, charset))
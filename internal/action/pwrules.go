@@ -0,0 +1,185 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/pwgen"
+	"github.com/gopasspw/gopass/pkg/pwgen/pwrules"
+	"github.com/urfave/cli/v2"
+)
+
+// loadStoreRules makes the password rules of all mounted stores available to
+// the generators
+func (s *Action) loadStoreRules(ctx context.Context) {
+	pwrules.SetStoreRules(s.Store.AllPasswordRules(ctx))
+}
+
+// PwrulesList prints the custom password rules of the user and all stores
+func (s *Action) PwrulesList(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	printRules(ctx, pwrules.CustomRules(), pwrules.SourceUser)
+	for _, alias := range append([]string{""}, s.Store.MountPoints()...) {
+		rules, err := s.Store.PasswordRules(ctx, alias)
+		if err != nil {
+			out.Warningf(ctx, "Invalid password rules in store %q: %s", alias, err)
+			continue
+		}
+		src := pwrules.SourceStore
+		if alias != "" {
+			src += " " + alias
+		}
+		printRules(ctx, rules, src)
+	}
+	if c.Bool("all") {
+		printRules(ctx, pwrules.BuiltinRules(), pwrules.SourceBuiltin)
+	}
+	return nil
+}
+
+func printRules(ctx context.Context, rules map[string]string, src string) {
+	domains := make([]string, 0, len(rules))
+	for k := range rules {
+		domains = append(domains, k)
+	}
+	sort.Strings(domains)
+	for _, d := range domains {
+		out.Printf(ctx, "%s: %s (%s)", d, rules[d], src)
+	}
+}
+
+// PwrulesAdd adds or replaces a custom password rule
+func (s *Action) PwrulesAdd(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	domain := c.Args().First()
+	rule := strings.Join(c.Args().Tail(), " ")
+
+	if domain == "" || rule == "" {
+		return ExitError(ExitUsage, nil, "Usage: %s pwrules add <domain> <rule>", s.Name)
+	}
+	if err := pwrules.ValidateRule(rule); err != nil {
+		return ExitError(ExitUsage, err, "Invalid rule: %s", err)
+	}
+	if p := pwrules.ParseRule(rule).Pattern; p != "" {
+		if _, err := pwgen.ParsePattern(p); err != nil {
+			return ExitError(ExitUsage, err, "Invalid pattern %q: %s", p, err)
+		}
+	}
+
+	if !c.IsSet("store") {
+		if err := pwrules.AddCustomRule(domain, rule); err != nil {
+			return ExitError(ExitIO, err, "Failed to save rule: %s", err)
+		}
+		out.OKf(ctx, "Added rule for %q", domain)
+		return nil
+	}
+
+	alias := c.String("store")
+	rules, err := s.Store.PasswordRules(ctx, alias)
+	if err != nil {
+		return ExitError(ExitMount, err, "Failed to read password rules of store %q: %s", alias, err)
+	}
+	rules[domain] = rule
+	if err := s.Store.SetPasswordRules(ctxutil.WithCommitMessage(ctx, fmt.Sprintf("Add password rule for %s", domain)), alias, rules); err != nil {
+		return ExitError(ExitIO, err, "Failed to save rule: %s", err)
+	}
+	out.OKf(ctx, "Added rule for %q to store %q", domain, alias)
+	return nil
+}
+
+// PwrulesRemove removes a custom password rule
+func (s *Action) PwrulesRemove(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	domain := c.Args().First()
+
+	if domain == "" {
+		return ExitError(ExitUsage, nil, "Usage: %s pwrules remove <domain>", s.Name)
+	}
+
+	if !c.IsSet("store") {
+		if err := pwrules.RemoveCustomRule(domain); err != nil {
+			return ExitError(ExitNotFound, err, "Failed to remove rule: %s", err)
+		}
+		out.OKf(ctx, "Removed rule for %q", domain)
+		return nil
+	}
+
+	alias := c.String("store")
+	rules, err := s.Store.PasswordRules(ctx, alias)
+	if err != nil {
+		return ExitError(ExitMount, err, "Failed to read password rules of store %q: %s", alias, err)
+	}
+	if _, found := rules[domain]; !found {
+		return ExitError(ExitNotFound, nil, "No rule for %q in store %q", domain, alias)
+	}
+	delete(rules, domain)
+	if err := s.Store.SetPasswordRules(ctxutil.WithCommitMessage(ctx, fmt.Sprintf("Remove password rule for %s", domain)), alias, rules); err != nil {
+		return ExitError(ExitIO, err, "Failed to save rules: %s", err)
+	}
+	out.OKf(ctx, "Removed rule for %q from store %q", domain, alias)
+	return nil
+}
+
+// PwrulesTest shows the effective rule for a domain and a sample password
+func (s *Action) PwrulesTest(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	domain := c.Args().First()
+
+	if domain == "" {
+		return ExitError(ExitUsage, nil, "Usage: %s pwrules test <domain> [length]", s.Name)
+	}
+
+	s.loadStoreRules(ctx)
+	r, src, found := pwrules.LookupRuleSource(domain)
+	if !found {
+		return ExitError(ExitNotFound, nil, "No password rule for %q", domain)
+	}
+
+	out.Printf(ctx, "Rule for %s (%s):", domain, src)
+	if r.Pattern != "" {
+		out.Printf(ctx, "  pattern: %s", r.Pattern)
+		p, err := pwgen.ParsePattern(r.Pattern)
+		if err != nil {
+			return ExitError(ExitUsage, err, "Invalid pattern %q: %s", r.Pattern, err)
+		}
+		out.Printf(ctx, "Sample: %s (%.1f bits of entropy)", p.Password(), p.Entropy())
+		return nil
+	}
+
+	out.Printf(ctx, "  minlength: %d", r.Minlen)
+	if r.Maxlen > 0 && r.Maxlen < math.MaxInt32 {
+		out.Printf(ctx, "  maxlength: %d", r.Maxlen)
+	}
+	if len(r.Required) > 0 {
+		out.Printf(ctx, "  required: %s", strings.Join(r.Required, ", "))
+	}
+	if len(r.Allowed) > 0 {
+		out.Printf(ctx, "  allowed: %s", strings.Join(r.Allowed, ", "))
+	}
+	if r.Maxconsec > 0 {
+		out.Printf(ctx, "  max-consecutive: %d", r.Maxconsec)
+	}
+
+	length := defaultLength
+	if ls := c.Args().Get(1); ls != "" {
+		iv, err := strconv.Atoi(ls)
+		if err != nil {
+			return ExitError(ExitUsage, err, "password length must be a number")
+		}
+		length = iv
+	}
+	g := pwgen.NewCrypticForDomain(length, domain)
+	pw := g.Password()
+	if pw == "" {
+		return ExitError(ExitUnknown, nil, "Failed to generate a password matching the rule")
+	}
+	out.Printf(ctx, "Sample: %s (%.1f bits of entropy)", pw, g.Entropy())
+	return nil
+}
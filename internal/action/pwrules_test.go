@@ -0,0 +1,94 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store/leaf"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/pwgen/pwrules"
+	"github.com/gopasspw/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPwrules(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		pwrules.SetStoreRules(nil)
+	}()
+
+	store := map[string]string{"store": ""}
+
+	t.Run("invalid rules", func(t *testing.T) {
+		defer buf.Reset()
+
+		assert.Error(t, act.PwrulesAdd(gptest.CliCtx(ctx, t, "intranet.example.com")))
+		assert.Error(t, act.PwrulesAdd(gptest.CliCtx(ctx, t, "intranet.example.com", "foo:", "bar")))
+		assert.Error(t, act.PwrulesAdd(gptest.CliCtx(ctx, t, "intranet.example.com", "pattern:", "d{;")))
+		assert.Error(t, act.PwrulesTest(gptest.CliCtx(ctx, t, "intranet.example.com")))
+	})
+
+	t.Run("store rules", func(t *testing.T) {
+		defer buf.Reset()
+
+		assert.NoError(t, act.PwrulesAdd(gptest.CliCtxWithFlags(ctx, t, store, "intranet.example.com", "minlength: 20; maxlength: 24; required: digit;")))
+		assert.FileExists(t, u.StoreDir("")+"/"+leaf.PwRulesFile)
+		buf.Reset()
+
+		assert.NoError(t, act.PwrulesList(gptest.CliCtx(ctx, t)))
+		assert.Contains(t, buf.String(), "intranet.example.com: minlength: 20; maxlength: 24; required: digit; (store)")
+		buf.Reset()
+
+		assert.NoError(t, act.PwrulesTest(gptest.CliCtx(ctx, t, "intranet.example.com")))
+		assert.Contains(t, buf.String(), "Rule for intranet.example.com (store)")
+		assert.Contains(t, buf.String(), "maxlength: 24")
+		assert.Contains(t, buf.String(), "bits of entropy")
+		buf.Reset()
+
+		// generate picks up the store rules
+		assert.NoError(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"print": "true"}, "web/intranet.example.com")))
+		assert.Contains(t, buf.String(), "Using password rules for intranet.example.com")
+	})
+
+	t.Run("user rules", func(t *testing.T) {
+		defer buf.Reset()
+
+		assert.NoError(t, act.PwrulesAdd(gptest.CliCtx(ctx, t, "intranet.example.com", "pattern: a{4}-d{4};")))
+		assert.NoError(t, act.PwrulesTest(gptest.CliCtx(ctx, t, "intranet.example.com")))
+		assert.Contains(t, buf.String(), "Rule for intranet.example.com (user)")
+		assert.Regexp(t, `Sample: [a-zA-Z]{4}-[0-9]{4} `, buf.String())
+		buf.Reset()
+
+		assert.NoError(t, act.PwrulesRemove(gptest.CliCtx(ctx, t, "intranet.example.com")))
+		assert.Error(t, act.PwrulesRemove(gptest.CliCtx(ctx, t, "intranet.example.com")))
+		assert.NoError(t, act.PwrulesTest(gptest.CliCtx(ctx, t, "intranet.example.com")))
+		assert.Contains(t, buf.String(), "Rule for intranet.example.com (store)")
+	})
+
+	t.Run("remove store rules", func(t *testing.T) {
+		defer buf.Reset()
+
+		assert.NoError(t, act.PwrulesRemove(gptest.CliCtxWithFlags(ctx, t, store, "intranet.example.com")))
+		assert.NoFileExists(t, u.StoreDir("")+"/"+leaf.PwRulesFile)
+		assert.Error(t, act.PwrulesRemove(gptest.CliCtxWithFlags(ctx, t, store, "intranet.example.com")))
+		assert.Error(t, act.PwrulesAdd(gptest.CliCtxWithFlags(ctx, t, map[string]string{"store": "nope"}, "intranet.example.com", "minlength: 8;")))
+	})
+}
@@ -3,11 +3,13 @@ package action
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/gopasspw/gopass/internal/tree"
 
 	"github.com/gopasspw/gopass/internal/cui"
 	"github.com/gopasspw/gopass/internal/out"
+	recps "github.com/gopasspw/gopass/internal/recipients"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
 	"github.com/gopasspw/gopass/pkg/termio"
@@ -94,6 +96,18 @@ func (s *Action) RecipientsAdd(c *cli.Context) error {
 
 	debug.Log("adding recipients: %+v", recipients)
 	for _, r := range recipients {
+		// group references are resolved by the store, there is no key to look up
+		if recps.IsGroup(r) {
+			if !termio.AskForConfirmation(ctx, fmt.Sprintf("Do you want to add the group %q as a recipient to the store %q?", r, store)) {
+				continue
+			}
+			if err := s.Store.AddRecipient(ctx, store, r); err != nil {
+				return ExitError(ExitRecipients, err, "failed to add recipient %q: %s", r, err)
+			}
+			added++
+			continue
+		}
+
 		keys, err := crypto.FindRecipients(ctx, r)
 		if err != nil {
 			out.Printf(ctx, "WARNING: Failed to list public key %q: %s", r, err)
@@ -156,6 +170,15 @@ func (s *Action) RecipientsRemove(c *cli.Context) error {
 	}
 
 	for _, r := range recipients {
+		if recps.IsGroup(r) {
+			if err := s.Store.RemoveRecipient(ctx, store, r); err != nil {
+				return ExitError(ExitRecipients, err, "failed to remove recipient %q: %s", r, err)
+			}
+			fmt.Fprintf(stdout, removalWarning, r)
+			removed++
+			continue
+		}
+
 		kl, err := crypto.FindIdentities(ctx, r)
 		if err == nil {
 			if len(kl) > 0 {
@@ -247,3 +270,122 @@ func (s *Action) recipientsSelectForAdd(ctx context.Context, store string) ([]st
 		return nil, ExitError(ExitAborted, nil, "user aborted")
 	}
 }
+
+// RecipientsGroupList prints all recipient groups of a store
+func (s *Action) RecipientsGroupList(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	store := c.String("store")
+
+	g, err := s.Store.ListGroups(ctx, store)
+	if err != nil {
+		return ExitError(ExitRecipients, err, "failed to list recipient groups: %s", err)
+	}
+
+	for _, name := range g.Names() {
+		fmt.Fprintf(stdout, "%s%s: %s\n", recps.GroupPrefix, name, strings.Join(g[name], ", "))
+	}
+	return nil
+}
+
+// RecipientsGroupAdd adds recipients to a group. All secrets that are
+// encrypted for this group will be re-encrypted.
+func (s *Action) RecipientsGroupAdd(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	store := c.String("store")
+	force := c.Bool("force")
+
+	if c.Args().Len() < 2 {
+		return ExitError(ExitUsage, nil, "Usage: %s recipients group add <group> <recipient> [<recipient>...]", s.Name)
+	}
+	group := recps.GroupName(c.Args().First())
+	crypto := s.Store.Crypto(ctx, store)
+
+	members := make([]string, 0, c.Args().Len()-1)
+	for _, r := range c.Args().Tail() {
+		if !recps.IsGroup(r) {
+			keys, err := crypto.FindRecipients(ctx, r)
+			if (err != nil || len(keys) < 1) && !force {
+				out.Printf(ctx, "WARNING: No matching valid key found for %q. Use --force to add it anyway.", r)
+				continue
+			}
+		}
+		members = append(members, r)
+	}
+	if len(members) < 1 {
+		return ExitError(ExitUnknown, nil, "no member added")
+	}
+
+	if err := s.Store.AddGroupMembers(ctx, store, group, members...); err != nil {
+		return ExitError(ExitRecipients, err, "failed to add members to group %q: %s", group, err)
+	}
+
+	out.Printf(ctx, "\nAdded %d members to group %s%s", len(members), recps.GroupPrefix, group)
+	out.Printf(ctx, "You need to run 'gopass sync' to push these changes")
+	return nil
+}
+
+// RecipientsGroupRemove removes recipients from a group. All secrets that are
+// encrypted for this group will be re-encrypted.
+func (s *Action) RecipientsGroupRemove(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	store := c.String("store")
+
+	if c.Args().Len() < 2 {
+		return ExitError(ExitUsage, nil, "Usage: %s recipients group remove <group> <recipient> [<recipient>...]", s.Name)
+	}
+	group := recps.GroupName(c.Args().First())
+	members := c.Args().Tail()
+
+	if err := s.Store.RemoveGroupMembers(ctx, store, group, members...); err != nil {
+		return ExitError(ExitRecipients, err, "failed to remove members from group %q: %s", group, err)
+	}
+
+	for _, m := range members {
+		fmt.Fprintf(stdout, removalWarning, m)
+	}
+	out.Printf(ctx, "\nRemoved %d members from group %s%s", len(members), recps.GroupPrefix, group)
+	out.Printf(ctx, "You need to run 'gopass sync' to push these changes")
+	return nil
+}
+
+// RecipientsVerify checks the signatures of all recipients files of a store
+func (s *Action) RecipientsVerify(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	store := c.String("store")
+
+	st, err := s.Store.VerifyRecipients(ctx, store)
+	if err != nil {
+		return ExitError(ExitRecipients, err, "failed to verify recipients: %s", err)
+	}
+
+	var failed int
+	for _, v := range st {
+		switch {
+		case v.Err != nil:
+			failed++
+			out.Errorf(ctx, "%s", v)
+		case !v.Signed:
+			out.Warningf(ctx, "%s", v)
+		default:
+			out.OKf(ctx, "%s", v)
+		}
+	}
+
+	if failed > 0 {
+		return ExitError(ExitRecipients, nil, "%d recipients files failed verification. Review them and run '%s recipients sign' to accept them.", failed, s.Name)
+	}
+	return nil
+}
+
+// RecipientsSign signs all recipients files of a store
+func (s *Action) RecipientsSign(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	store := c.String("store")
+
+	if err := s.Store.SignRecipients(ctx, store); err != nil {
+		return ExitError(ExitRecipients, err, "failed to sign recipients: %s", err)
+	}
+
+	out.OKf(ctx, "Signed recipients")
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/gopasspw/gopass/internal/out"
@@ -82,3 +83,117 @@ func TestRecipients(t *testing.T) {
 		assert.NoError(t, act.RecipientsRemove(gptest.CliCtx(ctx, t, "0xDEADBEEF")))
 	})
 }
+
+func TestRecipientsGroups(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	stdout = buf
+	color.NoColor = true
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		stdout = os.Stdout
+	}()
+
+	t.Run("add group members w/o args", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.RecipientsGroupAdd(gptest.CliCtx(ctx, t, "sre")))
+	})
+
+	t.Run("add unknown key to group", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.RecipientsGroupAdd(gptest.CliCtx(ctx, t, "sre", "0xBEEFFEED")))
+	})
+
+	t.Run("add group members", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.RecipientsGroupAdd(gptest.CliCtx(ctx, t, "@sre", "0xFEEDBEEF")))
+		assert.NoError(t, act.RecipientsGroupAdd(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true"}, "sre", "0xBEEFFEED")))
+	})
+
+	t.Run("list groups", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.RecipientsGroupList(gptest.CliCtx(ctx, t)))
+		assert.Equal(t, "@sre: 0xBEEFFEED, 0xFEEDBEEF\n", buf.String())
+	})
+
+	t.Run("add group as recipient", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.RecipientsAdd(gptest.CliCtx(ctx, t, "@sre")))
+		assert.Contains(t, act.Store.ListRecipients(ctx, ""), "0xBEEFFEED")
+	})
+
+	t.Run("remove group members", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.RecipientsGroupRemove(gptest.CliCtx(ctx, t, "sre", "0xBEEFFEED")))
+		assert.NotContains(t, act.Store.ListRecipients(ctx, ""), "0xBEEFFEED")
+	})
+
+	t.Run("remove group as recipient", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.RecipientsRemove(gptest.CliCtx(ctx, t, "@sre")))
+		assert.NotContains(t, act.Store.ListRecipients(ctx, ""), "0xFEEDBEEF")
+	})
+}
+
+func TestRecipientsSignVerify(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	stdout = buf
+	color.NoColor = true
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		stdout = os.Stdout
+	}()
+
+	t.Run("verify unsigned store", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.RecipientsVerify(gptest.CliCtx(ctx, t)))
+		assert.Contains(t, buf.String(), "not signed")
+	})
+
+	t.Run("sign store", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.RecipientsSign(gptest.CliCtx(ctx, t)))
+		assert.FileExists(t, filepath.Join(u.StoreDir(""), ".plain-id.sig"))
+	})
+
+	t.Run("verify signed store", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.RecipientsVerify(gptest.CliCtx(ctx, t)))
+		assert.Contains(t, buf.String(), "signed by 0xDEADBEEF")
+	})
+
+	t.Run("verify tampered store", func(t *testing.T) {
+		defer buf.Reset()
+		fn := filepath.Join(u.StoreDir(""), ".plain-id")
+		require.NoError(t, os.WriteFile(fn, []byte("0xDEADBEEF\n0xBADC0FFE\n"), 0o600))
+		assert.Error(t, act.RecipientsVerify(gptest.CliCtx(ctx, t)))
+		assert.Contains(t, buf.String(), "signature is invalid")
+	})
+}
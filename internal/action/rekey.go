@@ -0,0 +1,41 @@
+package action
+
+import (
+	"fmt"
+
+	"github.com/gopasspw/gopass/internal/cui"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/termio"
+
+	"github.com/urfave/cli/v2"
+)
+
+// Rekey rotates the data key of a store and re-encrypts all secrets with the
+// new key. Stores that don't use envelope encryption yet are converted.
+func (s *Action) Rekey(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	store := c.String("store")
+
+	// select store
+	if store == "" {
+		store = cui.AskForStore(ctx, s.Store)
+	}
+
+	msg := "Rotated data keys"
+	question := fmt.Sprintf("Rotate the data keys of %q and re-encrypt all secrets?", store)
+	if !s.Store.IsEnvelope(ctx, store) {
+		msg = "Enabled envelope encryption"
+		question = fmt.Sprintf("Convert %q to envelope encryption and re-encrypt all secrets?", store)
+	}
+	if !termio.AskForConfirmation(ctx, question) {
+		return ExitError(ExitAborted, nil, "user aborted")
+	}
+
+	if err := s.Store.Rekey(ctxutil.WithCommitMessage(ctx, msg), store); err != nil {
+		return ExitError(ExitEncrypt, err, "failed to rekey %q: %s", store, err)
+	}
+
+	out.OKf(ctx, "%s for %q", msg, store)
+	return nil
+}
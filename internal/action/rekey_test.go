@@ -0,0 +1,55 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRekey(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithInteractive(ctx, false)
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	stdout = buf
+	color.NoColor = true
+	defer func() {
+		out.Stdout = os.Stdout
+		stdout = os.Stdout
+	}()
+
+	assert.False(t, act.Store.IsEnvelope(ctx, ""))
+
+	// convert
+	assert.NoError(t, act.Rekey(gptest.CliCtxWithFlags(ctx, t, map[string]string{"store": ""})))
+	assert.Contains(t, buf.String(), "Enabled envelope encryption")
+	assert.True(t, act.Store.IsEnvelope(ctx, ""))
+	buf.Reset()
+
+	// rotate
+	assert.NoError(t, act.Rekey(gptest.CliCtxWithFlags(ctx, t, map[string]string{"store": ""})))
+	assert.Contains(t, buf.String(), "Rotated data keys")
+	buf.Reset()
+
+	assert.NoError(t, act.show(ctx, gptest.CliCtx(ctx, t), "foo", false))
+	assert.Equal(t, "secret\nsecond\nthird", buf.String())
+}
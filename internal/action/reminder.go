@@ -3,9 +3,12 @@ package action
 import (
 	"context"
 	"os"
+	"time"
 
 	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store/leaf"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
 )
 
 func (s *Action) printReminder(ctx context.Context) {
@@ -19,6 +22,16 @@ func (s *Action) printReminder(ctx context.Context) {
 		return
 	}
 
+	// Recipient keys are checked once a week. This check only prints
+	// something if there is an actual problem, so it doesn't count towards
+	// the daily reminder.
+	if s.rem.OverdueAfter("keycheck", 7*24*time.Hour) {
+		_ = s.rem.Reset("keycheck")
+		if s.printKeyIssues(ctx) {
+			return
+		}
+	}
+
 	// Note: We only want to print one reminder per day (at most).
 	// So we intentionally return after printing one, leaving the others
 	// for the following days.
@@ -37,3 +50,19 @@ func (s *Action) printReminder(ctx context.Context) {
 		return
 	}
 }
+
+// printKeyIssues warns about any unusable or expiring recipient keys. It
+// returns true if anything was printed.
+func (s *Action) printKeyIssues(ctx context.Context) bool {
+	issues, err := s.Store.CheckRecipientKeys(ctx, leaf.DefaultKeyExpiryWarning)
+	if err != nil {
+		debug.Log("failed to check recipient keys: %s", err)
+	}
+	for _, ki := range issues {
+		out.Warningf(ctx, "%s", ki)
+	}
+	if len(issues) > 0 {
+		out.Notice(ctx, "Run 'gopass fsck' for details.")
+	}
+	return len(issues) > 0
+}
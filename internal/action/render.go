@@ -0,0 +1,194 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/tpl"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/urfave/cli/v2"
+)
+
+// Render implements the render subcommand. It executes a user provided
+// template against the store and writes the result to a file (with
+// restrictive permissions) or stdout. In watch mode the template is rendered
+// again whenever the store or the template changes.
+func (s *Action) Render(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	ctx = tpl.WithStrict(ctx, true)
+
+	if c.Args().Len() != 1 {
+		return ExitError(ExitUsage, nil, "Usage: %s render <template> [-o output] [--watch]", s.Name)
+	}
+	tmplFile := c.Args().First()
+	outFile := c.String("output")
+
+	if !c.Bool("watch") {
+		_, err := s.render(ctx, tmplFile, outFile, nil)
+		return err
+	}
+
+	interval := c.Duration("interval")
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	return s.renderWatch(ctx, tmplFile, outFile, interval)
+}
+
+// renderWatch polls the store and the template for changes and renders the
+// template again if anything changed. It returns once the context is canceled.
+func (s *Action) renderWatch(ctx context.Context, tmplFile, outFile string, interval time.Duration) error {
+	var last []byte
+	var lastSum uint64
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for first := true; ; first = false {
+		sum, err := s.renderFingerprint(ctx, tmplFile)
+		if err != nil {
+			return ExitError(ExitIO, err, "failed to watch store: %s", err)
+		}
+		if first || sum != lastSum {
+			debug.Log("store or template changed, rendering %s", tmplFile)
+			buf, err := s.render(ctx, tmplFile, outFile, last)
+			if err != nil {
+				if first {
+					return err
+				}
+				// keep the last good output, the store might be in the
+				// middle of an update
+				out.Errorf(ctx, "Failed to render %s: %s", tmplFile, err)
+			} else {
+				last = buf
+			}
+			lastSum = sum
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// render executes the template and writes the result. If the result is equal
+// to prev nothing is written.
+func (s *Action) render(ctx context.Context, tmplFile, outFile string, prev []byte) ([]byte, error) {
+	buf, err := os.ReadFile(tmplFile)
+	if err != nil {
+		return nil, ExitError(ExitIO, err, "failed to read template %s: %s", tmplFile, err)
+	}
+
+	name := outFile
+	if name == "" {
+		name = tmplFile
+	}
+	content, err := tpl.Execute(ctx, string(buf), name, nil, s.Store)
+	if err != nil {
+		return nil, ExitError(ExitUnknown, err, "failed to render template %s: %s", tmplFile, err)
+	}
+
+	if prev != nil && bytes.Equal(prev, content) {
+		debug.Log("output of %s unchanged", tmplFile)
+		return content, nil
+	}
+
+	if outFile == "" {
+		if _, err := stdout.Write(content); err != nil {
+			return nil, ExitError(ExitIO, err, "failed to write output: %s", err)
+		}
+		return content, nil
+	}
+
+	if err := writeFileAtomic(outFile, content, 0600); err != nil {
+		return nil, ExitError(ExitIO, err, "failed to write %s: %s", outFile, err)
+	}
+	out.OKf(ctx, "Rendered %s to %s", tmplFile, outFile)
+	return content, nil
+}
+
+// renderFingerprint returns a checksum over the names, sizes and modification
+// times of all files of all mounted stores and the template.
+func (s *Action) renderFingerprint(ctx context.Context, tmplFile string) (uint64, error) {
+	dirs := []string{s.Store.Path()}
+	for _, p := range s.Store.Mounts() {
+		dirs = append(dirs, p)
+	}
+	sort.Strings(dirs)
+
+	h := fnv.New64a()
+	add := func(path string, fi fs.FileInfo) {
+		fmt.Fprintf(h, "%s\x00%d\x00%d\x00", path, fi.Size(), fi.ModTime().UnixNano())
+	}
+
+	fi, err := os.Stat(tmplFile)
+	if err != nil {
+		return 0, err
+	}
+	add(tmplFile, fi)
+
+	for _, dir := range dirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if d.Name() == ".git" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			fi, err := d.Info()
+			if err != nil {
+				return err
+			}
+			add(path, fi)
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	return h.Sum64(), nil
+}
+
+// writeFileAtomic writes to a temporary file in the same directory and moves
+// it into place so readers never see a partially written file
+func writeFileAtomic(fn string, content []byte, mode os.FileMode) error {
+	fh, err := os.CreateTemp(filepath.Dir(fn), "."+filepath.Base(fn)+".*")
+	if err != nil {
+		return err
+	}
+	tmp := fh.Name()
+	defer func() {
+		_ = os.Remove(tmp)
+	}()
+
+	if err := fh.Chmod(mode); err != nil {
+		_ = fh.Close()
+		return err
+	}
+	if _, err := fh.Write(content); err != nil {
+		_ = fh.Close()
+		return err
+	}
+	if err := fh.Sync(); err != nil {
+		_ = fh.Close()
+		return err
+	}
+	if err := fh.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, fn)
+}
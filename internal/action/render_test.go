@@ -0,0 +1,100 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		stdout = os.Stdout
+	}()
+
+	tmpl := filepath.Join(u.Dir, "app.conf.tmpl")
+	require.NoError(t, os.WriteFile(tmpl, []byte(`password = {{ getpw "foo" }}`+"\n"), 0644))
+
+	t.Run("no args", func(t *testing.T) {
+		assert.Error(t, act.Render(gptest.CliCtx(ctx, t)))
+	})
+
+	t.Run("stdout", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.Render(gptest.CliCtx(ctx, t, tmpl)))
+		assert.Equal(t, "password = secret\n", buf.String())
+	})
+
+	t.Run("output file", func(t *testing.T) {
+		defer buf.Reset()
+		fn := filepath.Join(u.Dir, "app.conf")
+		assert.NoError(t, act.Render(gptest.CliCtxWithFlags(ctx, t, map[string]string{"output": fn}, tmpl)))
+
+		content, err := os.ReadFile(fn)
+		require.NoError(t, err)
+		assert.Equal(t, "password = secret\n", string(content))
+
+		fi, err := os.Stat(fn)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+	})
+
+	t.Run("missing secret", func(t *testing.T) {
+		defer buf.Reset()
+		missing := filepath.Join(u.Dir, "missing.tmpl")
+		require.NoError(t, os.WriteFile(missing, []byte(`{{ getpw "does/not/exist" }}`), 0644))
+		assert.Error(t, act.Render(gptest.CliCtx(ctx, t, missing)))
+	})
+
+	t.Run("watch", func(t *testing.T) {
+		defer buf.Reset()
+		fn := filepath.Join(u.Dir, "watch.conf")
+
+		wctx, cancel := context.WithCancel(ctx)
+		done := make(chan error, 1)
+		go func() {
+			done <- act.renderWatch(wctx, tmpl, fn, 10*time.Millisecond)
+		}()
+
+		waitFor := func(want string) {
+			assert.Eventually(t, func() bool {
+				content, err := os.ReadFile(fn)
+				return err == nil && string(content) == want
+			}, 5*time.Second, 10*time.Millisecond)
+		}
+		waitFor("password = secret\n")
+
+		sec := &secrets.Plain{}
+		sec.SetPassword("changed")
+		require.NoError(t, act.Store.Set(ctx, "foo", sec))
+		waitFor("password = changed\n")
+
+		cancel()
+		assert.NoError(t, <-done)
+	})
+}
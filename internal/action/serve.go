@@ -0,0 +1,89 @@
+package action
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/vaultkv"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/urfave/cli/v2"
+)
+
+// Serve implements the serve subcommand. It exposes the store through a Vault
+// KV v2 compatible HTTP API on localhost until it's interrupted.
+func (s *Action) Serve(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	if !c.Bool("vault-compat") {
+		return ExitError(ExitUsage, nil, "Nothing to serve. Use --vault-compat to serve the Vault KV v2 API")
+	}
+
+	addr := c.String("addr")
+	if err := checkLoopback(addr); err != nil {
+		return ExitError(ExitUsage, err, "%s", err)
+	}
+
+	token := c.String("token")
+	if token == "" {
+		var err error
+		token, err = newServeToken()
+		if err != nil {
+			return ExitError(ExitUnknown, err, "failed to generate token: %s", err)
+		}
+	}
+
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return ExitError(ExitIO, err, "failed to listen on %s: %s", addr, err)
+	}
+
+	srv := &http.Server{
+		Handler:           vaultkv.New(ctx, s.Store, token, c.String("mount")),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		sctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(sctx)
+	}()
+
+	fmt.Fprintf(stdout, "export VAULT_ADDR=http://%s\n", l.Addr())
+	fmt.Fprintf(stdout, "export VAULT_TOKEN=%s\n", token)
+	out.Noticef(ctx, "Serving the Vault KV v2 API at mount %q. Press Ctrl+C to stop.", c.String("mount"))
+
+	if err := srv.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return ExitError(ExitIO, err, "failed to serve: %s", err)
+	}
+	return nil
+}
+
+// checkLoopback makes sure the API is not exposed to the network
+func checkLoopback(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid address %q: %w", addr, err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("refusing to listen on %q, only loopback addresses are allowed", addr)
+}
+
+func newServeToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "gp." + hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,87 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServe(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		stdout = os.Stdout
+	}()
+
+	assert.Error(t, act.Serve(gptest.CliCtx(ctx, t)))
+	assert.Error(t, act.Serve(gptest.CliCtxWithFlags(ctx, t, map[string]string{"vault-compat": "true", "addr": "0.0.0.0:8200"})))
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+
+	sctx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() {
+		done <- act.Serve(gptest.CliCtxWithFlags(sctx, t, map[string]string{
+			"vault-compat": "true",
+			"addr":         addr,
+			"token":        "t0ken",
+			"mount":        "secret",
+		}))
+	}()
+
+	var body string
+	require.Eventually(t, func() bool {
+		req, err := http.NewRequest(http.MethodGet, "http://"+addr+"/v1/secret/data/foo", nil)
+		require.NoError(t, err)
+		req.Header.Set("X-Vault-Token", "t0ken")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return false
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		body = string(b)
+		return resp.StatusCode == http.StatusOK
+	}, 5*time.Second, 20*time.Millisecond)
+	assert.Contains(t, body, `"password":"secret"`)
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestCheckLoopback(t *testing.T) {
+	for _, addr := range []string{"127.0.0.1:8200", "localhost:8200", "[::1]:8200"} {
+		assert.NoError(t, checkLoopback(addr), addr)
+	}
+	for _, addr := range []string{"0.0.0.0:8200", ":8200", "192.168.1.1:8200", "example.com:80", "foo"} {
+		assert.Error(t, checkLoopback(addr), addr)
+	}
+}
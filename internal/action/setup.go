@@ -26,6 +26,10 @@ func (s *Action) Setup(c *cli.Context) error {
 
 	ctx = initParseContext(ctx, c)
 
+	if fn := c.String("answers"); fn != "" {
+		return s.setupFromAnswers(ctx, fn)
+	}
+
 	out.Printf(ctx, logo)
 	out.Printf(ctx, "🌟 Welcome to gopass!")
 	out.Printf(ctx, "🌟 Initializing a new password store ...")
@@ -0,0 +1,223 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/fatih/color"
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/backend/crypto/gpg"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store/root"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/pwgen/xkcdgen"
+	"gopkg.in/yaml.v3"
+)
+
+// setupAnswers are the answers to all questions asked by setup. They are read
+// from a YAML file to provision gopass without any prompts.
+type setupAnswers struct {
+	// Crypto and Storage select the backends, e.g. gpgcli and gitfs
+	Crypto  string `yaml:"crypto"`
+	Storage string `yaml:"storage"`
+	// Identity is used for the key pair if no usable one exists and for the
+	// git config
+	Identity struct {
+		Name       string `yaml:"name"`
+		Email      string `yaml:"email"`
+		Passphrase string `yaml:"passphrase"`
+	} `yaml:"identity"`
+	// Path of the root store, defaults to the configured path
+	Path string `yaml:"path"`
+	// Keys are the recipients of the root store, defaults to the first
+	// usable private key
+	Keys []string `yaml:"keys"`
+	// Remote is an optional git remote of the root store
+	Remote string            `yaml:"remote"`
+	Teams  []setupTeam       `yaml:"teams"`
+	Config map[string]string `yaml:"config"`
+}
+
+// setupTeam is a shared store mounted into the root store
+type setupTeam struct {
+	Name   string   `yaml:"name"`
+	Remote string   `yaml:"remote"`
+	Create bool     `yaml:"create"`
+	Path   string   `yaml:"path"`
+	Keys   []string `yaml:"keys"`
+}
+
+func readSetupAnswers(fn string) (*setupAnswers, error) {
+	var buf []byte
+	var err error
+	if fn == "-" {
+		buf, err = io.ReadAll(stdin)
+	} else {
+		buf, err = os.ReadFile(fn)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read answers: %w", err)
+	}
+
+	a := &setupAnswers{}
+	dec := yaml.NewDecoder(bytes.NewReader(buf))
+	dec.KnownFields(true)
+	if err := dec.Decode(a); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to parse answers: %w", err)
+	}
+
+	if a.Crypto != "" && backend.CryptoBackendFromName(a.Crypto) < 0 {
+		return nil, fmt.Errorf("unknown crypto backend %q, use one of %v", a.Crypto, backend.CryptoBackends())
+	}
+	if a.Storage != "" && !contains(backend.StorageBackends(), a.Storage) {
+		return nil, fmt.Errorf("unknown storage backend %q, use one of %v", a.Storage, backend.StorageBackends())
+	}
+	seen := make(map[string]bool, len(a.Teams))
+	for _, t := range a.Teams {
+		if t.Name == "" {
+			return nil, fmt.Errorf("team without name")
+		}
+		if seen[t.Name] {
+			return nil, fmt.Errorf("duplicate team %q", t.Name)
+		}
+		seen[t.Name] = true
+		if !t.Create && t.Remote == "" {
+			return nil, fmt.Errorf("team %q needs a remote to join", t.Name)
+		}
+	}
+	return a, nil
+}
+
+// setupFromAnswers runs the setup without asking any questions
+func (s *Action) setupFromAnswers(ctx context.Context, fn string) error {
+	a, err := readSetupAnswers(fn)
+	if err != nil {
+		return ExitError(ExitUsage, err, "Invalid answers file %q: %s", fn, err)
+	}
+
+	ctx = ctxutil.WithInteractive(ctx, false)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	if a.Crypto != "" {
+		ctx = backend.WithCryptoBackendString(ctx, a.Crypto)
+	}
+	if a.Storage != "" {
+		ctx = backend.WithStorageBackendString(ctx, a.Storage)
+	}
+	if a.Identity.Name != "" {
+		ctx = ctxutil.WithUsername(ctx, a.Identity.Name)
+	}
+	if a.Identity.Email != "" {
+		ctx = ctxutil.WithEmail(ctx, a.Identity.Email)
+	}
+
+	if a.Path != "" {
+		s.cfg.Path = a.Path
+	}
+	keys := make([]string, 0, len(a.Config))
+	for k := range a.Config {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := s.cfg.SetConfigValue(k, a.Config[k]); err != nil {
+			return ExitError(ExitConfig, err, "Failed to set config value %q: %s", k, err)
+		}
+	}
+
+	// need to re-initialize the root store to pick up the backends and path
+	s.Store = root.New(s.cfg)
+	inited, err := s.Store.IsInitialized(ctx)
+	if err != nil {
+		return ExitError(ExitUnknown, err, "Failed to initialized store: %s", err)
+	}
+	if inited {
+		return ExitError(ExitAlreadyInitialized, nil, "Store is already initialized. Aborting.")
+	}
+
+	crypto := s.getCryptoFor(ctx, "")
+	if crypto == nil {
+		return ExitError(ExitGPG, nil, "can not continue without crypto")
+	}
+	if !s.initHasUseablePrivateKeys(ctx, crypto) {
+		if a.Identity.Name == "" || a.Identity.Email == "" {
+			return ExitError(ExitUsage, nil, "No useable private keys. Set identity.name and identity.email to generate one")
+		}
+		out.Printf(ctx, "🔐 No useable cryptographic keys. Generating new key pair")
+		passphrase := a.Identity.Passphrase
+		if passphrase == "" {
+			passphrase = xkcdgen.Random()
+			out.Printf(ctx, color.MagentaString("Passphrase: ")+passphrase)
+		}
+		if err := crypto.GenerateIdentity(ctx, a.Identity.Name, a.Identity.Email, passphrase); err != nil {
+			return ExitError(ExitGPG, err, "Failed to create new private key: %s", err)
+		}
+		out.OKf(ctx, "Key pair generated")
+	}
+
+	rootKeys, err := setupKeys(ctx, crypto, a.Keys)
+	if err != nil {
+		return ExitError(ExitGPG, err, "%s", err)
+	}
+	out.Printf(ctx, "🌟 Configuring your password store ...")
+	if err := s.init(ctxutil.WithHidden(ctx, true), "", s.Store.Path(), rootKeys...); err != nil {
+		return ExitError(ExitUnknown, err, "Failed to init local store: %s", err)
+	}
+	if a.Remote != "" {
+		if err := s.initSetupGitRemote(ctx, "", a.Remote); err != nil {
+			return ExitError(ExitGit, err, "Failed to setup git remote: %s", err)
+		}
+	}
+
+	for _, t := range a.Teams {
+		tctx := out.AddPrefix(ctx, "["+t.Name+"] ")
+		if !t.Create {
+			out.Printf(tctx, "Joining existing team ...")
+			if err := s.clone(ctxutil.WithHidden(tctx, true), t.Remote, t.Name, t.Path); err != nil {
+				return err
+			}
+			continue
+		}
+
+		out.Printf(tctx, "Creating a new team ...")
+		teamKeys, err := setupKeys(tctx, crypto, t.Keys)
+		if err != nil {
+			return ExitError(ExitGPG, err, "%s", err)
+		}
+		if err := s.init(ctxutil.WithHidden(tctx, true), t.Name, t.Path, teamKeys...); err != nil {
+			return ExitError(ExitMount, err, "Failed to init shared store %q: %s", t.Name, err)
+		}
+		if t.Remote != "" {
+			if err := s.initSetupGitRemote(tctx, t.Name, t.Remote); err != nil {
+				return ExitError(ExitGit, err, "Failed to setup git remote for %q: %s", t.Name, err)
+			}
+		}
+	}
+
+	if err := s.cfg.Save(); err != nil {
+		return ExitError(ExitConfig, err, "Failed to save config: %s", err)
+	}
+	out.OKf(ctx, "Configured")
+	return nil
+}
+
+// setupKeys returns the given keys or the first usable private key
+func setupKeys(ctx context.Context, crypto backend.Crypto, keys []string) ([]string, error) {
+	if len(keys) > 0 {
+		return keys, nil
+	}
+	kl, err := crypto.ListIdentities(gpg.WithAlwaysTrust(gpg.WithUseCache(ctx, false), false))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list private keys: %w", err)
+	}
+	if len(kl) < 1 {
+		return nil, fmt.Errorf("no useable private keys found")
+	}
+	debug.Log("using private key %s", kl[0])
+	return kl[:1], nil
+}
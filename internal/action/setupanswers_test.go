@@ -0,0 +1,90 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/backend/crypto/plain"
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/tests/gptest"
+	"github.com/urfave/cli/v2"
+
+	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetupAnswers(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = backend.WithCryptoBackend(ctx, backend.Plain)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+
+	storeDir := filepath.Join(u.Dir, "setup-store")
+	fn := filepath.Join(u.Dir, "setup.yaml")
+	require.NoError(t, os.WriteFile(fn, []byte(`crypto: plain
+storage: fs
+identity:
+  name: John Doe
+  email: john.doe@example.org
+path: `+storeDir+`
+teams:
+  - name: team
+    create: true
+config:
+  nopager: true
+  cliptimeout: 10
+`), 0600))
+
+	cfg := config.New()
+	cfg.Path = u.StoreDir("")
+	act, err := newAction(cfg, semver.Version{}, false)
+	require.NoError(t, err)
+
+	c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"answers": fn})
+	require.NoError(t, act.Setup(c))
+	assert.Equal(t, storeDir, act.cfg.Path)
+	assert.True(t, act.cfg.NoPager)
+	assert.Equal(t, 10, act.cfg.ClipTimeout)
+	assert.FileExists(t, filepath.Join(storeDir, plain.IDFile))
+	assert.Contains(t, act.cfg.Mounts, "team")
+	assert.FileExists(t, filepath.Join(config.PwStoreDir("team"), plain.IDFile))
+
+	// running it again must fail with a meaningful exit code
+	err = act.Setup(c)
+	require.Error(t, err)
+	assert.Equal(t, ExitAlreadyInitialized, err.(cli.ExitCoder).ExitCode())
+
+	t.Run("invalid answers", func(t *testing.T) {
+		for _, in := range []string{
+			"crypto: foo",
+			"storage: foo",
+			"unknown: field",
+			"teams:\n  - name: foo",
+			"teams:\n  - create: true",
+			"[",
+		} {
+			require.NoError(t, os.WriteFile(fn, []byte(in), 0600))
+			err := act.Setup(gptest.CliCtxWithFlags(ctx, t, map[string]string{"answers": fn}))
+			require.Error(t, err, in)
+			assert.Equal(t, ExitUsage, err.(cli.ExitCoder).ExitCode(), in)
+		}
+
+		err := act.Setup(gptest.CliCtxWithFlags(ctx, t, map[string]string{"answers": filepath.Join(u.Dir, "missing.yaml")}))
+		require.Error(t, err)
+	})
+}
@@ -78,11 +78,23 @@ func (s *Action) show(ctx context.Context, c *cli.Context, name string, recurse
 		out.Warningf(ctx, "%s is a secret and a folder. Use 'gopass show %s' to display the secret and 'gopass list %s' to show the content of the folder", name, name, name)
 	}
 
+	// per-mount overrides take precedence over the global config but not
+	// over explicit flags
+	ctx = s.Store.WithMountContext(ctx, name)
+	if c != nil && c.IsSet("noparsing") {
+		ctx = ctxutil.WithShowParsing(ctx, !c.Bool("noparsing"))
+	}
+
+	action := "show"
+	if IsClip(ctx) {
+		action = "clip"
+	}
+
 	if HasRevision(ctx) {
-		return s.showHandleRevision(ctx, c, name, GetRevision(ctx))
+		return s.showHandleRevision(ctx, c, name, GetRevision(ctx), action)
 	}
 
-	sec, err := s.Store.Get(ctx, name)
+	sec, err := s.Store.Get(ctxutil.WithAccessAction(ctx, action), name)
 	if err != nil {
 		return s.showHandleError(ctx, c, name, recurse, err)
 	}
@@ -91,13 +103,13 @@ func (s *Action) show(ctx context.Context, c *cli.Context, name string, recurse
 }
 
 // showHandleRevision displays a single revision
-func (s *Action) showHandleRevision(ctx context.Context, c *cli.Context, name, revision string) error {
+func (s *Action) showHandleRevision(ctx context.Context, c *cli.Context, name, revision, action string) error {
 	revision, err := s.parseRevision(ctx, name, revision)
 	if err != nil {
 		return ExitError(ExitUnknown, err, "Failed to get revisions: %s", err)
 	}
 
-	ctx, sec, err := s.Store.GetRevision(ctx, name, revision)
+	ctx, sec, err := s.Store.GetRevision(ctxutil.WithAccessAction(ctx, action), name, revision)
 	if err != nil {
 		return s.showHandleError(ctx, c, name, false, err)
 	}
@@ -135,6 +147,11 @@ func (s *Action) parseRevision(ctx context.Context, name, revision string) (stri
 
 // showHandleOutput displays a secret
 func (s *Action) showHandleOutput(ctx context.Context, name string, sec gopass.Secret) error {
+	sec, err := s.resolveDerived(ctx, name, sec)
+	if err != nil {
+		return err
+	}
+
 	pw, body, err := s.showGetContent(ctx, sec)
 	if err != nil {
 		return err
@@ -154,7 +171,7 @@ func (s *Action) showHandleOutput(ctx context.Context, name string, sec gopass.S
 	}
 
 	if IsClip(ctx) && pw != "" {
-		if err := clipboard.CopyTo(ctx, name, []byte(pw), s.cfg.ClipTimeout); err != nil {
+		if err := clipboard.CopyTo(ctx, name, []byte(pw), s.Store.Config(ctx, name).ClipTimeout); err != nil {
 			return err
 		}
 	}
@@ -349,7 +349,7 @@ func TestShowHandleRevision(t *testing.T) {
 	t.Run("show foo", func(t *testing.T) {
 		defer buf.Reset()
 		c := gptest.CliCtx(ctx, t)
-		assert.NoError(t, act.showHandleRevision(ctx, c, "foo", "HEAD"))
+		assert.NoError(t, act.showHandleRevision(ctx, c, "foo", "HEAD", "show"))
 	})
 }
 
@@ -0,0 +1,200 @@
+package action
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/shamir"
+	"github.com/gopasspw/gopass/internal/tree"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/pkg/termio"
+
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	sharesSuffix = ".shares"
+	shareName    = "share"
+)
+
+// sharePath returns the name of the n-th share of a secret. Each share lives
+// in its own folder so it can have its own recipients file.
+func sharePath(name string, n int) string {
+	return path.Join(name+sharesSuffix, strconv.Itoa(n), shareName)
+}
+
+// Split splits a secret into shares using Shamir's secret sharing scheme. Each
+// share is encrypted for exactly one recipient, so no single recipient can
+// decrypt the secret on their own.
+func (s *Action) Split(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	name := c.Args().First()
+	if name == "" {
+		return ExitError(ExitUsage, nil, "Usage: %s split <secret> --threshold K --to <recipient> [--to <recipient>...]", s.Name)
+	}
+
+	to := make([]string, 0, len(c.StringSlice("to")))
+	for _, r := range c.StringSlice("to") {
+		for _, rr := range strings.Split(r, ",") {
+			if rr = strings.TrimSpace(rr); rr != "" {
+				to = append(to, rr)
+			}
+		}
+	}
+	n := c.Int("shares")
+	if n == 0 {
+		n = len(to)
+	}
+	if n != len(to) {
+		return ExitError(ExitUsage, nil, "Need exactly one recipient per share, got %d shares and %d recipients", n, len(to))
+	}
+	k := c.Int("threshold")
+
+	crypto := s.Store.Crypto(ctx, name)
+	for _, r := range to {
+		kl, err := crypto.FindRecipients(ctx, r)
+		if (err != nil || len(kl) < 1) && !c.Bool("force") {
+			return ExitError(ExitRecipients, err, "No useable public key found for %q. Use --force to use it anyway.", r)
+		}
+	}
+
+	sec, err := s.Store.Get(ctx, name)
+	if err != nil {
+		return ExitError(ExitDecrypt, err, "failed to decrypt %s: %s", name, err)
+	}
+
+	shares, err := shamir.Split(sec.Bytes(), n, k)
+	if err != nil {
+		return ExitError(ExitUsage, err, "failed to split %s: %s", name, err)
+	}
+
+	ctx = ctxutil.WithCommitMessage(ctx, fmt.Sprintf("Split %s into %d shares", name, n))
+	for i, share := range shares {
+		sp := sharePath(name, i+1)
+		if err := s.Store.SetFolderRecipients(ctx, path.Dir(sp), []string{to[i]}); err != nil {
+			return ExitError(ExitEncrypt, err, "failed to set recipients for %s: %s", sp, err)
+		}
+
+		shareSec := secrets.NewKV()
+		shareSec.SetPassword(base64.StdEncoding.EncodeToString(share))
+		_ = shareSec.Set("threshold", strconv.Itoa(k))
+		_ = shareSec.Set("shares", strconv.Itoa(n))
+		_ = shareSec.Set("recipient", to[i])
+		if err := s.Store.SetFor(ctx, sp, shareSec, []string{to[i]}); err != nil {
+			return ExitError(ExitEncrypt, err, "failed to write share %s: %s", sp, err)
+		}
+		out.Printf(ctx, "Share %d/%d for %s written to %s", i+1, n, to[i], sp)
+	}
+
+	if c.Bool("delete") {
+		if err := s.Store.Delete(ctx, name); err != nil {
+			return ExitError(ExitIO, err, "failed to remove %s: %s", name, err)
+		}
+		out.Printf(ctx, "Removed %s", name)
+	}
+
+	out.OKf(ctx, "Split %s into %d shares, %d of them are required to restore it", name, n, k)
+	return nil
+}
+
+// Combine reconstructs a secret from the shares created by split. Shares that
+// we can decrypt are read from the store, any missing ones are requested from
+// the user (e.g. pasted by the other share holders).
+func (s *Action) Combine(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	name := c.Args().First()
+	if name == "" {
+		return ExitError(ExitUsage, nil, "Usage: %s combine <secret>", s.Name)
+	}
+
+	names, err := s.shareNames(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	threshold := 0
+	shares := make([][]byte, 0, len(names))
+	for _, sn := range names {
+		sec, err := s.Store.Get(ctxutil.WithHidden(ctx, true), sn)
+		if err != nil {
+			debug.Log("can not decrypt share %s: %s", sn, err)
+			continue
+		}
+		share, err := base64.StdEncoding.DecodeString(sec.Password())
+		if err != nil {
+			return ExitError(ExitDecrypt, err, "invalid share %s: %s", sn, err)
+		}
+		if tv, found := sec.Get("threshold"); found {
+			if iv, err := strconv.Atoi(tv); err == nil {
+				threshold = iv
+			}
+		}
+		shares = append(shares, share)
+		out.Printf(ctx, "Using share %s", sn)
+	}
+
+	for threshold == 0 || len(shares) < threshold {
+		if !ctxutil.IsInteractive(ctx) {
+			return ExitError(ExitDecrypt, nil, "Only %d shares available, need %d", len(shares), threshold)
+		}
+		in, err := termio.AskForString(ctx, fmt.Sprintf("Please enter share %d (leave empty to abort)", len(shares)+1), "")
+		if err != nil {
+			return ExitError(ExitAborted, err, "failed to read share: %s", err)
+		}
+		if in == "" {
+			return ExitError(ExitAborted, nil, "user aborted")
+		}
+		share, err := base64.StdEncoding.DecodeString(strings.TrimSpace(in))
+		if err != nil {
+			out.Errorf(ctx, "Invalid share: %s", err)
+			continue
+		}
+		shares = append(shares, share)
+		// without any decryptable share we don't know the threshold, so we
+		// keep asking until the user provides the number of shares they have
+		if threshold == 0 && len(shares) >= 2 && !termio.AskForConfirmation(ctx, "Do you have more shares?") {
+			threshold = len(shares)
+		}
+	}
+
+	plain, err := shamir.Combine(shares)
+	if err != nil {
+		return ExitError(ExitDecrypt, err, "failed to combine shares: %s", err)
+	}
+
+	fmt.Fprint(stdout, string(plain))
+	if len(plain) > 0 && plain[len(plain)-1] != '\n' {
+		fmt.Fprintln(stdout)
+	}
+	return nil
+}
+
+// shareNames returns the names of all shares of the given secret
+func (s *Action) shareNames(ctx context.Context, name string) ([]string, error) {
+	t, err := s.Store.Tree(ctx)
+	if err != nil {
+		return nil, ExitError(ExitList, err, "failed to list store: %s", err)
+	}
+	subtree, err := t.FindFolder(name + sharesSuffix)
+	if err != nil {
+		return nil, ExitError(ExitNotFound, nil, "No shares found for %s", name)
+	}
+
+	names := make([]string, 0, 5)
+	for _, e := range subtree.List(tree.INF) {
+		if path.Base(e) != shareName {
+			continue
+		}
+		names = append(names, e)
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,93 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/backend/crypto/plain"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func splitCtx(ctx context.Context, t *testing.T, args ...string) *cli.Context {
+	return gptest.CliCtxWithFlagDefs(ctx, t, []cli.Flag{
+		&cli.IntFlag{Name: "shares"},
+		&cli.IntFlag{Name: "threshold", Value: 2},
+		&cli.StringSliceFlag{Name: "to"},
+		&cli.BoolFlag{Name: "force"},
+		&cli.BoolFlag{Name: "delete"},
+	}, args...)
+}
+
+func TestSplitCombine(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithInteractive(ctx, false)
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	stdout = buf
+	color.NoColor = true
+	defer func() {
+		out.Stdout = os.Stdout
+		stdout = os.Stdout
+	}()
+
+	// missing secret name
+	assert.Error(t, act.Split(splitCtx(ctx, t)))
+
+	// shares and recipients don't match
+	assert.Error(t, act.Split(splitCtx(ctx, t, "--shares=3", "--to=0xDEADBEEF", "--to=0xBEEFFEED", "foo")))
+
+	// threshold larger than the number of shares
+	assert.Error(t, act.Split(splitCtx(ctx, t, "--threshold=3", "--force", "--to=0xDEADBEEF,0xBEEFFEED", "foo")))
+
+	// split foo into three shares
+	assert.NoError(t, act.Split(splitCtx(ctx, t, "--force", "--to=0xDEADBEEF", "--to=0xBEEFFEED,0xFEEDBEEF", "foo")))
+	assert.Contains(t, buf.String(), "Split foo into 3 shares")
+	buf.Reset()
+
+	for i, r := range []string{"0xDEADBEEF", "0xBEEFFEED", "0xFEEDBEEF"} {
+		sp := sharePath("foo", i+1)
+		assert.True(t, act.Store.Exists(ctx, sp), sp)
+		buf, err := os.ReadFile(filepath.Join(u.StoreDir(""), filepath.Dir(sp), plain.IDFile))
+		require.NoError(t, err)
+		assert.Equal(t, r, strings.TrimSpace(string(buf)))
+	}
+	assert.True(t, act.Store.Exists(ctx, "foo"))
+
+	// combine foo
+	assert.NoError(t, act.Combine(gptest.CliCtx(ctx, t, "foo")))
+	assert.Contains(t, buf.String(), "secret\nsecond\nthird\n")
+	buf.Reset()
+
+	// no shares
+	assert.Error(t, act.Combine(gptest.CliCtx(ctx, t, "bar")))
+	assert.Error(t, act.Combine(gptest.CliCtx(ctx, t)))
+
+	// split and delete
+	require.NoError(t, act.insertStdin(ctx, "bar", []byte("secret"), false))
+	assert.NoError(t, act.Split(splitCtx(ctx, t, "--force", "--delete", "--to=0xDEADBEEF,0xBEEFFEED", "bar")))
+	assert.False(t, act.Store.Exists(ctx, "bar"))
+	buf.Reset()
+
+	assert.NoError(t, act.Combine(gptest.CliCtx(ctx, t, "bar")))
+	assert.Contains(t, buf.String(), "secret\n")
+}
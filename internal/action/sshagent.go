@@ -0,0 +1,57 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/sshagent"
+	"github.com/gopasspw/gopass/pkg/appdir"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/termio"
+	"github.com/urfave/cli/v2"
+)
+
+// SSHAgent implements the ssh-agent subcommand. It serves the private keys
+// below a prefix through the ssh-agent protocol until it's interrupted.
+func (s *Action) SSHAgent(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	socket := c.String("socket")
+	if socket == "" {
+		socket = sshAgentSocket()
+	}
+	if err := os.MkdirAll(filepath.Dir(socket), 0700); err != nil {
+		return ExitError(ExitIO, err, "failed to create directory for %s: %s", socket, err)
+	}
+
+	opts := sshagent.Options{
+		Prefix:   c.String("prefix"),
+		Lifetime: c.Duration("lifetime"),
+	}
+	if c.Bool("confirm") {
+		// never skip the confirmation, even with --yes
+		cctx := ctxutil.WithAlwaysYes(ctx, false)
+		opts.Confirm = func(_ context.Context, name string) bool {
+			return termio.AskForConfirmation(cctx, fmt.Sprintf("Allow use of SSH key %q?", name))
+		}
+	}
+
+	fmt.Fprintf(stdout, "SSH_AUTH_SOCK=%s; export SSH_AUTH_SOCK;\n", socket)
+	out.Noticef(ctx, "Serving SSH keys from %q. Press Ctrl+C to stop.", opts.Prefix)
+
+	if err := sshagent.New(ctx, s.Store, opts).Serve(ctx, socket); err != nil {
+		return ExitError(ExitUnknown, err, "ssh-agent failed: %s", err)
+	}
+	return nil
+}
+
+// sshAgentSocket returns the default location of the agent socket
+func sshAgentSocket() string {
+	if rd := os.Getenv("XDG_RUNTIME_DIR"); rd != "" {
+		return filepath.Join(rd, "gopass", "ssh-agent.sock")
+	}
+	return filepath.Join(appdir.UserCache(), "ssh-agent.sock")
+}
@@ -8,6 +8,7 @@ import (
 	"os/exec"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/gopasspw/gopass/internal/backend/crypto/gpg"
 	"github.com/gopasspw/gopass/internal/backend/crypto/gpg/colons"
@@ -100,6 +101,21 @@ func (g *GPG) FindIdentities(ctx context.Context, search ...string) ([]string, e
 	return kl.UseableKeys(gpg.IsAlwaysTrust(ctx)).Recipients(), nil
 }
 
+// KeyValidity returns the expiration date of the given public key (zero if it
+// never expires) and whether it has been revoked. It returns an error if the
+// key is not in the keyring.
+func (g *GPG) KeyValidity(ctx context.Context, id string) (time.Time, bool, error) {
+	kl, err := g.listKeys(ctx, "public", id)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	k, err := kl.FindKey(id)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return k.ExpirationDate, k.IsRevoked(), nil
+}
+
 func (g *GPG) findKey(ctx context.Context, id string) gpg.Key {
 	kl, _ := g.listKeys(ctx, "secret", id)
 	if len(kl) >= 1 {
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// Sign creates a detached signature of the given data with the given key
+func (g *GPG) Sign(ctx context.Context, id string, data []byte) ([]byte, error) {
+	args := append(g.args, "--armor", "--detach-sign")
+	if id != "" {
+		args = append(args, "--local-user", id)
+	}
+
+	buf := &bytes.Buffer{}
+
+	cmd := exec.CommandContext(ctx, g.binary, args...)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = buf
+	cmd.Stderr = os.Stderr
+
+	debug.Log("%s %+v", cmd.Path, cmd.Args)
+	err := cmd.Run()
+	return buf.Bytes(), err
+}
+
+// Verify checks the detached signature of the given data and returns the
+// fingerprint of the (primary) key that created it
+func (g *GPG) Verify(ctx context.Context, data, sig []byte) (string, error) {
+	tf, err := os.CreateTemp("", "gopass-sig-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create tempfile: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(tf.Name())
+	}()
+	if _, err := tf.Write(sig); err != nil {
+		_ = tf.Close()
+		return "", fmt.Errorf("failed to write signature: %w", err)
+	}
+	if err := tf.Close(); err != nil {
+		return "", fmt.Errorf("failed to write signature: %w", err)
+	}
+
+	args := append(g.args, "--status-fd", "1", "--verify", tf.Name(), "-")
+	cmd := exec.CommandContext(ctx, g.binary, args...)
+	cmd.Stdin = bytes.NewReader(data)
+
+	debug.Log("%s %+v", cmd.Path, cmd.Args)
+	// gpg exits non-zero on bad signatures but we still want the status output
+	cmdout, err := cmd.Output()
+	if fpr := parseValidSig(cmdout); fpr != "" {
+		return fpr, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to verify signature: %w", err)
+	}
+	return "", fmt.Errorf("no valid signature found")
+}
+
+// parseValidSig extracts the primary key fingerprint from the VALIDSIG status
+// line, see doc/DETAILS in the GnuPG sources
+func parseValidSig(buf []byte) string {
+	sc := bufio.NewScanner(bytes.NewReader(buf))
+	for sc.Scan() {
+		p := strings.Fields(sc.Text())
+		if len(p) < 3 || p[0] != "[GNUPG:]" || p[1] != "VALIDSIG" {
+			continue
+		}
+		// the primary key fingerprint is the last field if the signature
+		// was made with a sub key
+		if len(p) >= 12 {
+			return p[11]
+		}
+		return p[2]
+	}
+	return ""
+}
@@ -38,6 +38,11 @@ func (k Key) IsUseable(alwaysTrust bool) bool {
 	return false
 }
 
+// IsRevoked returns true if GPG reports this key as revoked
+func (k Key) IsRevoked() bool {
+	return k.Validity == "r"
+}
+
 // String implement fmt.Stringer. This method produces output that is close to, but
 // not exactly the same, as the output form GPG itself
 func (k Key) String() string {
@@ -120,3 +120,9 @@ func TestUseability(t *testing.T) {
 		assert.True(t, k.IsUseable(false))
 	}
 }
+
+func TestRevoked(t *testing.T) {
+	assert.True(t, Key{Validity: "r"}.IsRevoked())
+	assert.False(t, Key{Validity: "u"}.IsRevoked())
+	assert.False(t, Key{Validity: "r"}.IsUseable(false))
+}
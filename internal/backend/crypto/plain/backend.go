@@ -3,6 +3,7 @@ package plain
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"strings"
 	"time"
@@ -43,6 +44,8 @@ var staticPrivateKeyList = gpg.KeyList{
 	},
 }
 
+const sigPrefix = "plain-signature"
+
 // Mocker is a no-op GPG mock
 type Mocker struct{}
 
@@ -80,6 +83,15 @@ func (m *Mocker) FindIdentities(ctx context.Context, keys ...string) ([]string,
 	return m.FindRecipients(ctx, keys...)
 }
 
+// KeyValidity returns the validity of a static key
+func (m *Mocker) KeyValidity(ctx context.Context, id string) (time.Time, bool, error) {
+	k, err := staticPrivateKeyList.FindKey(id)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return k.ExpirationDate, k.IsRevoked(), nil
+}
+
 // RecipientIDs does nothing
 func (m *Mocker) RecipientIDs(context.Context, []byte) ([]string, error) {
 	return staticPrivateKeyList.Recipients(), nil
@@ -163,3 +175,23 @@ func (m *Mocker) IDFile() string {
 func (m *Mocker) ReadNamesFromKey(ctx context.Context, buf []byte) ([]string, error) {
 	return []string{"unsupported"}, nil
 }
+
+// Sign creates a fake signature that contains a checksum of the data
+func (m *Mocker) Sign(ctx context.Context, id string, data []byte) ([]byte, error) {
+	if id == "" {
+		id = staticPrivateKeyList[0].Fingerprint
+	}
+	return []byte(fmt.Sprintf("%s %s %x\n", sigPrefix, id, sha256.Sum256(data))), nil
+}
+
+// Verify checks a fake signature created by Sign and returns the signer
+func (m *Mocker) Verify(ctx context.Context, data, sig []byte) (string, error) {
+	p := strings.Fields(string(sig))
+	if len(p) != 3 || p[0] != sigPrefix {
+		return "", fmt.Errorf("invalid signature")
+	}
+	if p[2] != fmt.Sprintf("%x", sha256.Sum256(data)) {
+		return "", fmt.Errorf("bad signature")
+	}
+	return p[1], nil
+}
@@ -58,6 +58,13 @@ func TestPlain(t *testing.T) {
 	_, err = m.FindIdentities(ctx)
 	assert.NoError(t, err)
 
+	exp, revoked, err := m.KeyValidity(ctx, "0xDEADBEEF")
+	assert.NoError(t, err)
+	assert.True(t, exp.IsZero())
+	assert.False(t, revoked)
+	_, _, err = m.KeyValidity(ctx, "0xBEEFFEED")
+	assert.Error(t, err)
+
 	buf, err = m.ExportPublicKey(ctx, "")
 	assert.NoError(t, err)
 	assert.NoError(t, m.ImportPublicKey(ctx, buf))
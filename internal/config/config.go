@@ -17,17 +17,21 @@ var (
 
 // Config is the current config struct
 type Config struct {
-	AutoClip      bool              `yaml:"autoclip"`      // decide whether passwords are automatically copied or not
-	AutoImport    bool              `yaml:"autoimport"`    // import missing public keys w/o asking
-	ClipTimeout   int               `yaml:"cliptimeout"`   // clear clipboard after seconds
-	ExportKeys    bool              `yaml:"exportkeys"`    // automatically export public keys of all recipients
-	NoColor       bool              `yaml:"nocolor"`       // do not use color when outputing text
-	NoPager       bool              `yaml:"nopager"`       // do not invoke a pager to display long lists
-	Notifications bool              `yaml:"notifications"` // enable desktop notifications
-	Parsing       bool              `yaml:"parsing"`       // allows to switch off all output parsing
-	Path          string            `yaml:"path"`
-	SafeContent   bool              `yaml:"safecontent"` // avoid showing passwords in terminal
-	Mounts        map[string]string `yaml:"mounts"`
+	AccessLog     string                  `yaml:"accesslog"`     // access log location: local, store or empty to disable
+	AutoClip      bool                    `yaml:"autoclip"`      // decide whether passwords are automatically copied or not
+	AutoImport    bool                    `yaml:"autoimport"`    // import missing public keys w/o asking
+	Clipboard     string                  `yaml:"clipboard"`     // clipboard provider: auto, system or osc52
+	ClipTimeout   int                     `yaml:"cliptimeout"`   // clear clipboard after seconds
+	ExportKeys    bool                    `yaml:"exportkeys"`    // automatically export public keys of all recipients
+	MinEntropy    int                     `yaml:"minentropy"`    // refuse generated passwords with less bits of entropy
+	NoColor       bool                    `yaml:"nocolor"`       // do not use color when outputing text
+	NoPager       bool                    `yaml:"nopager"`       // do not invoke a pager to display long lists
+	Notifications bool                    `yaml:"notifications"` // enable desktop notifications
+	Parsing       bool                    `yaml:"parsing"`       // allows to switch off all output parsing
+	Path          string                  `yaml:"path"`
+	SafeContent   bool                    `yaml:"safecontent"` // avoid showing passwords in terminal
+	Mounts        map[string]string       `yaml:"mounts"`
+	Overrides     map[string]*MountConfig `yaml:"overrides,omitempty"` // per-mount overrides, keyed by mount point
 
 	ConfigPath string `yaml:"-"`
 
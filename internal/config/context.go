@@ -12,6 +12,9 @@ func (c *Config) WithContext(ctx context.Context) context.Context {
 	if !c.AutoImport {
 		ctx = ctxutil.WithImportFunc(ctx, nil)
 	}
+	if !ctxutil.HasClipboardProvider(ctx) {
+		ctx = ctxutil.WithClipboardProvider(ctx, c.Clipboard)
+	}
 	if !ctxutil.HasExportKeys(ctx) {
 		ctx = ctxutil.WithExportKeys(ctx, c.ExportKeys)
 	}
@@ -29,3 +32,28 @@ func (c *Config) WithContext(ctx context.Context) context.Context {
 	}
 	return ctx
 }
+
+// WithMountContext returns a context with the options overridden for the given
+// mount point. Unlike WithContext it replaces values already set from the
+// global config.
+func (c *Config) WithMountContext(ctx context.Context, alias string) context.Context {
+	return c.Overrides[alias].WithContext(ctx)
+}
+
+// WithContext returns a context with the overridden options set. Unlike
+// Config.WithContext it replaces values that are already set.
+func (mc *MountConfig) WithContext(ctx context.Context) context.Context {
+	if mc == nil {
+		return ctx
+	}
+	if mc.Notifications != nil {
+		ctx = ctxutil.WithNotifications(ctx, *mc.Notifications)
+	}
+	if mc.SafeContent != nil {
+		ctx = ctxutil.WithShowSafeContent(ctx, *mc.SafeContent)
+	}
+	if mc.Parsing != nil {
+		ctx = ctxutil.WithShowParsing(ctx, *mc.Parsing)
+	}
+	return ctx
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MountConfig contains the options that can be overridden per mount. Unset
+// options are inherited from the global config.
+type MountConfig struct {
+	AutoClip      *bool `yaml:"autoclip,omitempty"`
+	ClipTimeout   *int  `yaml:"cliptimeout,omitempty"`
+	Notifications *bool `yaml:"notifications,omitempty"`
+	Parsing       *bool `yaml:"parsing,omitempty"`
+	SafeContent   *bool `yaml:"safecontent,omitempty"`
+}
+
+// MountConfigKeys returns the options that can be overridden per mount
+func MountConfigKeys() []string {
+	return []string{"autoclip", "cliptimeout", "notifications", "parsing", "safecontent"}
+}
+
+// ForMount returns a copy of the config with the overrides of the given mount
+// point applied. The root store (empty alias) always uses the global config.
+func (c *Config) ForMount(alias string) *Config {
+	return c.Apply(c.Overrides[alias])
+}
+
+// Apply returns a copy of the config with the given overrides applied
+func (c *Config) Apply(mc *MountConfig) *Config {
+	if mc == nil || *mc == (MountConfig{}) {
+		return c
+	}
+	nc := *c
+	if mc.AutoClip != nil {
+		nc.AutoClip = *mc.AutoClip
+	}
+	if mc.ClipTimeout != nil {
+		nc.ClipTimeout = *mc.ClipTimeout
+	}
+	if mc.Notifications != nil {
+		nc.Notifications = *mc.Notifications
+	}
+	if mc.Parsing != nil {
+		nc.Parsing = *mc.Parsing
+	}
+	if mc.SafeContent != nil {
+		nc.SafeContent = *mc.SafeContent
+	}
+	return &nc
+}
+
+// Keys returns the names of the options that are overridden
+func (mc *MountConfig) Keys() []string {
+	if mc == nil {
+		return nil
+	}
+	keys := make([]string, 0, 5)
+	if mc.AutoClip != nil {
+		keys = append(keys, "autoclip")
+	}
+	if mc.ClipTimeout != nil {
+		keys = append(keys, "cliptimeout")
+	}
+	if mc.Notifications != nil {
+		keys = append(keys, "notifications")
+	}
+	if mc.Parsing != nil {
+		keys = append(keys, "parsing")
+	}
+	if mc.SafeContent != nil {
+		keys = append(keys, "safecontent")
+	}
+	return keys
+}
+
+// MountConfigMap returns the effective values of all options that can be
+// overridden for the given mount point
+func (c *Config) MountConfigMap(alias string) map[string]string {
+	m := c.ForMount(alias).ConfigMap()
+	keys := MountConfigKeys()
+	out := make(map[string]string, len(keys))
+	for _, k := range keys {
+		out[k] = m[k]
+	}
+	return out
+}
+
+// SetMountConfigValue overrides an option for the given mount point. An empty
+// value removes the override.
+func (c *Config) SetMountConfigValue(alias, key, value string) error {
+	if _, found := c.Mounts[alias]; !found {
+		return fmt.Errorf("mount %q does not exist", alias)
+	}
+	if err := c.setMountConfigValue(alias, key, value); err != nil {
+		return err
+	}
+	return c.Save()
+}
+
+func (c *Config) setMountConfigValue(alias, key, value string) error {
+	mc := c.Overrides[alias]
+	if mc == nil {
+		mc = &MountConfig{}
+	}
+
+	value = strings.ToLower(value)
+	var bv *bool
+	if value != "" && key != "cliptimeout" {
+		switch value {
+		case "true", "on":
+			b := true
+			bv = &b
+		case "false", "off":
+			b := false
+			bv = &b
+		default:
+			return fmt.Errorf("not a bool: %s", value)
+		}
+	}
+
+	switch key {
+	case "autoclip":
+		mc.AutoClip = bv
+	case "cliptimeout":
+		mc.ClipTimeout = nil
+		if value != "" {
+			iv, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("failed to convert %q to integer: %w", value, err)
+			}
+			mc.ClipTimeout = &iv
+		}
+	case "notifications":
+		mc.Notifications = bv
+	case "parsing":
+		mc.Parsing = bv
+	case "safecontent":
+		mc.SafeContent = bv
+	default:
+		return fmt.Errorf("option %q can not be set per mount, use one of %v", key, MountConfigKeys())
+	}
+
+	if *mc == (MountConfig{}) {
+		delete(c.Overrides, alias)
+		return nil
+	}
+	if c.Overrides == nil {
+		c.Overrides = make(map[string]*MountConfig, 1)
+	}
+	c.Overrides[alias] = mc
+	return nil
+}
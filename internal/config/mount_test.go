@@ -0,0 +1,65 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMountConfig(t *testing.T) {
+	td := t.TempDir()
+	require.NoError(t, os.Setenv("GOPASS_CONFIG", filepath.Join(td, "config.yml")))
+	defer func() {
+		_ = os.Unsetenv("GOPASS_CONFIG")
+	}()
+
+	cfg := New()
+	cfg.SafeContent = false
+	cfg.Mounts["team"] = filepath.Join(td, "team")
+
+	assert.Error(t, cfg.SetMountConfigValue("other", "safecontent", "true"))
+	assert.Error(t, cfg.SetMountConfigValue("team", "path", "/tmp"))
+	assert.Error(t, cfg.SetMountConfigValue("team", "safecontent", "yo"))
+	assert.Error(t, cfg.SetMountConfigValue("team", "cliptimeout", "yo"))
+
+	require.NoError(t, cfg.SetMountConfigValue("team", "safecontent", "true"))
+	require.NoError(t, cfg.SetMountConfigValue("team", "cliptimeout", "10"))
+
+	tc := cfg.ForMount("team")
+	assert.True(t, tc.SafeContent)
+	assert.Equal(t, 10, tc.ClipTimeout)
+	assert.True(t, tc.Parsing)
+	assert.False(t, cfg.ForMount("").SafeContent)
+	assert.Equal(t, 45, cfg.ForMount("").ClipTimeout)
+	assert.Equal(t, map[string]string{
+		"autoclip":      "false",
+		"cliptimeout":   "10",
+		"notifications": "true",
+		"parsing":       "true",
+		"safecontent":   "true",
+	}, cfg.MountConfigMap("team"))
+
+	ctx := cfg.WithContext(context.Background())
+	assert.False(t, ctxutil.IsShowSafeContent(ctx))
+	assert.True(t, ctxutil.IsShowSafeContent(cfg.WithMountContext(ctx, "team")))
+	assert.False(t, ctxutil.IsShowSafeContent(cfg.WithMountContext(ctx, "")))
+
+	// overrides survive a reload
+	buf, err := os.ReadFile(filepath.Join(td, "config.yml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(buf), "overrides:\n    team:\n        cliptimeout: 10\n        safecontent: true\n")
+	lc, err := load(filepath.Join(td, "config.yml"), false)
+	require.NoError(t, err)
+	assert.Equal(t, 10, lc.ForMount("team").ClipTimeout)
+
+	// removing all overrides removes the mount entry
+	require.NoError(t, cfg.SetMountConfigValue("team", "safecontent", ""))
+	require.NoError(t, cfg.SetMountConfigValue("team", "cliptimeout", ""))
+	assert.Len(t, cfg.Overrides, 0)
+	assert.Equal(t, 45, cfg.ForMount("team").ClipTimeout)
+}
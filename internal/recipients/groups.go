@@ -0,0 +1,131 @@
+package recipients
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GroupPrefix marks an entry in a recipients file as a reference to a named
+// group, e.g. @sre
+const GroupPrefix = "@"
+
+// maxGroupDepth limits how deep groups may be nested to guard against cycles
+const maxGroupDepth = 10
+
+// Groups maps group names (without the prefix) to their members. Members can
+// be key IDs or references to other groups.
+type Groups map[string][]string
+
+// IsGroup returns true if the given recipient refers to a group
+func IsGroup(r string) bool {
+	return strings.HasPrefix(r, GroupPrefix) && len(r) > len(GroupPrefix)
+}
+
+// GroupName returns the group name of a group reference (i.e. strips the prefix)
+func GroupName(r string) string {
+	return strings.TrimPrefix(r, GroupPrefix)
+}
+
+// MarshalGroups serializes the group definitions to YAML. Members are
+// deduplicated and sorted to keep diffs stable.
+func MarshalGroups(g Groups) ([]byte, error) {
+	clean := make(Groups, len(g))
+	for name, members := range g {
+		if len(members) < 1 {
+			continue
+		}
+		clean[name] = Unmarshal(Marshal(members))
+	}
+	if len(clean) < 1 {
+		return []byte("\n"), nil
+	}
+	return yaml.Marshal(clean)
+}
+
+// UnmarshalGroups reads group definitions from YAML
+func UnmarshalGroups(buf []byte) (Groups, error) {
+	g := make(Groups, 5)
+	if err := yaml.Unmarshal(buf, &g); err != nil {
+		return nil, fmt.Errorf("failed to parse recipient groups: %w", err)
+	}
+	for name, members := range g {
+		sort.Strings(members)
+		g[name] = members
+	}
+	return g, nil
+}
+
+// Names returns the sorted list of all group names
+func (g Groups) Names() []string {
+	names := make([]string, 0, len(g))
+	for name := range g {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Expand replaces all group references in the given recipient list by the
+// group members. Nested groups are resolved recursively. The result is
+// deduplicated and sorted. Referencing an unknown group is an error since
+// silently dropping it would leave the members unable to decrypt.
+func (g Groups) Expand(rs []string) ([]string, error) {
+	m := make(map[string]struct{}, len(rs))
+	if err := g.expand(m, rs, 0); err != nil {
+		return nil, err
+	}
+
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func (g Groups) expand(m map[string]struct{}, rs []string, depth int) error {
+	if depth > maxGroupDepth {
+		return fmt.Errorf("recipient groups nested too deep (cycle?)")
+	}
+	for _, r := range rs {
+		if !IsGroup(r) {
+			m[r] = struct{}{}
+			continue
+		}
+		members, found := g[GroupName(r)]
+		if !found {
+			return fmt.Errorf("unknown recipient group %q", r)
+		}
+		if err := g.expand(m, members, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// References returns true if the given recipient list references the group
+// name, either directly or through nested groups.
+func (g Groups) References(rs []string, name string) bool {
+	return g.references(rs, name, 0)
+}
+
+func (g Groups) references(rs []string, name string, depth int) bool {
+	if depth > maxGroupDepth {
+		return false
+	}
+	for _, r := range rs {
+		if !IsGroup(r) {
+			continue
+		}
+		if GroupName(r) == name {
+			return true
+		}
+		if g.references(g[GroupName(r)], name, depth+1) {
+			return true
+		}
+	}
+	return false
+}
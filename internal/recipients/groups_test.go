@@ -0,0 +1,51 @@
+package recipients
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupsMarshal(t *testing.T) {
+	g := Groups{
+		"sre":   {"0xFEEDBEEF", "0xDEADBEEF", "0xDEADBEEF"},
+		"empty": {},
+	}
+	buf, err := MarshalGroups(g)
+	require.NoError(t, err)
+	assert.Equal(t, "sre:\n    - \"0xDEADBEEF\"\n    - \"0xFEEDBEEF\"\n", string(buf))
+
+	g2, err := UnmarshalGroups(buf)
+	require.NoError(t, err)
+	assert.Equal(t, Groups{"sre": {"0xDEADBEEF", "0xFEEDBEEF"}}, g2)
+
+	g2, err = UnmarshalGroups([]byte("sre:\n  - 0xFEEDBEEF\n  - 0xDEADBEEF\n"))
+	require.NoError(t, err)
+	assert.Equal(t, Groups{"sre": {"0xDEADBEEF", "0xFEEDBEEF"}}, g2)
+
+	_, err = UnmarshalGroups([]byte("foo: [bar"))
+	assert.Error(t, err)
+}
+
+func TestGroupsExpand(t *testing.T) {
+	g := Groups{
+		"sre": {"0xDEADBEEF", "0xFEEDBEEF"},
+		"ops": {"@sre", "0xBEEFFEED"},
+	}
+
+	rs, err := g.Expand([]string{"@ops", "0xDEADBEEF", "john.doe"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0xBEEFFEED", "0xDEADBEEF", "0xFEEDBEEF", "john.doe"}, rs)
+
+	_, err = g.Expand([]string{"@dev"})
+	assert.Error(t, err)
+
+	g["loop"] = []string{"@loop"}
+	_, err = g.Expand([]string{"@loop"})
+	assert.Error(t, err)
+
+	assert.True(t, g.References([]string{"@ops"}, "sre"))
+	assert.False(t, g.References([]string{"@sre", "0xDEADBEEF"}, "ops"))
+	assert.Equal(t, []string{"loop", "ops", "sre"}, g.Names())
+}
@@ -72,3 +72,14 @@ func (s *Store) Overdue(key string) bool {
 	s.Reset("overdue")
 	return time.Since(s.lastSeen(key)) > 90*24*time.Hour
 }
+
+// OverdueAfter returns true iff the key wasn't updated within the given
+// interval. Unlike Overdue this is not rate limited to once per day, so
+// callers should Reset the key once they have acted on it.
+func (s *Store) OverdueAfter(key string, interval time.Duration) bool {
+	if s == nil {
+		return false
+	}
+
+	return time.Since(s.lastSeen(key)) > interval
+}
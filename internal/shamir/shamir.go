@@ -0,0 +1,158 @@
+// Package shamir implements Shamir's secret sharing over GF(2^8).
+//
+// Each share is the x coordinate (one byte, never zero) followed by the
+// evaluation of one random polynomial per secret byte at that x coordinate.
+package shamir
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// MaxShares is the maximum number of shares, limited by the field size
+const MaxShares = 255
+
+var (
+	expTable [512]byte
+	logTable [256]byte
+)
+
+func init() {
+	// 0x03 is a generator of the multiplicative group of GF(2^8) with the
+	// AES reduction polynomial x^8 + x^4 + x^3 + x + 1 (0x11b)
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		expTable[i] = x
+		expTable[i+255] = x
+		logTable[x] = byte(i)
+		x = mulNoTable(x, 0x03)
+	}
+}
+
+func mulNoTable(a, b byte) byte {
+	var p byte
+	for b > 0 {
+		if b&1 == 1 {
+			p ^= a
+		}
+		hi := a & 0x80
+		a <<= 1
+		if hi != 0 {
+			a ^= 0x1b
+		}
+		b >>= 1
+	}
+	return p
+}
+
+func mul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return expTable[int(logTable[a])+int(logTable[b])]
+}
+
+func div(a, b byte) byte {
+	if b == 0 {
+		panic("shamir: division by zero")
+	}
+	if a == 0 {
+		return 0
+	}
+	return expTable[int(logTable[a])+255-int(logTable[b])]
+}
+
+// eval evaluates the polynomial with the given coefficients at x
+func eval(coeffs []byte, x byte) byte {
+	// Horner's method, addition is XOR in GF(2^8)
+	var y byte
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		y = mul(y, x) ^ coeffs[i]
+	}
+	return y
+}
+
+// Split splits the secret into n shares, any threshold of them are required
+// to reconstruct the secret.
+func Split(secret []byte, n, threshold int) ([][]byte, error) {
+	if len(secret) < 1 {
+		return nil, fmt.Errorf("can not split an empty secret")
+	}
+	if n < 2 || n > MaxShares {
+		return nil, fmt.Errorf("number of shares must be between 2 and %d", MaxShares)
+	}
+	if threshold < 2 || threshold > n {
+		return nil, fmt.Errorf("threshold must be between 2 and the number of shares (%d)", n)
+	}
+
+	shares := make([][]byte, n)
+	for i := range shares {
+		shares[i] = make([]byte, len(secret)+1)
+		shares[i][0] = byte(i + 1)
+	}
+
+	coeffs := make([]byte, threshold)
+	for j, b := range secret {
+		coeffs[0] = b
+		if _, err := rand.Read(coeffs[1:]); err != nil {
+			return nil, fmt.Errorf("failed to read random coefficients: %w", err)
+		}
+		for i := range shares {
+			shares[i][j+1] = eval(coeffs, shares[i][0])
+		}
+	}
+	// don't leave the secret around in memory longer than necessary
+	for i := range coeffs {
+		coeffs[i] = 0
+	}
+
+	return shares, nil
+}
+
+// Combine reconstructs the secret from the given shares. It can not detect
+// if too few shares are given, the result will just be garbage in that case.
+func Combine(shares [][]byte) ([]byte, error) {
+	if len(shares) < 2 {
+		return nil, fmt.Errorf("need at least two shares")
+	}
+	l := len(shares[0])
+	if l < 2 {
+		return nil, fmt.Errorf("invalid share length")
+	}
+
+	xs := make([]byte, len(shares))
+	seen := make(map[byte]bool, len(shares))
+	for i, s := range shares {
+		if len(s) != l {
+			return nil, fmt.Errorf("shares have different lengths")
+		}
+		if s[0] == 0 {
+			return nil, fmt.Errorf("invalid share index 0")
+		}
+		if seen[s[0]] {
+			return nil, fmt.Errorf("duplicate share %d", s[0])
+		}
+		seen[s[0]] = true
+		xs[i] = s[0]
+	}
+
+	secret := make([]byte, l-1)
+	for j := range secret {
+		// Lagrange interpolation at x = 0
+		var y byte
+		for i := range shares {
+			basis := byte(1)
+			for k := range shares {
+				if k == i {
+					continue
+				}
+				// x_k / (x_k - x_i), subtraction is XOR
+				basis = mul(basis, div(xs[k], xs[k]^xs[i]))
+			}
+			y ^= mul(shares[i][j+1], basis)
+		}
+		secret[j] = y
+	}
+
+	return secret, nil
+}
@@ -0,0 +1,69 @@
+package shamir
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestField(t *testing.T) {
+	for a := 1; a < 256; a++ {
+		for b := 1; b < 256; b++ {
+			p := mul(byte(a), byte(b))
+			assert.Equal(t, mulNoTable(byte(a), byte(b)), p)
+			assert.Equal(t, byte(a), div(p, byte(b)))
+		}
+	}
+}
+
+func TestSplitCombine(t *testing.T) {
+	secret := []byte("correct horse battery staple")
+
+	shares, err := Split(secret, 5, 3)
+	require.NoError(t, err)
+	require.Len(t, shares, 5)
+
+	for _, sel := range [][]int{
+		{0, 1, 2},
+		{4, 2, 0},
+		{1, 3, 4},
+		{0, 1, 2, 3, 4},
+	} {
+		subset := make([][]byte, 0, len(sel))
+		for _, i := range sel {
+			subset = append(subset, shares[i])
+		}
+		got, err := Combine(subset)
+		require.NoError(t, err)
+		assert.Equal(t, secret, got, "shares %v", sel)
+	}
+
+	// too few shares yield garbage
+	got, err := Combine(shares[:2])
+	require.NoError(t, err)
+	assert.NotEqual(t, secret, got)
+}
+
+func TestErrors(t *testing.T) {
+	_, err := Split(nil, 3, 2)
+	assert.Error(t, err)
+	_, err = Split([]byte("foo"), 1, 1)
+	assert.Error(t, err)
+	_, err = Split([]byte("foo"), 3, 4)
+	assert.Error(t, err)
+	_, err = Split([]byte("foo"), 256, 2)
+	assert.Error(t, err)
+
+	shares, err := Split([]byte("foo"), 3, 2)
+	require.NoError(t, err)
+
+	_, err = Combine(shares[:1])
+	assert.Error(t, err)
+	_, err = Combine([][]byte{shares[0], shares[0]})
+	assert.Error(t, err)
+	_, err = Combine([][]byte{shares[0], shares[1][:2]})
+	assert.Error(t, err)
+	_, err = Combine([][]byte{{0, 1}, {1, 1}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,295 @@
+// Package sshagent implements an ssh-agent that serves private keys stored
+// in gopass. Keys are decrypted on demand for every operation and never
+// written to disk.
+package sshagent
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/tree"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/gopass"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+var (
+	// ErrLocked is returned while the agent is locked
+	ErrLocked = errors.New("agent is locked")
+	// ErrKeyNotFound is returned if a key is not served by this agent
+	ErrKeyNotFound = errors.New("key not found")
+	// ErrDenied is returned if the user did not confirm the use of a key
+	ErrDenied = errors.New("use of key denied")
+	// ErrReadOnly is returned when a client tries to add keys
+	ErrReadOnly = errors.New("adding keys is not supported, insert them into the store instead")
+)
+
+var _ agent.ExtendedAgent = (*Agent)(nil)
+
+type secretStore interface {
+	List(context.Context, int) ([]string, error)
+	Get(context.Context, string) (gopass.Secret, error)
+}
+
+// Options configure the agent
+type Options struct {
+	// Prefix is the subtree holding the private keys
+	Prefix string
+	// Confirm is called before every use of a key if set. The key is only
+	// used if it returns true.
+	Confirm func(ctx context.Context, name string) bool
+	// Lifetime limits how long a key is served after it was loaded for the
+	// first time. Zero means no limit.
+	Lifetime time.Duration
+}
+
+// Agent is an ssh-agent backed by a password store
+type Agent struct {
+	ctx   context.Context
+	store secretStore
+	opts  Options
+
+	mu         sync.Mutex
+	passphrase []byte
+	// keys maps the public key blob to the secret name
+	keys map[string]string
+	// loaded records when a key was first loaded to enforce its lifetime
+	loaded  map[string]time.Time
+	removed map[string]bool
+	now     func() time.Time
+}
+
+// New creates a new agent
+func New(ctx context.Context, store secretStore, opts Options) *Agent {
+	opts.Prefix = strings.TrimSuffix(opts.Prefix, "/")
+	return &Agent{
+		ctx:     ctx,
+		store:   store,
+		opts:    opts,
+		keys:    map[string]string{},
+		loaded:  map[string]time.Time{},
+		removed: map[string]bool{},
+		now:     time.Now,
+	}
+}
+
+// List returns the public keys of all private keys below the prefix
+func (a *Agent) List() ([]*agent.Key, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.passphrase != nil {
+		return nil, nil
+	}
+
+	names, err := a.store.List(a.ctx, tree.INF)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list store: %w", err)
+	}
+
+	keys := make([]*agent.Key, 0, len(names))
+	for _, name := range names {
+		if a.opts.Prefix != "" && !strings.HasPrefix(name, a.opts.Prefix+"/") {
+			continue
+		}
+		signer, err := a.load(name)
+		if err != nil {
+			debug.Log("skipping %s: %s", name, err)
+			continue
+		}
+		pub := signer.PublicKey()
+		blob := string(pub.Marshal())
+		if a.removed[blob] || a.expired(blob) {
+			continue
+		}
+		a.keys[blob] = name
+		keys = append(keys, &agent.Key{
+			Format:  pub.Type(),
+			Blob:    pub.Marshal(),
+			Comment: name,
+		})
+	}
+	return keys, nil
+}
+
+// Sign signs data with the given key
+func (a *Agent) Sign(key ssh.PublicKey, data []byte) (*ssh.Signature, error) {
+	return a.SignWithFlags(key, data, 0)
+}
+
+// SignWithFlags signs data with the given key. The flags select the
+// signature algorithm for RSA keys.
+func (a *Agent) SignWithFlags(key ssh.PublicKey, data []byte, flags agent.SignatureFlags) (*ssh.Signature, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.passphrase != nil {
+		return nil, ErrLocked
+	}
+
+	blob := string(key.Marshal())
+	name, found := a.keys[blob]
+	if !found || a.removed[blob] || a.expired(blob) {
+		return nil, ErrKeyNotFound
+	}
+
+	if a.opts.Confirm != nil && !a.opts.Confirm(a.ctx, name) {
+		return nil, ErrDenied
+	}
+
+	signer, err := a.load(name)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(signer.PublicKey().Marshal(), key.Marshal()) {
+		// the secret was changed since it was listed
+		delete(a.keys, blob)
+		return nil, ErrKeyNotFound
+	}
+	debug.Log("signing with %s", name)
+
+	algo := ""
+	switch {
+	case flags&agent.SignatureFlagRsaSha256 != 0:
+		algo = ssh.SigAlgoRSASHA2256
+	case flags&agent.SignatureFlagRsaSha512 != 0:
+		algo = ssh.SigAlgoRSASHA2512
+	}
+	if as, ok := signer.(ssh.AlgorithmSigner); ok && algo != "" && key.Type() == ssh.KeyAlgoRSA {
+		return as.SignWithAlgorithm(nil, data, algo)
+	}
+	return signer.Sign(nil, data)
+}
+
+// Add is not supported, the agent only serves keys from the store
+func (a *Agent) Add(key agent.AddedKey) error {
+	return ErrReadOnly
+}
+
+// Remove stops serving the given key until the agent is restarted
+func (a *Agent) Remove(key ssh.PublicKey) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.passphrase != nil {
+		return ErrLocked
+	}
+	blob := string(key.Marshal())
+	if _, found := a.keys[blob]; !found {
+		return ErrKeyNotFound
+	}
+	a.removed[blob] = true
+	return nil
+}
+
+// RemoveAll stops serving all currently known keys until the agent is
+// restarted
+func (a *Agent) RemoveAll() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.passphrase != nil {
+		return ErrLocked
+	}
+	for blob := range a.keys {
+		a.removed[blob] = true
+	}
+	return nil
+}
+
+// Lock locks the agent. No keys are listed or used until it's unlocked with
+// the same passphrase.
+func (a *Agent) Lock(passphrase []byte) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.passphrase != nil {
+		return ErrLocked
+	}
+	a.passphrase = append([]byte{}, passphrase...)
+	return nil
+}
+
+// Unlock unlocks the agent
+func (a *Agent) Unlock(passphrase []byte) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.passphrase == nil {
+		return errors.New("agent is not locked")
+	}
+	if subtle.ConstantTimeCompare(a.passphrase, passphrase) != 1 {
+		return errors.New("incorrect passphrase")
+	}
+	a.passphrase = nil
+	return nil
+}
+
+// Signers is not supported since it would hand out the private keys without
+// confirmation
+func (a *Agent) Signers() ([]ssh.Signer, error) {
+	return nil, errors.New("not supported")
+}
+
+// Extension is not supported
+func (a *Agent) Extension(extensionType string, contents []byte) ([]byte, error) {
+	return nil, agent.ErrExtensionUnsupported
+}
+
+// expired returns true if the lifetime of the key is over. It records the
+// time a key is seen for the first time. Must be called with the lock held.
+func (a *Agent) expired(blob string) bool {
+	if a.opts.Lifetime <= 0 {
+		return false
+	}
+	ts, found := a.loaded[blob]
+	if !found {
+		a.loaded[blob] = a.now()
+		return false
+	}
+	return a.now().Sub(ts) > a.opts.Lifetime
+}
+
+// load decrypts the secret and parses the private key
+func (a *Agent) load(name string) (ssh.Signer, error) {
+	sec, err := a.store.Get(a.ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return ParseKey(sec)
+}
+
+// ParseKey extracts a PEM encoded private key from a secret. Encrypted keys
+// are decrypted with the value of the passphrase key of the secret.
+func ParseKey(sec gopass.Secret) (ssh.Signer, error) {
+	buf := sec.Bytes()
+	start := bytes.Index(buf, []byte("-----BEGIN "))
+	if start < 0 {
+		return nil, errors.New("no private key found")
+	}
+	buf = buf[start:]
+	if end := bytes.Index(buf, []byte("-----END ")); end >= 0 {
+		if eol := bytes.Index(buf[end+5:], []byte("-----")); eol >= 0 {
+			buf = buf[:end+5+eol+5]
+		}
+	}
+
+	var key interface{}
+	var err error
+	if pw, found := sec.Get("passphrase"); found {
+		key, err = ssh.ParseRawPrivateKeyWithPassphrase(buf, []byte(pw))
+	} else {
+		key, err = ssh.ParseRawPrivateKey(buf)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	return ssh.NewSignerFromKey(key)
+}
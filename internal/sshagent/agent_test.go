@@ -0,0 +1,174 @@
+package sshagent
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/gopasspw/gopass/pkg/gopass"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets/secparse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+type fakeStore map[string][]byte
+
+func (f fakeStore) List(context.Context, int) ([]string, error) {
+	names := make([]string, 0, len(f))
+	for k := range f {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (f fakeStore) Get(_ context.Context, name string) (gopass.Secret, error) {
+	buf, found := f[name]
+	if !found {
+		return nil, fmt.Errorf("not found")
+	}
+	return secparse.Parse(buf)
+}
+
+func genKey(t *testing.T) (ssh.PublicKey, []byte) {
+	t.Helper()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	sshPub, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+	return sshPub, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func serve(ctx context.Context, t *testing.T, a *Agent) agent.ExtendedAgent {
+	t.Helper()
+
+	socket := filepath.Join(t.TempDir(), "agent.sock")
+	done := make(chan error, 1)
+	go func() {
+		done <- a.Serve(ctx, socket)
+	}()
+	t.Cleanup(func() {
+		assert.NoError(t, <-done)
+	})
+
+	var conn net.Conn
+	require.Eventually(t, func() bool {
+		var err error
+		conn, err = net.Dial("unix", socket)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return agent.NewClient(conn)
+}
+
+func TestAgent(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pub, key := genKey(t)
+	store := fakeStore{
+		"ssh/github": key,
+		"ssh/broken": []byte("not a key"),
+		"web/github": []byte("password"),
+	}
+
+	client := serve(ctx, t, New(ctx, store, Options{Prefix: "ssh/"}))
+
+	keys, err := client.List()
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "ssh/github", keys[0].Comment)
+	assert.Equal(t, pub.Marshal(), keys[0].Blob)
+
+	data := []byte("challenge")
+	sig, err := client.Sign(pub, data)
+	require.NoError(t, err)
+	assert.NoError(t, pub.Verify(data, sig))
+
+	// read-only
+	assert.Error(t, client.Add(agent.AddedKey{PrivateKey: ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))}))
+
+	// lock
+	require.NoError(t, client.Lock([]byte("foo")))
+	keys, err = client.List()
+	require.NoError(t, err)
+	assert.Len(t, keys, 0)
+	_, err = client.Sign(pub, data)
+	assert.Error(t, err)
+	assert.Error(t, client.Unlock([]byte("bar")))
+	require.NoError(t, client.Unlock([]byte("foo")))
+
+	// remove
+	require.NoError(t, client.Remove(pub))
+	keys, err = client.List()
+	require.NoError(t, err)
+	assert.Len(t, keys, 0)
+	_, err = client.Sign(pub, data)
+	assert.Error(t, err)
+}
+
+func TestAgentConfirm(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pub, key := genKey(t)
+	store := fakeStore{"ssh/id": key}
+
+	var asked []string
+	allow := false
+	a := New(ctx, store, Options{
+		Confirm: func(_ context.Context, name string) bool {
+			asked = append(asked, name)
+			return allow
+		},
+	})
+	client := serve(ctx, t, a)
+
+	_, err := client.List()
+	require.NoError(t, err)
+
+	_, err = client.Sign(pub, []byte("data"))
+	assert.Error(t, err)
+
+	allow = true
+	_, err = client.Sign(pub, []byte("data"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ssh/id", "ssh/id"}, asked)
+}
+
+func TestAgentLifetime(t *testing.T) {
+	ctx := context.Background()
+
+	pub, key := genKey(t)
+	a := New(ctx, fakeStore{"ssh/id": key}, Options{Prefix: "ssh", Lifetime: time.Minute})
+	now := time.Now()
+	a.now = func() time.Time { return now }
+
+	keys, err := a.List()
+	require.NoError(t, err)
+	assert.Len(t, keys, 1)
+	_, err = a.Sign(pub, []byte("data"))
+	assert.NoError(t, err)
+
+	now = now.Add(2 * time.Minute)
+	keys, err = a.List()
+	require.NoError(t, err)
+	assert.Len(t, keys, 0)
+	_, err = a.Sign(pub, []byte("data"))
+	assert.Error(t, err)
+}
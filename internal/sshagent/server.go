@@ -0,0 +1,66 @@
+package sshagent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/gopasspw/gopass/pkg/debug"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// Serve listens on the given unix socket and serves the agent until the
+// context is canceled. The socket is only accessible by the current user and
+// removed on exit.
+func (a *Agent) Serve(ctx context.Context, socket string) error {
+	if fi, err := os.Lstat(socket); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return fmt.Errorf("%s exists and is not a socket", socket)
+		}
+		// remove stale sockets, but don't hijack a running agent
+		if c, err := net.Dial("unix", socket); err == nil {
+			_ = c.Close()
+			return fmt.Errorf("%s is in use", socket)
+		}
+		if err := os.Remove(socket); err != nil {
+			return fmt.Errorf("failed to remove stale socket %s: %w", socket, err)
+		}
+	}
+
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", socket, err)
+	}
+	defer func() {
+		_ = l.Close()
+		_ = os.Remove(socket)
+	}()
+	if err := os.Chmod(socket, 0600); err != nil {
+		return fmt.Errorf("failed to set permissions of %s: %w", socket, err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = l.Close()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return fmt.Errorf("failed to accept connection: %w", err)
+		}
+		go func() {
+			defer func() {
+				_ = conn.Close()
+			}()
+			if err := agent.ServeAgent(a, conn); err != nil && !errors.Is(err, net.ErrClosed) {
+				debug.Log("agent connection closed: %s", err)
+			}
+		}()
+	}
+}
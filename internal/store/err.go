@@ -33,4 +33,6 @@ var (
 	ErrNoKey = fmt.Errorf("key not found in entry")
 	// ErrYAMLValueUnsupported is returned is the user tries to unmarshal an nested struct
 	ErrYAMLValueUnsupported = fmt.Errorf("can not unmarshal nested YAML value")
+	// ErrInvalidSignature is returned if a recipients file is not signed by a trusted recipient
+	ErrInvalidSignature = fmt.Errorf("recipients file signature is invalid")
 )
@@ -0,0 +1,260 @@
+package leaf
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/appdir"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+const (
+	// AccessLogLocal keeps the access log in the local data directory
+	AccessLogLocal = "local"
+	// AccessLogStore keeps the access log in the store (and its repository)
+	AccessLogStore = "store"
+
+	accessLogDir = ".access-log"
+	accessLogExt = ".log"
+)
+
+var reAccessLogName = regexp.MustCompile(`[^-._@a-zA-Z0-9]`)
+
+// ErrAccessLogTampered is returned if the hash chain of an access log is broken
+var ErrAccessLogTampered = errors.New("access log has been tampered with")
+
+// AccessEntry is a single entry of the access log. Every entry contains the
+// hash of the previous (encrypted) entry so removing or modifying entries
+// breaks the chain.
+type AccessEntry struct {
+	Seq    int       `json:"seq"`
+	Time   time.Time `json:"time"`
+	User   string    `json:"user"`
+	Host   string    `json:"host"`
+	Action string    `json:"action"`
+	Name   string    `json:"name"`
+	Prev   string    `json:"prev"`
+}
+
+// LogAccess appends an entry to the access log. The entry is encrypted for
+// the recipients of the store. In store mode the entry is committed but never
+// pushed, so reads don't depend on the remote. It's pushed with the next write
+// or sync.
+func (s *Store) LogAccess(ctx context.Context, mode, action, name string) error {
+	fn, err := s.accessLogFile(mode)
+	if err != nil {
+		return err
+	}
+
+	buf, err := s.readAccessLog(ctx, mode, fn)
+	if err != nil {
+		return err
+	}
+
+	e := AccessEntry{
+		Time:   time.Now().UTC(),
+		Action: action,
+		Name:   name,
+	}
+	e.User, e.Host = accessIdentity()
+	// the sequence number is the position in the log, AccessLog verifies
+	// that, so the previous entry doesn't need to be decrypted
+	if lines := accessLogLines(buf); len(lines) > 0 {
+		e.Prev = accessLogHash(lines[len(lines)-1])
+		e.Seq = len(lines)
+	}
+
+	plain, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	recipients := s.ensureOurKeyID(ctx, s.Recipients(ctx))
+	ciphertext, err := s.crypto.Encrypt(ctx, plain, recipients)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt access log entry: %w", err)
+	}
+	line := base64.StdEncoding.EncodeToString(ciphertext) + "\n"
+
+	debug.Log("logging access to %s (%s) in %s", name, action, fn)
+	if mode == AccessLogLocal {
+		fh, err := os.OpenFile(fn, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to open access log: %w", err)
+		}
+		if _, err := fh.WriteString(line); err != nil {
+			_ = fh.Close()
+			return fmt.Errorf("failed to write access log: %w", err)
+		}
+		return fh.Close()
+	}
+
+	if err := s.storage.Set(ctx, fn, append(buf, line...)); err != nil {
+		return fmt.Errorf("failed to write access log: %w", err)
+	}
+	if err := s.storage.Add(ctx, fn); err != nil {
+		if errors.Is(err, store.ErrGitNotInit) {
+			return nil
+		}
+		return fmt.Errorf("failed to add access log to git: %w", err)
+	}
+	if err := s.storage.Commit(ctx, fmt.Sprintf("Access log: %s %s", action, name)); err != nil {
+		if errors.Is(err, store.ErrGitNotInit) || errors.Is(err, store.ErrGitNothingToCommit) {
+			return nil
+		}
+		return fmt.Errorf("failed to commit access log: %w", err)
+	}
+	return nil
+}
+
+// AccessLog reads, decrypts and verifies the access log. In store mode the
+// logs of all users and hosts are read. The entries of each log are returned
+// even if its chain is broken, in that case ErrAccessLogTampered is returned
+// as well.
+func (s *Store) AccessLog(ctx context.Context, mode string) ([]AccessEntry, error) {
+	var files []string
+	switch mode {
+	case AccessLogLocal:
+		fn, err := s.accessLogFile(mode)
+		if err != nil {
+			return nil, err
+		}
+		files = []string{fn}
+	case AccessLogStore:
+		// storage backends don't list hidden folders
+		des, err := os.ReadDir(filepath.Join(s.path, accessLogDir))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to list access logs: %w", err)
+		}
+		for _, de := range des {
+			if !de.IsDir() && strings.HasSuffix(de.Name(), accessLogExt) {
+				files = append(files, accessLogDir+"/"+de.Name())
+			}
+		}
+	default:
+		return nil, fmt.Errorf("access log is disabled")
+	}
+
+	var entries []AccessEntry
+	var tampered []string
+	for _, fn := range files {
+		buf, err := s.readAccessLog(ctx, mode, fn)
+		if err != nil {
+			return nil, err
+		}
+		prev := ""
+		for i, line := range accessLogLines(buf) {
+			e, err := s.decryptAccessEntry(ctx, line)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt entry %d of %s: %w", i+1, fn, err)
+			}
+			if e.Prev != prev || e.Seq != i {
+				tampered = append(tampered, fmt.Sprintf("%s: entry %d", filepath.Base(fn), i+1))
+			}
+			prev = accessLogHash(line)
+			entries = append(entries, e)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.Before(entries[j].Time)
+	})
+	if len(tampered) > 0 {
+		return entries, fmt.Errorf("%w: %s", ErrAccessLogTampered, strings.Join(tampered, ", "))
+	}
+	return entries, nil
+}
+
+// accessLogFile returns the location of the log of this user and host. In
+// store mode it's relative to the store, otherwise an absolute path.
+func (s *Store) accessLogFile(mode string) (string, error) {
+	switch mode {
+	case AccessLogStore:
+		u, h := accessIdentity()
+		return accessLogDir + "/" + reAccessLogName.ReplaceAllString(u+"@"+h, "_") + accessLogExt, nil
+	case AccessLogLocal:
+		dir := filepath.Join(appdir.UserData(), "access-log")
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return "", fmt.Errorf("failed to create access log dir: %w", err)
+		}
+		name := "root"
+		if s.alias != "" {
+			name = "mount-" + reAccessLogName.ReplaceAllString(s.alias, "_")
+		}
+		return filepath.Join(dir, name+accessLogExt), nil
+	default:
+		return "", fmt.Errorf("unknown access log location %q, use %q or %q", mode, AccessLogLocal, AccessLogStore)
+	}
+}
+
+func (s *Store) readAccessLog(ctx context.Context, mode, fn string) ([]byte, error) {
+	if mode == AccessLogLocal {
+		buf, err := os.ReadFile(fn)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read access log: %w", err)
+		}
+		return buf, nil
+	}
+	if !s.storage.Exists(ctx, fn) {
+		return nil, nil
+	}
+	buf, err := s.storage.Get(ctx, fn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read access log: %w", err)
+	}
+	return buf, nil
+}
+
+func (s *Store) decryptAccessEntry(ctx context.Context, line []byte) (AccessEntry, error) {
+	var e AccessEntry
+	ciphertext, err := base64.StdEncoding.DecodeString(string(line))
+	if err != nil {
+		return e, err
+	}
+	plain, err := s.crypto.Decrypt(ctx, ciphertext)
+	if err != nil {
+		return e, err
+	}
+	err = json.Unmarshal(plain, &e)
+	return e, err
+}
+
+func accessLogLines(buf []byte) [][]byte {
+	var lines [][]byte
+	for _, line := range bytes.Split(buf, []byte("\n")) {
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func accessLogHash(line []byte) string {
+	sum := sha256.Sum256(line)
+	return hex.EncodeToString(sum[:])
+}
+
+func accessIdentity() (string, string) {
+	name := "unknown"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return name, host
+}
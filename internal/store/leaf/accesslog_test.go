@@ -0,0 +1,67 @@
+package leaf
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopasspw/gopass/pkg/appdir"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessLog(t *testing.T) {
+	ctx := context.Background()
+
+	for _, mode := range []string{AccessLogLocal, AccessLogStore} {
+		mode := mode
+		t.Run(mode, func(t *testing.T) {
+			tempdir := t.TempDir()
+
+			s, err := createSubStore(tempdir)
+			require.NoError(t, err)
+
+			entries, err := s.AccessLog(ctx, mode)
+			require.NoError(t, err)
+			assert.Len(t, entries, 0)
+
+			require.NoError(t, s.LogAccess(ctx, mode, "show", "foo/bar"))
+			require.NoError(t, s.LogAccess(ctx, mode, "clip", "baz"))
+			require.NoError(t, s.LogAccess(ctx, mode, "api", "foo/bar"))
+
+			entries, err = s.AccessLog(ctx, mode)
+			require.NoError(t, err)
+			require.Len(t, entries, 3)
+			for i, e := range entries {
+				assert.Equal(t, i, e.Seq)
+			}
+			assert.Equal(t, "show", entries[0].Action)
+			assert.Equal(t, "baz", entries[1].Name)
+			assert.NotEmpty(t, entries[2].Prev)
+
+			// removing an entry must break the chain
+			fn := filepath.Join(appdir.UserData(), "access-log", "root.log")
+			if mode == AccessLogStore {
+				fn, err = s.accessLogFile(mode)
+				require.NoError(t, err)
+				fn = filepath.Join(s.path, fn)
+			}
+			buf, err := os.ReadFile(fn)
+			require.NoError(t, err)
+			lines := bytes.SplitAfter(buf, []byte("\n"))
+			require.NoError(t, os.WriteFile(fn, append(lines[0], lines[2]...), 0600))
+
+			entries, err = s.AccessLog(ctx, mode)
+			assert.ErrorIs(t, err, ErrAccessLogTampered)
+			assert.Len(t, entries, 2)
+		})
+	}
+
+	s, err := createSubStore(t.TempDir())
+	require.NoError(t, err)
+	assert.Error(t, s.LogAccess(ctx, "foo", "show", "foo/bar"))
+	_, err = s.AccessLog(ctx, "")
+	assert.Error(t, err)
+}
@@ -2,6 +2,7 @@ package leaf
 
 import (
 	"context"
+	"time"
 
 	"github.com/gopasspw/gopass/internal/store"
 )
@@ -15,8 +16,14 @@ const (
 	ctxKeyCheckRecipients
 	ctxKeyFsckDecrypt
 	ctxKeyNoGitOps
+	ctxKeyKeyExpiryWarning
+	ctxKeyPolicyOverride
 )
 
+// DefaultKeyExpiryWarning is the default period before a recipient's key
+// expires in which we start to warn about it
+const DefaultKeyExpiryWarning = 30 * 24 * time.Hour
+
 // WithFsckCheck returns a context with the flag for fscks check set
 func WithFsckCheck(ctx context.Context, check bool) context.Context {
 	return context.WithValue(ctx, ctxKeyFsckCheck, check)
@@ -127,6 +134,33 @@ func IsNoGitOps(ctx context.Context) bool {
 	return is(ctx, ctxKeyNoGitOps, false)
 }
 
+// WithKeyExpiryWarning returns a context with the period set in which
+// fsck will warn about expiring recipient keys.
+func WithKeyExpiryWarning(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, ctxKeyKeyExpiryWarning, d)
+}
+
+// GetKeyExpiryWarning returns the period in which fsck will warn about
+// expiring recipient keys or the default (30 days).
+func GetKeyExpiryWarning(ctx context.Context) time.Duration {
+	d, ok := ctx.Value(ctxKeyKeyExpiryWarning).(time.Duration)
+	if !ok || d < 0 {
+		return DefaultKeyExpiryWarning
+	}
+	return d
+}
+
+// WithPolicyOverride returns a context with the flag for overriding password
+// policy violations set. It only has an effect if the policy allows it.
+func WithPolicyOverride(ctx context.Context, override bool) context.Context {
+	return context.WithValue(ctx, ctxKeyPolicyOverride, override)
+}
+
+// IsPolicyOverride returns the value of policy override or the default (false)
+func IsPolicyOverride(ctx context.Context) bool {
+	return is(ctx, ctxKeyPolicyOverride, false)
+}
+
 // hasBool is a helper function for checking if a bool has been set in
 // the provided context.
 func hasBool(ctx context.Context, key contextKey) bool {
@@ -3,6 +3,7 @@ package leaf
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -45,3 +46,10 @@ func TestCheckRecipients(t *testing.T) {
 	assert.False(t, IsCheckRecipients(WithCheckRecipients(ctx, false)))
 	assert.True(t, HasCheckRecipients(WithCheckRecipients(ctx, true)))
 }
+
+func TestKeyExpiryWarning(t *testing.T) {
+	ctx := context.Background()
+
+	assert.Equal(t, DefaultKeyExpiryWarning, GetKeyExpiryWarning(ctx))
+	assert.Equal(t, time.Hour, GetKeyExpiryWarning(WithKeyExpiryWarning(ctx, time.Hour)))
+}
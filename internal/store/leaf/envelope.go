@@ -0,0 +1,393 @@
+package leaf
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// Envelope encryption encrypts secrets with a symmetric data key. Only the
+// data key file, which lives next to the recipients file, is encrypted for
+// the recipients. Changing the recipients then only requires re-wrapping the
+// data key instead of re-encrypting every secret.
+//
+// The data key file contains one key per line, the last one is used for new
+// secrets. Older keys are kept so that secrets from the history can still be
+// decrypted after a rotation.
+
+const (
+	dataKeyFile   = ".data-key"
+	dataKeyLen    = 32
+	dataKeyIDLen  = 8
+	envelopeMagic = "GPENV1"
+)
+
+type dataKeyring struct {
+	ids  []string
+	keys map[string][]byte
+}
+
+// cachedKeyring is a decrypted keyring and the checksum of the data key file
+// it was read from. Other processes (or git pull) may replace the file, so the
+// cache is only valid as long as the checksum matches.
+type cachedKeyring struct {
+	sum string
+	k   *dataKeyring
+}
+
+func newDataKeyring() *dataKeyring {
+	return &dataKeyring{
+		keys: make(map[string][]byte, 1),
+	}
+}
+
+// rotate adds a new random key and makes it the current one
+func (k *dataKeyring) rotate() error {
+	id := make([]byte, dataKeyIDLen)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	key := make([]byte, dataKeyLen)
+	if _, err := rand.Read(key); err != nil {
+		return err
+	}
+	hid := hex.EncodeToString(id)
+	k.ids = append(k.ids, hid)
+	k.keys[hid] = key
+	return nil
+}
+
+func (k *dataKeyring) current() (string, []byte) {
+	if len(k.ids) < 1 {
+		return "", nil
+	}
+	id := k.ids[len(k.ids)-1]
+	return id, k.keys[id]
+}
+
+func (k *dataKeyring) marshal() []byte {
+	buf := &bytes.Buffer{}
+	for _, id := range k.ids {
+		fmt.Fprintf(buf, "%s %s\n", id, base64.StdEncoding.EncodeToString(k.keys[id]))
+	}
+	return buf.Bytes()
+}
+
+func unmarshalDataKeyring(buf []byte) (*dataKeyring, error) {
+	k := newDataKeyring()
+	sc := bufio.NewScanner(bytes.NewReader(buf))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		p := strings.SplitN(line, " ", 2)
+		if len(p) != 2 {
+			return nil, fmt.Errorf("invalid data key entry")
+		}
+		key, err := base64.StdEncoding.DecodeString(p[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid data key %s: %w", p[0], err)
+		}
+		if len(key) != dataKeyLen {
+			return nil, fmt.Errorf("invalid length of data key %s", p[0])
+		}
+		k.ids = append(k.ids, p[0])
+		k.keys[p[0]] = key
+	}
+	if len(k.ids) < 1 {
+		return nil, fmt.Errorf("no data keys found")
+	}
+	return k, nil
+}
+
+// isEnvelope returns true if the given ciphertext was encrypted with a data key
+func isEnvelope(ciphertext []byte) bool {
+	return bytes.HasPrefix(ciphertext, []byte(envelopeMagic))
+}
+
+// sealEnvelope encrypts the plaintext with the current key of the keyring.
+// The layout is magic | key ID | nonce | AES-256-GCM ciphertext.
+func sealEnvelope(k *dataKeyring, plaintext []byte) ([]byte, error) {
+	id, key := k.current()
+	if key == nil {
+		return nil, fmt.Errorf("no data key available")
+	}
+	bid, err := hex.DecodeString(id)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := append([]byte(envelopeMagic), bid...)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 0, len(header)+len(nonce)+len(plaintext)+aead.Overhead())
+	buf = append(buf, header...)
+	buf = append(buf, nonce...)
+	return aead.Seal(buf, nonce, plaintext, header), nil
+}
+
+// envelopeKeyID returns the ID of the data key used to encrypt the ciphertext
+func envelopeKeyID(ciphertext []byte) (string, error) {
+	if !isEnvelope(ciphertext) || len(ciphertext) < len(envelopeMagic)+dataKeyIDLen {
+		return "", fmt.Errorf("not an envelope")
+	}
+	return hex.EncodeToString(ciphertext[len(envelopeMagic) : len(envelopeMagic)+dataKeyIDLen]), nil
+}
+
+func openEnvelope(k *dataKeyring, ciphertext []byte) ([]byte, error) {
+	id, err := envelopeKeyID(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	key, found := k.keys[id]
+	if !found {
+		return nil, fmt.Errorf("data key %s not found", id)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	hl := len(envelopeMagic) + dataKeyIDLen
+	if len(ciphertext) < hl+aead.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	nonce := ciphertext[hl : hl+aead.NonceSize()]
+	return aead.Open(nil, nonce, ciphertext[hl+aead.NonceSize():], ciphertext[:hl])
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// dataKeyFile returns the data key file that belongs to the given recipients file
+func (s *Store) dataKeyFile(idf string) string {
+	return filepath.Join(filepath.Dir(idf), dataKeyFile)
+}
+
+// IsEnvelope returns true if this store uses envelope encryption
+func (s *Store) IsEnvelope(ctx context.Context) bool {
+	if s.crypto == nil {
+		return false
+	}
+	return s.storage.Exists(ctx, s.dataKeyFile(s.idFile(ctx, "")))
+}
+
+// keyring loads and caches the data keys that belong to the given recipients
+// file. The file is read every time, it's only decrypted again if it changed.
+func (s *Store) keyring(ctx context.Context, idf string) (*dataKeyring, error) {
+	s.krMu.Lock()
+	defer s.krMu.Unlock()
+
+	dkf := s.dataKeyFile(idf)
+	ciphertext, err := s.storage.Get(ctx, dkf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data key file %q: %w", dkf, err)
+	}
+	sum := keyringChecksum(ciphertext)
+	if c, found := s.keyrings[dkf]; found && c.sum == sum {
+		return c.k, nil
+	}
+	debug.Log("loading data keys from %s", dkf)
+
+	buf, err := s.crypto.Decrypt(ctx, ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data key file %q: %w", dkf, err)
+	}
+	k, err := unmarshalDataKeyring(buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse data key file %q: %w", dkf, err)
+	}
+
+	s.cacheKeyring(dkf, sum, k)
+	return k, nil
+}
+
+// cacheKeyring must be called with krMu held
+func (s *Store) cacheKeyring(dkf, sum string, k *dataKeyring) {
+	if s.keyrings == nil {
+		s.keyrings = make(map[string]cachedKeyring, 1)
+	}
+	s.keyrings[dkf] = cachedKeyring{sum: sum, k: k}
+}
+
+func keyringChecksum(ciphertext []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(ciphertext))
+}
+
+// saveKeyring encrypts the data keys for the current recipients of the given
+// recipients file and stages the result
+func (s *Store) saveKeyring(ctx context.Context, idf string, k *dataKeyring) error {
+	recps, err := s.getRecipients(ctx, idf)
+	if err != nil {
+		return fmt.Errorf("failed to read recipients from %q: %w", idf, err)
+	}
+	recps = s.ensureOurKeyID(ctx, recps)
+
+	ciphertext, err := s.crypto.Encrypt(ctx, k.marshal(), recps)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt data keys: %w", err)
+	}
+
+	dkf := s.dataKeyFile(idf)
+	if err := s.storage.Set(ctx, dkf, ciphertext); err != nil {
+		return fmt.Errorf("failed to write data key file %q: %w", dkf, err)
+	}
+	if err := s.storage.Add(ctx, dkf); err != nil {
+		if !errors.Is(err, store.ErrGitNotInit) {
+			return fmt.Errorf("failed to add %q to git: %w", dkf, err)
+		}
+	}
+
+	s.krMu.Lock()
+	defer s.krMu.Unlock()
+	s.cacheKeyring(dkf, keyringChecksum(ciphertext), k)
+	return nil
+}
+
+// sealFor encrypts the plaintext with the data key of the folder the secret
+// belongs to. The data key is created if this folder does not have one yet.
+func (s *Store) sealFor(ctx context.Context, name string, plaintext []byte) ([]byte, error) {
+	idf := s.idFile(ctx, name)
+	if !s.storage.Exists(ctx, s.dataKeyFile(idf)) {
+		debug.Log("creating data key for %s", idf)
+		k := newDataKeyring()
+		if err := k.rotate(); err != nil {
+			return nil, err
+		}
+		if err := s.saveKeyring(ctx, idf, k); err != nil {
+			return nil, err
+		}
+	}
+
+	k, err := s.keyring(ctx, idf)
+	if err != nil {
+		return nil, err
+	}
+	return sealEnvelope(k, plaintext)
+}
+
+// decrypt decrypts the ciphertext of the given secret. It handles both secrets
+// encrypted with a data key and those encrypted for the recipients directly.
+func (s *Store) decrypt(ctx context.Context, name string, ciphertext []byte) ([]byte, error) {
+	if !isEnvelope(ciphertext) {
+		return s.crypto.Decrypt(ctx, ciphertext)
+	}
+
+	k, err := s.keyring(ctx, s.idFile(ctx, name))
+	if err == nil {
+		if content, err := openEnvelope(k, ciphertext); err == nil {
+			return content, nil
+		}
+	}
+
+	// the secret might have been moved from another folder without
+	// re-encrypting it, so try the other data keys before giving up
+	for _, idf := range s.idFiles(ctx) {
+		if !s.storage.Exists(ctx, s.dataKeyFile(idf)) {
+			continue
+		}
+		k, err := s.keyring(ctx, idf)
+		if err != nil {
+			debug.Log("failed to load data key for %s: %s", idf, err)
+			continue
+		}
+		if content, err := openEnvelope(k, ciphertext); err == nil {
+			return content, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no matching data key found for %s", name)
+}
+
+// rewrap encrypts the data keys of the given recipients files for their
+// current recipients. The secrets themselves are not touched.
+func (s *Store) rewrap(ctx context.Context, idfs ...string) error {
+	for _, idf := range idfs {
+		if !s.storage.Exists(ctx, s.dataKeyFile(idf)) {
+			debug.Log("no data key for %s, nothing to rewrap", idf)
+			continue
+		}
+		k, err := s.keyring(ctx, idf)
+		if err != nil {
+			return err
+		}
+		if err := s.saveKeyring(ctx, idf, k); err != nil {
+			return err
+		}
+		out.Printf(ctx, "Re-wrapped data key %s", s.dataKeyFile(idf))
+	}
+
+	if err := s.storage.Commit(ctx, ctxutil.GetCommitMessage(ctx)); err != nil {
+		switch {
+		case errors.Is(err, store.ErrGitNotInit):
+			debug.Log("skipping git commit - git not initialized")
+		case errors.Is(err, store.ErrGitNothingToCommit):
+			debug.Log("skipping git commit - nothing to commit")
+		default:
+			return fmt.Errorf("failed to commit changes to git: %w", err)
+		}
+	}
+
+	return s.reencryptGitPush(ctx)
+}
+
+// Rekey generates a new data key for every recipients file and re-encrypts
+// all secrets with it. Stores that don't use envelope encryption yet are
+// converted.
+func (s *Store) Rekey(ctx context.Context) error {
+	idfs := s.idFiles(ctx)
+	if root := s.idFile(ctx, ""); !contains(idfs, root) {
+		idfs = append(idfs, root)
+	}
+
+	for _, idf := range idfs {
+		k := newDataKeyring()
+		if s.storage.Exists(ctx, s.dataKeyFile(idf)) {
+			ok, err := s.keyring(ctx, idf)
+			if err != nil {
+				return err
+			}
+			// copy the keyring so concurrent readers keep a consistent view
+			k.ids = append(k.ids, ok.ids...)
+			for id, key := range ok.keys {
+				k.keys[id] = key
+			}
+		}
+		if err := k.rotate(); err != nil {
+			return fmt.Errorf("failed to generate data key: %w", err)
+		}
+		if err := s.saveKeyring(ctx, idf, k); err != nil {
+			return err
+		}
+	}
+
+	return s.reencrypt(ctx)
+}
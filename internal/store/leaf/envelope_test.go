@@ -0,0 +1,177 @@
+package leaf
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	plain "github.com/gopasspw/gopass/internal/backend/crypto/plain"
+	"github.com/gopasspw/gopass/internal/backend/storage/fs"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataKeyring(t *testing.T) {
+	k := newDataKeyring()
+	_, err := sealEnvelope(k, []byte("foo"))
+	assert.Error(t, err)
+
+	require.NoError(t, k.rotate())
+	ct, err := sealEnvelope(k, []byte("foo"))
+	require.NoError(t, err)
+	assert.True(t, isEnvelope(ct))
+	assert.NotContains(t, string(ct), "foo")
+
+	pt, err := openEnvelope(k, ct)
+	require.NoError(t, err)
+	assert.Equal(t, "foo", string(pt))
+
+	// round trip keeps all keys and the current key
+	k2, err := unmarshalDataKeyring(k.marshal())
+	require.NoError(t, err)
+	pt, err = openEnvelope(k2, ct)
+	require.NoError(t, err)
+	assert.Equal(t, "foo", string(pt))
+
+	// old keys remain usable after a rotation
+	require.NoError(t, k2.rotate())
+	pt, err = openEnvelope(k2, ct)
+	require.NoError(t, err)
+	assert.Equal(t, "foo", string(pt))
+	ct2, err := sealEnvelope(k2, []byte("bar"))
+	require.NoError(t, err)
+	_, err = openEnvelope(k, ct2)
+	assert.Error(t, err)
+
+	// tampering is detected
+	ct[len(ct)-1] ^= 0x01
+	_, err = openEnvelope(k, ct)
+	assert.Error(t, err)
+
+	for _, in := range []string{"", "foo", "abcd !!!", "abcd Zm9v"} {
+		_, err := unmarshalDataKeyring([]byte(in))
+		assert.Error(t, err, in)
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	ctx := context.Background()
+	ctx = ctxutil.WithExportKeys(ctx, false)
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+
+	obuf := &bytes.Buffer{}
+	out.Stdout = obuf
+	defer func() {
+		out.Stdout = os.Stdout
+	}()
+
+	tempdir, err := os.MkdirTemp("", "gopass-")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(tempdir)
+	}()
+
+	s := &Store{
+		alias:   "",
+		path:    tempdir,
+		crypto:  plain.New(),
+		storage: fs.New(tempdir),
+	}
+	require.NoError(t, s.saveRecipients(ctx, []string{"john.doe"}, "test"))
+
+	for _, e := range []string{"foo/bar", "foo/baz", "team/zab"} {
+		sec := &secrets.Plain{}
+		sec.SetPassword(e)
+		require.NoError(t, s.Set(ctx, e, sec))
+	}
+	assert.False(t, s.IsEnvelope(ctx))
+
+	// a subfolder with its own recipients gets its own data key
+	require.NoError(t, s.SetFolderRecipients(ctx, "team", []string{"jane.doe"}))
+
+	// convert the store
+	require.NoError(t, s.Rekey(ctx))
+	assert.True(t, s.IsEnvelope(ctx))
+	assert.FileExists(t, filepath.Join(tempdir, dataKeyFile))
+	assert.FileExists(t, filepath.Join(tempdir, "team", dataKeyFile))
+
+	for _, e := range []string{"foo/bar", "foo/baz", "team/zab"} {
+		ct, err := s.storage.Get(ctx, s.passfile(e))
+		require.NoError(t, err)
+		assert.True(t, isEnvelope(ct), e)
+
+		sec, err := s.Get(ctx, e)
+		require.NoError(t, err)
+		assert.Equal(t, e, sec.Password())
+	}
+
+	// new secrets use the data key, too
+	sec := &secrets.Plain{}
+	sec.SetPassword("new")
+	require.NoError(t, s.Set(ctx, "foo/new", sec))
+	before, err := s.storage.Get(ctx, s.passfile("foo/new"))
+	require.NoError(t, err)
+	assert.True(t, isEnvelope(before))
+
+	// adding a recipient only re-wraps the data key
+	obuf.Reset()
+	require.NoError(t, s.AddRecipient(ctx, "0xDEADBEEF"))
+	assert.Contains(t, obuf.String(), "Re-wrapped data key .data-key")
+	assert.NotContains(t, obuf.String(), "Starting reencrypt")
+	after, err := s.storage.Get(ctx, s.passfile("foo/new"))
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+
+	// rotating re-encrypts everything with a new key, but old revisions can
+	// still be decrypted
+	require.NoError(t, s.Rekey(ctx))
+	after, err = s.storage.Get(ctx, s.passfile("foo/new"))
+	require.NoError(t, err)
+	assert.NotEqual(t, before, after)
+	pt, err := s.decrypt(ctx, "foo/new", before)
+	require.NoError(t, err)
+	assert.Equal(t, "new\n", string(pt))
+
+	// a secret copied to a folder with a different data key is still readable
+	require.NoError(t, s.storage.Set(ctx, s.passfile("team/new"), after))
+	sec2, err := s.Get(ctx, "team/new")
+	require.NoError(t, err)
+	assert.Equal(t, "new", sec2.Password())
+
+	// and fsck fixes it
+	s.keyrings = nil
+	require.NoError(t, s.Fsck(WithFsckDecrypt(ctx, true), ""))
+	assert.Contains(t, obuf.String(), "Re-encrypting automatically team/new to fix the data key.")
+	fixed, err := s.storage.Get(ctx, s.passfile("team/new"))
+	require.NoError(t, err)
+	assert.NotEqual(t, after, fixed)
+	_, err = openEnvelope(s.keyrings[filepath.Join("team", dataKeyFile)].k, fixed)
+	assert.NoError(t, err)
+	// keys rotated by another process, e.g. through git pull, are picked up
+	// and never overwritten with the stale cached keys
+	other := &Store{
+		alias:   "",
+		path:    tempdir,
+		crypto:  plain.New(),
+		storage: fs.New(tempdir),
+	}
+	require.NoError(t, other.Rekey(ctx))
+	ok, err := other.keyring(ctx, other.idFile(ctx, ""))
+	require.NoError(t, err)
+	sec3, err := s.Get(ctx, "foo/bar")
+	require.NoError(t, err)
+	assert.Equal(t, "foo/bar", sec3.Password())
+
+	require.NoError(t, s.Rekey(ctx))
+	k, err := other.keyring(ctx, other.idFile(ctx, ""))
+	require.NoError(t, err)
+	for _, id := range ok.ids {
+		assert.Contains(t, k.ids, id)
+	}
+}
@@ -32,6 +32,23 @@ func (s *Store) Fsck(ctx context.Context, path string) error {
 		return fmt.Errorf("storage backend compaction failed: %w", err)
 	}
 
+	// warn about recipient keys that are (about to become) unusable
+	out.Printf(ctx, "Checking recipient keys")
+	issues, err := s.CheckRecipientKeys(ctx, GetKeyExpiryWarning(ctx))
+	if err != nil {
+		out.Errorf(ctx, "Failed to check recipient keys: %s", err)
+	}
+	for _, ki := range issues {
+		out.Warningf(ctx, "%s", ki)
+	}
+
+	if s.IsEnvelope(ctx) {
+		out.Printf(ctx, "Checking data keys")
+		if err := s.fsckCheckDataKeys(ctx); err != nil {
+			return fmt.Errorf("failed to check data keys: %w", err)
+		}
+	}
+
 	pcb := ctxutil.GetProgressCallback(ctx)
 
 	// then we'll make sure all the secrets are readable by us and every
@@ -91,6 +108,10 @@ func (s *Store) fsckCheckEntry(ctx context.Context, name string) error {
 		return fmt.Errorf("failed to get raw secret: %w", err)
 	}
 
+	if isEnvelope(ciphertext) {
+		return s.fsckCheckEnvelope(ctx, name, ciphertext)
+	}
+
 	itemRecps, err := s.crypto.RecipientIDs(ctx, ciphertext)
 	if err != nil {
 		return fmt.Errorf("failed to read recipient IDs from raw secret: %w", err)
@@ -127,6 +148,85 @@ func (s *Store) fsckCheckEntry(ctx context.Context, name string) error {
 	return nil
 }
 
+// fsckCheckDataKeys makes sure every data key file is encrypted for the
+// recipients of its folder
+func (s *Store) fsckCheckDataKeys(ctx context.Context) error {
+	idfs := s.idFiles(ctx)
+	if root := s.idFile(ctx, ""); !contains(idfs, root) {
+		idfs = append(idfs, root)
+	}
+
+	for _, idf := range idfs {
+		dkf := s.dataKeyFile(idf)
+		ciphertext, err := s.storage.Get(ctx, dkf)
+		if err != nil {
+			debug.Log("no data key for %s: %s", idf, err)
+			continue
+		}
+
+		keyRecps, err := s.crypto.RecipientIDs(ctx, ciphertext)
+		if err != nil {
+			return fmt.Errorf("failed to read recipient IDs from %q: %w", dkf, err)
+		}
+		keyRecps = fingerprints(ctx, s.crypto, keyRecps)
+
+		storeRecps, err := s.getRecipients(ctx, idf)
+		if err != nil {
+			return fmt.Errorf("failed to get recipients from %q: %w", idf, err)
+		}
+		storeRecps = fingerprints(ctx, s.crypto, storeRecps)
+
+		missing, extra := compareStringSlices(storeRecps, keyRecps)
+		if len(missing) > 0 {
+			out.Errorf(ctx, "Missing recipients on %s: %+v\nRun fsck with the --decrypt flag to re-wrap it automatically.", dkf, missing)
+		}
+		if len(extra) > 0 {
+			out.Errorf(ctx, "Extra recipients on %s: %+v\nRun fsck with the --decrypt flag to re-wrap it automatically.", dkf, extra)
+		}
+
+		if IsFsckDecrypt(ctx) && (len(missing) > 0 || len(extra) > 0) {
+			out.Printf(ctx, "Re-wrapping automatically %s to fix the recipients.", dkf)
+			if err := s.rewrap(ctxutil.WithCommitMessage(ctx, "fsck fix recipients"), idf); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// fsckCheckEnvelope makes sure a secret is encrypted with a data key of the
+// folder it belongs to. Otherwise it would become unreadable for the
+// recipients of that folder.
+func (s *Store) fsckCheckEnvelope(ctx context.Context, name string, ciphertext []byte) error {
+	// without decryption we can't look into the data key files
+	if !IsFsckDecrypt(ctx) {
+		return nil
+	}
+
+	id, err := envelopeKeyID(ciphertext)
+	if err != nil {
+		return err
+	}
+
+	if k, err := s.keyring(ctx, s.idFile(ctx, name)); err == nil {
+		if _, found := k.keys[id]; found {
+			return nil
+		}
+	}
+
+	out.Errorf(ctx, "Secret %s is encrypted with a data key of another folder", name)
+	out.Printf(ctx, "Re-encrypting automatically %s to fix the data key.", name)
+	sec, err := s.Get(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to decode secret: %w", err)
+	}
+	if err := s.Set(ctxutil.WithCommitMessage(ctx, "fsck fix data key"), name, sec); err != nil {
+		return fmt.Errorf("failed to write secret: %w", err)
+	}
+	return nil
+}
+
 func fingerprints(ctx context.Context, crypto backend.Crypto, in []string) []string {
 	out := make([]string, 0, len(in))
 	for _, r := range in {
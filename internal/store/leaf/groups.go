@@ -0,0 +1,206 @@
+package leaf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/recipients"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// groupsFile contains the store wide recipient group definitions. It is shared
+// by all crypto backends, so it can be referenced from any recipients file.
+const groupsFile = ".recipient-groups"
+
+// Groups returns the recipient groups defined for this store
+func (s *Store) Groups(ctx context.Context) (recipients.Groups, error) {
+	if !s.storage.Exists(ctx, groupsFile) {
+		return recipients.Groups{}, nil
+	}
+	buf, err := s.storage.Get(ctx, groupsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", groupsFile, err)
+	}
+	if err := s.verifyFile(ctx, groupsFile, buf); err != nil {
+		return nil, err
+	}
+	return recipients.UnmarshalGroups(buf)
+}
+
+// AddGroupMembers adds the given IDs to the named group (creating it if
+// necessary) and re-encrypts all secrets that reference this group.
+func (s *Store) AddGroupMembers(ctx context.Context, group string, ids ...string) error {
+	group = recipients.GroupName(group)
+	g, err := s.Groups(ctx)
+	if err != nil {
+		return err
+	}
+
+	added := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if contains(g[group], id) {
+			debug.Log("%q already member of group %q", id, group)
+			continue
+		}
+		g[group] = append(g[group], id)
+		added = append(added, id)
+	}
+	if len(added) < 1 {
+		return fmt.Errorf("no new members for group %q", group)
+	}
+
+	if _, err := g.Expand([]string{recipients.GroupPrefix + group}); err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("Added %s to group @%s", strings.Join(added, ", "), group)
+	if err := s.saveGroups(ctx, g, msg); err != nil {
+		return err
+	}
+
+	// save all new members public keys to the repo
+	if ctxutil.IsExportKeys(ctx) {
+		if _, err := s.ExportMissingPublicKeys(ctx, added); err != nil {
+			out.Errorf(ctx, "Failed to export missing public keys: %s", err)
+		}
+	}
+
+	return s.reencryptGroup(ctxutil.WithCommitMessage(ctx, msg), group)
+}
+
+// RemoveGroupMembers removes the given IDs from the named group and
+// re-encrypts all secrets that reference this group. Removing the last member
+// deletes the group, but only if it is not referenced anymore.
+func (s *Store) RemoveGroupMembers(ctx context.Context, group string, ids ...string) error {
+	group = recipients.GroupName(group)
+	g, err := s.Groups(ctx)
+	if err != nil {
+		return err
+	}
+
+	members, found := g[group]
+	if !found {
+		return fmt.Errorf("unknown recipient group %q", group)
+	}
+
+	nm := make([]string, 0, len(members))
+	for _, m := range members {
+		if contains(ids, m) {
+			continue
+		}
+		nm = append(nm, m)
+	}
+	if len(nm) == len(members) {
+		return fmt.Errorf("none of %+v is a member of group %q", ids, group)
+	}
+
+	if len(nm) > 0 {
+		g[group] = nm
+	} else {
+		if refs := s.groupIDFiles(ctx, g, group); len(refs) > 0 {
+			return fmt.Errorf("can not remove all members of group %q, it is still used in %+v", group, refs)
+		}
+		delete(g, group)
+	}
+
+	msg := fmt.Sprintf("Removed %s from group @%s", strings.Join(ids, ", "), group)
+	if err := s.saveGroups(ctx, g, msg); err != nil {
+		return err
+	}
+
+	return s.reencryptGroup(ctxutil.WithCommitMessage(ctx, msg), group)
+}
+
+// groupIDFiles returns all recipient files that reference the given group
+func (s *Store) groupIDFiles(ctx context.Context, g recipients.Groups, group string) []string {
+	idfs := make([]string, 0, 1)
+	for _, idf := range s.idFiles(ctx) {
+		rs, err := s.getRawRecipients(ctx, idf)
+		if err != nil {
+			debug.Log("failed to read recipients from %q: %s", idf, err)
+			continue
+		}
+		if g.References(rs, group) {
+			idfs = append(idfs, idf)
+		}
+	}
+	return idfs
+}
+
+// reencryptGroup re-encrypts only those secrets that are covered by a
+// recipients file referencing the given group.
+func (s *Store) reencryptGroup(ctx context.Context, group string) error {
+	g, err := s.Groups(ctx)
+	if err != nil {
+		return err
+	}
+	idfs := s.groupIDFiles(ctx, g, group)
+	if len(idfs) < 1 {
+		debug.Log("group %q is not used, nothing to re-encrypt", group)
+		return nil
+	}
+
+	if s.IsEnvelope(ctx) {
+		return s.rewrap(ctx, idfs...)
+	}
+
+	entries, err := s.List(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to list store: %w", err)
+	}
+
+	affected := make([]string, 0, len(entries))
+	for _, e := range entries {
+		name := strings.TrimPrefix(strings.TrimPrefix(e, s.alias), "/")
+		if contains(idfs, s.idFile(ctx, name)) {
+			affected = append(affected, e)
+		}
+	}
+
+	out.Printf(ctx, "Reencrypting %d secrets in %+v. This may take some time ...", len(affected), idfs)
+	return s.reencryptEntries(ctx, affected)
+}
+
+func (s *Store) saveGroups(ctx context.Context, g recipients.Groups, msg string) error {
+	buf, err := recipients.MarshalGroups(g)
+	if err != nil {
+		return err
+	}
+	if err := s.verifyExisting(ctx, groupsFile); err != nil {
+		return err
+	}
+	if err := s.storage.Set(ctx, groupsFile, buf); err != nil {
+		return fmt.Errorf("failed to write recipient groups: %w", err)
+	}
+
+	if err := s.storage.Add(ctx, groupsFile); err != nil {
+		if !errors.Is(err, store.ErrGitNotInit) {
+			return fmt.Errorf("failed to add file %q to git: %w", groupsFile, err)
+		}
+	}
+
+	if err := s.signFile(ctx, groupsFile, buf); err != nil {
+		return err
+	}
+
+	if err := s.storage.Commit(ctx, msg); err != nil {
+		if !errors.Is(err, store.ErrGitNotInit) && !errors.Is(err, store.ErrGitNothingToCommit) {
+			return fmt.Errorf("failed to commit changes to git: %w", err)
+		}
+	}
+	return nil
+}
+
+func contains(haystack []string, needle string) bool {
+	for _, blade := range haystack {
+		if blade == needle {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package leaf
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	plain "github.com/gopasspw/gopass/internal/backend/crypto/plain"
+	"github.com/gopasspw/gopass/internal/backend/storage/fs"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/recipients"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroups(t *testing.T) {
+	ctx := context.Background()
+	ctx = ctxutil.WithHidden(ctx, true)
+
+	tempdir, err := os.MkdirTemp("", "gopass-")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(tempdir)
+	}()
+
+	_, _, err = createStore(tempdir, nil, nil)
+	require.NoError(t, err)
+
+	obuf := &bytes.Buffer{}
+	out.Stdout = obuf
+	defer func() {
+		out.Stdout = os.Stdout
+	}()
+
+	s := &Store{
+		alias:   "",
+		path:    tempdir,
+		crypto:  plain.New(),
+		storage: fs.New(tempdir),
+	}
+
+	g, err := s.Groups(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, recipients.Groups{}, g)
+
+	// referencing an unknown group must fail
+	assert.Error(t, s.AddRecipient(ctx, "@sre"))
+
+	require.NoError(t, s.AddGroupMembers(ctx, "sre", "0xBEEFFEED", "john.doe"))
+	assert.Error(t, s.AddGroupMembers(ctx, "@sre", "john.doe"))
+
+	require.NoError(t, s.SetFolderRecipients(ctx, "foo", []string{"@sre"}))
+
+	rs, err := s.GetRecipients(ctx, "foo/bar/baz")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0xBEEFFEED", "john.doe"}, rs)
+
+	require.NoError(t, s.AddRecipient(ctx, "@sre"))
+	rs, err = s.GetRecipients(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0xBEEFFEED", "0xDEADBEEF", "0xFEEDBEEF", "john.doe"}, rs)
+
+	raw, err := s.getRawRecipients(ctx, s.idFile(ctx, ""))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0xDEADBEEF", "0xFEEDBEEF", "@sre"}, raw)
+
+	assert.Equal(t, []string{s.crypto.IDFile(), filepath.Join("foo", s.crypto.IDFile())}, s.groupIDFiles(ctx, g, "sre"))
+
+	require.NoError(t, s.RemoveGroupMembers(ctx, "sre", "john.doe"))
+	rs, err = s.GetRecipients(ctx, "foo/bar/baz")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0xBEEFFEED"}, rs)
+
+	// the group is still referenced, so it can not be emptied
+	assert.Error(t, s.RemoveGroupMembers(ctx, "sre", "0xBEEFFEED"))
+	assert.Error(t, s.RemoveGroupMembers(ctx, "dev", "0xBEEFFEED"))
+}
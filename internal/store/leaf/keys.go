@@ -0,0 +1,136 @@
+package leaf
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// KeyStatus is the kind of problem found with a recipient's key
+type KeyStatus int
+
+const (
+	// KeyExpiring means the key will expire soon
+	KeyExpiring KeyStatus = iota
+	// KeyExpired means the key has already expired
+	KeyExpired
+	// KeyRevoked means the key has been revoked
+	KeyRevoked
+	// KeyMissing means the key is not available in the keyring
+	KeyMissing
+)
+
+func (k KeyStatus) String() string {
+	switch k {
+	case KeyExpiring:
+		return "expiring"
+	case KeyExpired:
+		return "expired"
+	case KeyRevoked:
+		return "revoked"
+	case KeyMissing:
+		return "missing"
+	default:
+		return "unknown"
+	}
+}
+
+// KeyIssue describes a recipient key that needs attention and the folders
+// that depend on it
+type KeyIssue struct {
+	Recipient string
+	Status    KeyStatus
+	Expires   time.Time
+	Folders   []string
+}
+
+// String implements fmt.Stringer
+func (k KeyIssue) String() string {
+	switch k.Status {
+	case KeyExpiring:
+		return fmt.Sprintf("Key %s expires on %s (used by %v)", k.Recipient, k.Expires.Format("2006-01-02"), k.Folders)
+	case KeyExpired:
+		return fmt.Sprintf("Key %s expired on %s (used by %v)", k.Recipient, k.Expires.Format("2006-01-02"), k.Folders)
+	default:
+		return fmt.Sprintf("Key %s is %s (used by %v)", k.Recipient, k.Status, k.Folders)
+	}
+}
+
+// keyValidator is implemented by crypto backends that can report the validity
+// of a recipient's key, e.g. GPG. Backends without expiring keys, like age,
+// don't need to implement it.
+type keyValidator interface {
+	KeyValidity(ctx context.Context, id string) (time.Time, bool, error)
+}
+
+// CheckRecipientKeys checks the keys of all recipients of this store and
+// reports any key that is missing from the keyring, revoked, expired or
+// expires within the given duration.
+func (s *Store) CheckRecipientKeys(ctx context.Context, within time.Duration) ([]KeyIssue, error) {
+	kv, ok := s.crypto.(keyValidator)
+	if !ok {
+		debug.Log("key validity checks not supported by %T", s.crypto)
+		return nil, nil
+	}
+
+	// map each recipient to the folders it is used in
+	folders := make(map[string][]string, 10)
+	idfs := s.idFiles(ctx)
+	if len(idfs) < 1 {
+		idfs = []string{s.idFile(ctx, "")}
+	}
+	for _, idf := range idfs {
+		rs, err := s.getRecipients(ctx, idf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read recipients from %q: %w", idf, err)
+		}
+		dir := filepath.ToSlash(filepath.Dir(idf))
+		if dir == "." {
+			dir = ""
+		}
+		dir = path.Join(s.alias, dir) + "/"
+		for _, r := range rs {
+			folders[r] = append(folders[r], dir)
+		}
+	}
+
+	recps := make([]string, 0, len(folders))
+	for r := range folders {
+		recps = append(recps, r)
+	}
+	sort.Strings(recps)
+
+	issues := make([]KeyIssue, 0, len(recps))
+	for _, r := range recps {
+		ki := KeyIssue{
+			Recipient: r,
+			Folders:   folders[r],
+		}
+		exp, revoked, err := kv.KeyValidity(ctx, r)
+		switch {
+		case err != nil:
+			debug.Log("failed to get key validity for %s: %s", r, err)
+			ki.Status = KeyMissing
+		case revoked:
+			ki.Status = KeyRevoked
+		case exp.IsZero():
+			continue
+		case exp.Before(time.Now()):
+			ki.Status = KeyExpired
+			ki.Expires = exp
+		case exp.Before(time.Now().Add(within)):
+			ki.Status = KeyExpiring
+			ki.Expires = exp
+		default:
+			continue
+		}
+		issues = append(issues, ki)
+	}
+
+	return issues, nil
+}
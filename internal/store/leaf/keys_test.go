@@ -0,0 +1,99 @@
+package leaf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	plain "github.com/gopasspw/gopass/internal/backend/crypto/plain"
+	"github.com/gopasspw/gopass/internal/backend/storage/fs"
+	"github.com/gopasspw/gopass/internal/out"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type validityCrypto struct {
+	*plain.Mocker
+	expires map[string]time.Time
+	revoked map[string]bool
+}
+
+func (v *validityCrypto) KeyValidity(ctx context.Context, id string) (time.Time, bool, error) {
+	if v.revoked[id] {
+		return time.Time{}, true, nil
+	}
+	exp, found := v.expires[id]
+	if !found {
+		return time.Time{}, false, fmt.Errorf("key not found")
+	}
+	return exp, false, nil
+}
+
+func TestCheckRecipientKeys(t *testing.T) {
+	ctx := context.Background()
+
+	tempdir, err := os.MkdirTemp("", "gopass-")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(tempdir)
+	}()
+
+	_, _, err = createStore(tempdir, []string{"0xDEADBEEF", "0xFEEDBEEF", "0xBEEFFEED", "0xBEEFDEAD"}, nil)
+	require.NoError(t, err)
+
+	obuf := &bytes.Buffer{}
+	out.Stdout = obuf
+	defer func() {
+		out.Stdout = os.Stdout
+	}()
+
+	now := time.Now()
+	crypto := &validityCrypto{
+		Mocker: plain.New(),
+		expires: map[string]time.Time{
+			"0xDEADBEEF": {},
+			"0xFEEDBEEF": now.Add(24 * time.Hour),
+			"john.doe":   now.Add(-24 * time.Hour),
+		},
+		revoked: map[string]bool{
+			"0xBEEFFEED": true,
+		},
+	}
+	s := &Store{
+		alias:   "team",
+		path:    tempdir,
+		crypto:  crypto,
+		storage: fs.New(tempdir),
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(tempdir, "foo", s.crypto.IDFile()), []byte("john.doe\n0xFEEDBEEF\n"), 0600))
+
+	issues, err := s.CheckRecipientKeys(ctx, 7*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []KeyIssue{
+		{Recipient: "0xBEEFDEAD", Status: KeyMissing, Folders: []string{"team/"}},
+		{Recipient: "0xBEEFFEED", Status: KeyRevoked, Folders: []string{"team/"}},
+		{Recipient: "0xFEEDBEEF", Status: KeyExpiring, Expires: crypto.expires["0xFEEDBEEF"], Folders: []string{"team/", "team/foo/"}},
+		{Recipient: "john.doe", Status: KeyExpired, Expires: crypto.expires["john.doe"], Folders: []string{"team/foo/"}},
+	}, issues)
+	assert.Equal(t, "Key 0xBEEFFEED is revoked (used by [team/])", issues[1].String())
+
+	// a shorter warning period must not report the expiring key
+	issues, err = s.CheckRecipientKeys(ctx, time.Hour)
+	require.NoError(t, err)
+	assert.Len(t, issues, 3)
+
+	// the plain backend only knows its static keys
+	s.crypto = plain.New()
+	issues, err = s.CheckRecipientKeys(ctx, time.Hour)
+	require.NoError(t, err)
+	for _, ki := range issues {
+		assert.Equal(t, KeyMissing, ki.Status, ki.Recipient)
+	}
+	assert.Len(t, issues, 3)
+}
@@ -0,0 +1,298 @@
+package leaf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/gopass"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets/secparse"
+	"github.com/nbutton23/zxcvbn-go"
+	"gopkg.in/yaml.v3"
+)
+
+// PolicyFile is the name of the policy file in the root of a store. It's
+// versioned with the store so the policy travels with gopass clone.
+const PolicyFile = ".gopass-policy.yml"
+
+// ErrPolicyViolation is returned if a secret does not comply with the store
+// policy
+var ErrPolicyViolation = errors.New("store policy violation")
+
+// Policy contains the conventions of a (team) store. It overrides or
+// constrains the local config.
+type Policy struct {
+	// Config overrides the local and per-mount config for this store
+	Config config.MountConfig `yaml:"config"`
+	// MaxClipTimeout caps the clipboard timeout
+	MaxClipTimeout int            `yaml:"maxcliptimeout"`
+	Password       PasswordPolicy `yaml:"password"`
+	// Template is used for new secrets if there is no matching .pass-template
+	Template string `yaml:"template"`
+	// Required lists the keys every secret must have
+	Required []string `yaml:"required"`
+	// AutoSync pushes every change to the remote, defaults to true
+	AutoSync *bool `yaml:"autosync"`
+}
+
+// PasswordPolicy contains the password conventions of a store
+type PasswordPolicy struct {
+	// Length is the default length of generated passwords
+	Length int `yaml:"length"`
+	// MinLength is the minimum length of passwords
+	MinLength int `yaml:"minlength"`
+	// Classes lists the character classes every password must contain: lower,
+	// upper, digit and symbol
+	Classes []string `yaml:"classes"`
+	// MinScore is the minimum zxcvbn score (0-4)
+	MinScore int `yaml:"minscore"`
+	// Forbidden lists passwords that must never be used (case insensitive)
+	Forbidden []string `yaml:"forbidden"`
+	// Enforce is either reject (default) or warn
+	Enforce string `yaml:"enforce"`
+	// AllowForce allows to store violating passwords with --force
+	AllowForce bool `yaml:"allowforce"`
+	// Folders contains stricter (or different) rules for folders. The most
+	// specific folder wins, its non-zero values replace the store defaults.
+	Folders map[string]PasswordPolicy `yaml:"folders"`
+}
+
+const (
+	// EnforceReject rejects passwords that violate the policy
+	EnforceReject = "reject"
+	// EnforceWarn only warns about passwords that violate the policy
+	EnforceWarn = "warn"
+)
+
+// Policy loads the policy of this store. Stores without a policy file have an
+// empty policy.
+func (s *Store) Policy(ctx context.Context) (*Policy, error) {
+	p := &Policy{}
+	if !s.storage.Exists(ctx, PolicyFile) {
+		return p, nil
+	}
+	buf, err := s.storage.Get(ctx, PolicyFile)
+	if err != nil {
+		return p, fmt.Errorf("failed to read policy: %w", err)
+	}
+	if err := yaml.Unmarshal(buf, p); err != nil {
+		return &Policy{}, fmt.Errorf("failed to parse policy %s: %w", PolicyFile, err)
+	}
+	debug.Log("loaded policy for %q: %+v", s.alias, p)
+	return p, nil
+}
+
+// policy loads the policy and falls back to an empty policy on errors
+func (s *Store) policy(ctx context.Context) *Policy {
+	p, err := s.Policy(ctx)
+	if err != nil {
+		out.Warningf(ctx, "Ignoring invalid policy of store %q: %s", s.alias, err)
+	}
+	return p
+}
+
+// Apply returns a copy of cfg with the policy applied
+func (p *Policy) Apply(cfg *config.Config) *config.Config {
+	cfg = cfg.Apply(&p.Config)
+	if p.MaxClipTimeout > 0 && (cfg.ClipTimeout <= 0 || cfg.ClipTimeout > p.MaxClipTimeout) {
+		nc := *cfg
+		nc.ClipTimeout = p.MaxClipTimeout
+		cfg = &nc
+	}
+	return cfg
+}
+
+// Overrides returns the names of the options of cfg the policy changes
+func (p *Policy) Overrides(cfg *config.Config) []string {
+	keys := p.Config.Keys()
+	if p.MaxClipTimeout > 0 && (cfg.ClipTimeout <= 0 || cfg.ClipTimeout > p.MaxClipTimeout) && p.Config.ClipTimeout == nil {
+		keys = append(keys, "cliptimeout")
+	}
+	return keys
+}
+
+// WithContext returns a context with the options overridden by the policy
+func (p *Policy) WithContext(ctx context.Context) context.Context {
+	return p.Config.WithContext(ctx)
+}
+
+// IsAutoSync returns true if changes should be pushed to the remote
+func (p *Policy) IsAutoSync() bool {
+	return p.AutoSync == nil || *p.AutoSync
+}
+
+// CheckPolicy returns an error if the secret does not comply with the policy
+// of this store. Password violations only cause a warning if the policy is not
+// enforced or if it is overridden. Unchanged passwords of existing secrets are
+// not checked. An invalid policy rejects all secrets.
+func (s *Store) CheckPolicy(ctx context.Context, name string, sec gopass.Byter) error {
+	p, err := s.Policy(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrPolicyViolation, err)
+	}
+	pp := p.Password.For(name)
+	if len(p.Required) < 1 && !pp.hasRules() {
+		return nil
+	}
+	// always parse the content, plain secrets (e.g. from edit) have no keys
+	ps, err := secparse.Parse(sec.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to parse secret: %w", err)
+	}
+	if err := p.Check(ps); err != nil {
+		return err
+	}
+	if !pp.hasRules() || !s.passwordChanged(ctx, name, ps.Password()) {
+		return nil
+	}
+
+	violations := pp.Check(name, ps)
+	if len(violations) < 1 {
+		return nil
+	}
+	err = fmt.Errorf("%w: password of %s: %s", ErrPolicyViolation, name, strings.Join(violations, ", "))
+	switch {
+	case pp.Enforce == EnforceWarn:
+		out.Warningf(ctx, "%s", err)
+		return nil
+	case pp.AllowForce && IsPolicyOverride(ctx):
+		out.Warningf(ctx, "Overriding %s", err)
+		return nil
+	}
+	return err
+}
+
+// passwordChanged returns false if the secret exists and already has the
+// given password, e.g. if only its metadata was edited
+func (s *Store) passwordChanged(ctx context.Context, name, pw string) bool {
+	if !s.Exists(ctx, name) {
+		return true
+	}
+	sec, err := s.Get(ctx, name)
+	if err != nil {
+		debug.Log("failed to read %q: %s", name, err)
+		return true
+	}
+	return sec.Password() != pw
+}
+
+// Check returns an error if the secret lacks any of the required keys
+func (p *Policy) Check(sec gopass.Secret) error {
+	missing := make([]string, 0, len(p.Required))
+	for _, k := range p.Required {
+		if v, found := sec.Get(k); !found || v == "" {
+			missing = append(missing, k)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: missing required keys: %s", ErrPolicyViolation, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// For returns the rules for the given secret
+func (pp PasswordPolicy) For(name string) PasswordPolicy {
+	np := pp
+	np.Folders = nil
+
+	best := ""
+	for folder := range pp.Folders {
+		f := strings.Trim(folder, "/")
+		if f == "" || !strings.HasPrefix(name+"/", f+"/") {
+			continue
+		}
+		if best == "" || len(f) > len(strings.Trim(best, "/")) {
+			best = folder
+		}
+	}
+	if best == "" {
+		return np
+	}
+
+	fp := pp.Folders[best]
+	if fp.Length > 0 {
+		np.Length = fp.Length
+	}
+	if fp.MinLength > 0 {
+		np.MinLength = fp.MinLength
+	}
+	if len(fp.Classes) > 0 {
+		np.Classes = fp.Classes
+	}
+	if fp.MinScore > 0 {
+		np.MinScore = fp.MinScore
+	}
+	if len(fp.Forbidden) > 0 {
+		np.Forbidden = append(append([]string{}, np.Forbidden...), fp.Forbidden...)
+	}
+	if fp.Enforce != "" {
+		np.Enforce = fp.Enforce
+	}
+	if fp.AllowForce {
+		np.AllowForce = true
+	}
+	return np
+}
+
+func (pp PasswordPolicy) hasRules() bool {
+	return pp.MinLength > 0 || len(pp.Classes) > 0 || pp.MinScore > 0 || len(pp.Forbidden) > 0
+}
+
+// Check returns all violations of the rules. Empty passwords are not checked.
+func (pp PasswordPolicy) Check(name string, sec gopass.Secret) []string {
+	pw := sec.Password()
+	if pw == "" {
+		return nil
+	}
+
+	var violations []string
+	if l := utf8.RuneCountInString(pw); l < pp.MinLength {
+		violations = append(violations, fmt.Sprintf("too short (%d < %d)", l, pp.MinLength))
+	}
+	for _, class := range pp.Classes {
+		check, found := passwordClasses[class]
+		if !found {
+			violations = append(violations, fmt.Sprintf("unknown character class %q", class))
+			continue
+		}
+		if strings.IndexFunc(pw, check) < 0 {
+			violations = append(violations, fmt.Sprintf("no %s character", class))
+		}
+	}
+	for _, f := range pp.Forbidden {
+		if strings.EqualFold(pw, f) {
+			violations = append(violations, "forbidden password")
+			break
+		}
+	}
+	if pp.MinScore > 0 {
+		// like audit: don't let the password consist of other values of the
+		// secret or its name
+		ui := make([]string, 0, len(sec.Keys())+1)
+		for _, k := range sec.Keys() {
+			if v, found := sec.Get(k); found {
+				ui = append(ui, v)
+			}
+		}
+		ui = append(ui, name)
+		if score := zxcvbn.PasswordStrength(pw, ui).Score; score < pp.MinScore {
+			violations = append(violations, fmt.Sprintf("too weak (%d / 4 < %d)", score, pp.MinScore))
+		}
+	}
+	return violations
+}
+
+var passwordClasses = map[string]func(rune) bool{
+	"lower": unicode.IsLower,
+	"upper": unicode.IsUpper,
+	"digit": unicode.IsDigit,
+	"symbol": func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSymbol(r)
+	},
+}
@@ -0,0 +1,152 @@
+package leaf
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy(t *testing.T) {
+	ctx := context.Background()
+
+	tempdir := t.TempDir()
+	s, err := createSubStore(tempdir)
+	require.NoError(t, err)
+
+	// no policy
+	p, err := s.Policy(ctx)
+	require.NoError(t, err)
+	assert.True(t, p.IsAutoSync())
+	_, _, found := s.LookupTemplate(ctx, "foo/bar")
+	assert.False(t, found)
+
+	require.NoError(t, os.WriteFile(filepath.Join(s.Path(), PolicyFile), []byte(`config:
+  safecontent: true
+maxcliptimeout: 10
+password:
+  length: 32
+template: |
+  {{ .Content }}
+  username:
+required:
+  - username
+autosync: false
+`), 0600))
+
+	p, err = s.Policy(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 32, p.Password.Length)
+	assert.False(t, p.IsAutoSync())
+
+	cfg := config.New()
+	cfg.ClipTimeout = 45
+	pc := p.Apply(cfg)
+	assert.True(t, pc.SafeContent)
+	assert.Equal(t, 10, pc.ClipTimeout)
+	assert.False(t, cfg.SafeContent, "must not modify the local config")
+	assert.Equal(t, []string{"safecontent", "cliptimeout"}, p.Overrides(cfg))
+	cfg.ClipTimeout = 5
+	assert.Equal(t, 5, p.Apply(cfg).ClipTimeout)
+	assert.Equal(t, []string{"safecontent"}, p.Overrides(cfg))
+	assert.True(t, ctxutil.IsShowSafeContent(p.WithContext(ctx)))
+
+	tName, content, found := s.LookupTemplate(ctx, "foo/bar")
+	assert.True(t, found)
+	assert.Equal(t, PolicyFile, tName)
+	assert.Contains(t, string(content), "username:")
+
+	sec := secrets.NewKV()
+	sec.SetPassword("foo")
+	err = s.CheckPolicy(ctx, "foo", sec)
+	assert.True(t, errors.Is(err, ErrPolicyViolation), err)
+	assert.Contains(t, err.Error(), "username")
+	assert.NoError(t, sec.Set("username", "bob"))
+	assert.NoError(t, s.CheckPolicy(ctx, "foo", sec))
+	assert.Error(t, s.CheckPolicy(ctx, "foo", secrets.ParsePlain([]byte("foo\nbar"))))
+
+	// invalid policies are reported
+	require.NoError(t, os.WriteFile(filepath.Join(s.Path(), PolicyFile), []byte("required: foo: bar"), 0600))
+	_, err = s.Policy(ctx)
+	assert.Error(t, err)
+	err = s.CheckPolicy(ctx, "foo", sec)
+	assert.True(t, errors.Is(err, ErrPolicyViolation), err)
+	assert.True(t, s.policy(ctx).IsAutoSync())
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	ctx := context.Background()
+
+	tempdir := t.TempDir()
+	s, err := createSubStore(tempdir)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(s.Path(), PolicyFile), []byte(`password:
+  minlength: 8
+  classes: [lower, digit]
+  forbidden: [password1]
+  folders:
+    prod:
+      minlength: 12
+      minscore: 3
+      classes: [lower, upper, digit, symbol]
+    prod/legacy:
+      minlength: 10
+      enforce: warn
+    dev:
+      allowforce: true
+`), 0600))
+
+	p, err := s.Policy(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 8, p.Password.For("foo").MinLength)
+	assert.Equal(t, 12, p.Password.For("prod/db").MinLength)
+	assert.Equal(t, 10, p.Password.For("prod/legacy/db").MinLength)
+	assert.Equal(t, 8, p.Password.For("production/db").MinLength)
+
+	for _, tc := range []struct {
+		name string
+		pw   string
+		ok   bool
+	}{
+		{"foo", "", true},
+		{"foo", "hunter2", false},
+		{"foo", "hunter22", true},
+		{"foo", "hunterhunter", false},
+		{"foo", "PASSWORD1", false},
+		{"prod/db", "hunter222222", false},
+		{"prod/db", "c0rrect-Horse-battery-St4ple", true},
+		{"prod/legacy/db", "hunter2", true},
+		{"dev/db", "hunter2", false},
+	} {
+		sec := secrets.NewKV()
+		sec.SetPassword(tc.pw)
+		err := s.CheckPolicy(ctx, tc.name, sec)
+		if tc.ok {
+			assert.NoError(t, err, "%s: %s", tc.name, tc.pw)
+			continue
+		}
+		assert.True(t, errors.Is(err, ErrPolicyViolation), "%s: %s", tc.name, tc.pw)
+	}
+
+	// --force is only honored if the policy allows it
+	sec := secrets.NewKV()
+	sec.SetPassword("hunter2")
+	fctx := WithPolicyOverride(ctx, true)
+	assert.NoError(t, s.CheckPolicy(fctx, "dev/db", sec))
+	assert.Error(t, s.CheckPolicy(fctx, "foo", sec))
+
+	// existing passwords are only checked if they change
+	require.NoError(t, s.Set(ctx, "foo", sec))
+	require.NoError(t, sec.Set("username", "bob"))
+	assert.NoError(t, s.CheckPolicy(ctx, "foo", sec))
+	sec.SetPassword("hunter3")
+	assert.Error(t, s.CheckPolicy(ctx, "foo", sec))
+}
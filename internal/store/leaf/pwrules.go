@@ -0,0 +1,70 @@
+package leaf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/pwgen/pwrules"
+	"gopkg.in/yaml.v3"
+)
+
+// PwRulesFile contains the password rules of a store, keyed by domain. The
+// rules use the syntax of pwrules.ParseRule. It's versioned with the store so
+// all members of a team use the same rules for their internal apps.
+const PwRulesFile = ".gopass-pwrules.yml"
+
+// PasswordRules returns the password rules of this store
+func (s *Store) PasswordRules(ctx context.Context) (map[string]string, error) {
+	rules := map[string]string{}
+	if !s.storage.Exists(ctx, PwRulesFile) {
+		return rules, nil
+	}
+	buf, err := s.storage.Get(ctx, PwRulesFile)
+	if err != nil {
+		return rules, fmt.Errorf("failed to read password rules: %w", err)
+	}
+	if err := yaml.Unmarshal(buf, &rules); err != nil {
+		return map[string]string{}, fmt.Errorf("failed to parse password rules %s: %w", PwRulesFile, err)
+	}
+	return rules, nil
+}
+
+// SetPasswordRules (over)writes the password rules of this store. Empty rules
+// remove the file.
+func (s *Store) SetPasswordRules(ctx context.Context, rules map[string]string) error {
+	for domain, rule := range rules {
+		if err := pwrules.ValidateRule(rule); err != nil {
+			return fmt.Errorf("invalid rule for %q: %w", domain, err)
+		}
+	}
+
+	if len(rules) > 0 {
+		buf, err := yaml.Marshal(rules)
+		if err != nil {
+			return err
+		}
+		if err := s.storage.Set(ctx, PwRulesFile, buf); err != nil {
+			return fmt.Errorf("failed to write password rules: %w", err)
+		}
+	} else if s.storage.Exists(ctx, PwRulesFile) {
+		if err := s.storage.Delete(ctx, PwRulesFile); err != nil {
+			return fmt.Errorf("failed to remove password rules: %w", err)
+		}
+	}
+
+	if err := s.storage.Add(ctx, PwRulesFile); err != nil {
+		if errors.Is(err, store.ErrGitNotInit) {
+			return nil
+		}
+		return fmt.Errorf("failed to add %q to git: %w", PwRulesFile, err)
+	}
+
+	if !ctxutil.IsGitCommit(ctx) {
+		return nil
+	}
+
+	return s.gitCommitAndPush(ctx, PwRulesFile)
+}
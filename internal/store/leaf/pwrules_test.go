@@ -0,0 +1,38 @@
+package leaf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordRules(t *testing.T) {
+	ctx := context.Background()
+
+	tempdir := t.TempDir()
+	s, err := createSubStore(tempdir)
+	require.NoError(t, err)
+
+	rules, err := s.PasswordRules(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+
+	want := map[string]string{"intranet.example.com": "minlength: 8; maxlength: 16;"}
+	require.NoError(t, s.SetPasswordRules(ctx, want))
+	rules, err = s.PasswordRules(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, want, rules)
+
+	assert.Error(t, s.SetPasswordRules(ctx, map[string]string{"foo": "bar"}))
+
+	require.NoError(t, s.SetPasswordRules(ctx, nil))
+	assert.NoFileExists(t, filepath.Join(s.Path(), PwRulesFile))
+
+	require.NoError(t, os.WriteFile(filepath.Join(s.Path(), PwRulesFile), []byte("foo: [bar"), 0600))
+	_, err = s.PasswordRules(ctx)
+	assert.Error(t, err)
+}
@@ -36,7 +36,7 @@ func (s *Store) GetRevision(ctx context.Context, name, revision string) (gopass.
 		return nil, fmt.Errorf("failed to get ciphertext of %q@%q: %w", name, revision, err)
 	}
 
-	content, err := s.crypto.Decrypt(ctx, ciphertext)
+	content, err := s.decrypt(ctx, name, ciphertext)
 	if err != nil {
 		debug.Log("Decryption failed: %s", err)
 		return nil, store.ErrDecrypt
@@ -23,7 +23,7 @@ func (s *Store) Get(ctx context.Context, name string) (gopass.Secret, error) {
 		return nil, store.ErrNotFound
 	}
 
-	content, err := s.crypto.Decrypt(ctx, ciphertext)
+	content, err := s.decrypt(ctx, name, ciphertext)
 	if err != nil {
 		out.Errorf(ctx, "Decryption failed: %s\n%s", err, string(content))
 		return nil, store.ErrDecrypt
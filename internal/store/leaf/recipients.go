@@ -57,9 +57,10 @@ func (s *Store) RecipientsTree(ctx context.Context) map[string][]string {
 	return out
 }
 
-// AddRecipient adds a new recipient to the list
+// AddRecipient adds a new recipient to the list. The recipient can also be a
+// reference to a recipient group (e.g. @sre).
 func (s *Store) AddRecipient(ctx context.Context, id string) error {
-	rs, err := s.GetRecipients(ctx, "")
+	rs, err := s.getRawRecipients(ctx, s.idFile(ctx, ""))
 	if err != nil {
 		return fmt.Errorf("failed to read recipient list: %w", err)
 	}
@@ -71,19 +72,33 @@ func (s *Store) AddRecipient(ctx context.Context, id string) error {
 		}
 	}
 
+	if recipients.IsGroup(id) {
+		g, err := s.Groups(ctx)
+		if err != nil {
+			return err
+		}
+		if _, err := g.Expand([]string{id}); err != nil {
+			return err
+		}
+	}
+
 	rs = append(rs, id)
 
 	if err := s.saveRecipients(ctx, rs, "Added Recipient "+id); err != nil {
 		return fmt.Errorf("failed to save recipients: %w", err)
 	}
 
+	if s.IsEnvelope(ctx) {
+		return s.rewrap(ctxutil.WithCommitMessage(ctx, "Added Recipient "+id), s.idFile(ctx, ""))
+	}
+
 	out.Printf(ctx, "Reencrypting existing secrets. This may take some time ...")
 	return s.reencrypt(ctxutil.WithCommitMessage(ctx, "Added Recipient "+id))
 }
 
 // SaveRecipients persists the current recipients on disk
 func (s *Store) SaveRecipients(ctx context.Context) error {
-	rs, err := s.GetRecipients(ctx, "")
+	rs, err := s.getRawRecipients(ctx, s.idFile(ctx, ""))
 	if err != nil {
 		return fmt.Errorf("failed to get recipients: %w", err)
 	}
@@ -104,7 +119,7 @@ func (s *Store) RemoveRecipient(ctx context.Context, id string) error {
 		out.Printf(ctx, "Warning: Failed to get GPG Key Info for %s: %s", id, err)
 	}
 
-	rs, err := s.GetRecipients(ctx, "")
+	rs, err := s.getRawRecipients(ctx, s.idFile(ctx, ""))
 	if err != nil {
 		return fmt.Errorf("failed to read recipient list: %w", err)
 	}
@@ -133,9 +148,44 @@ RECIPIENTS:
 		return fmt.Errorf("failed to save recipients: %w", err)
 	}
 
+	if s.IsEnvelope(ctx) {
+		if err := s.rewrap(ctxutil.WithCommitMessage(ctx, "Removed Recipient "+id), s.idFile(ctx, "")); err != nil {
+			return err
+		}
+		out.Warningf(ctx, "%s might still know the old data key. Run 'gopass rekey' to rotate it.", id)
+		return nil
+	}
+
 	return s.reencrypt(ctxutil.WithCommitMessage(ctx, "Removed Recipient "+id))
 }
 
+// SetFolderRecipients writes a recipients file to the given folder. Any
+// secret in or below this folder will only be encrypted for these recipients.
+// The change is only staged, it will be committed with the next secret
+// written to that folder.
+func (s *Store) SetFolderRecipients(ctx context.Context, dir string, rs []string) error {
+	if len(rs) < 1 {
+		return fmt.Errorf("can not remove all recipients")
+	}
+
+	idf := filepath.Join(strings.TrimPrefix(dir, "/"), s.crypto.IDFile())
+	if err := s.verifyExisting(ctx, idf); err != nil {
+		return err
+	}
+
+	buf := recipients.Marshal(rs)
+	if err := s.storage.Set(ctx, idf, buf); err != nil {
+		return fmt.Errorf("failed to write recipients file: %w", err)
+	}
+
+	if err := s.storage.Add(ctx, idf); err != nil {
+		if !errors.Is(err, store.ErrGitNotInit) {
+			return fmt.Errorf("failed to add file %q to git: %w", idf, err)
+		}
+	}
+	return s.signFile(ctx, idf, buf)
+}
+
 func (s *Store) ensureOurKeyID(ctx context.Context, rs []string) []string {
 	ourID := s.OurKeyID(ctx)
 	if ourID == "" {
@@ -164,17 +214,37 @@ func (s *Store) OurKeyID(ctx context.Context) string {
 }
 
 // GetRecipients will load all Recipients from the .gpg-id file for the given
-// secret path
+// secret path. Any group references are expanded to the group members.
 func (s *Store) GetRecipients(ctx context.Context, name string) ([]string, error) {
 	return s.getRecipients(ctx, s.idFile(ctx, name))
 }
 
 func (s *Store) getRecipients(ctx context.Context, idf string) ([]string, error) {
+	recps, err := s.getRawRecipients(ctx, idf)
+	if err != nil {
+		return nil, err
+	}
+
+	g, err := s.Groups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.Expand(recps)
+}
+
+// getRawRecipients returns the recipients as listed in the given file, i.e.
+// without resolving any group references.
+func (s *Store) getRawRecipients(ctx context.Context, idf string) ([]string, error) {
 	buf, err := s.storage.Get(ctx, idf)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get recipients from %q: %w", idf, err)
 	}
 
+	if err := s.verifyFile(ctx, idf, buf); err != nil {
+		return nil, err
+	}
+
 	recps := recipients.Unmarshal(buf)
 	sort.Strings(recps)
 	return recps, nil
@@ -236,6 +306,10 @@ func (s *Store) saveRecipients(ctx context.Context, rs []string, msg string) err
 	}
 
 	idf := s.idFile(ctx, "")
+	if err := s.verifyExisting(ctx, idf); err != nil {
+		return err
+	}
+
 	buf := recipients.Marshal(rs)
 	if err := s.storage.Set(ctx, idf, buf); err != nil {
 		return fmt.Errorf("failed to write recipients file: %w", err)
@@ -247,6 +321,10 @@ func (s *Store) saveRecipients(ctx context.Context, rs []string, msg string) err
 		}
 	}
 
+	if err := s.signFile(ctx, idf, buf); err != nil {
+		return err
+	}
+
 	if err := s.storage.Commit(ctx, msg); err != nil {
 		if err != store.ErrGitNotInit && err != store.ErrGitNothingToCommit {
 			return fmt.Errorf("failed to commit changes to git: %w", err)
@@ -255,6 +333,11 @@ func (s *Store) saveRecipients(ctx context.Context, rs []string, msg string) err
 
 	// save all recipients public keys to the repo
 	if ctxutil.IsExportKeys(ctx) {
+		if g, err := s.Groups(ctx); err == nil {
+			if ers, err := g.Expand(rs); err == nil {
+				rs = ers
+			}
+		}
 		if _, err := s.ExportMissingPublicKeys(ctx, rs); err != nil {
 			out.Errorf(ctx, "Failed to export missing public keys: %s", err)
 		}
@@ -23,6 +23,12 @@ func (s *Store) reencrypt(ctx context.Context) error {
 		return fmt.Errorf("failed to list store: %w", err)
 	}
 
+	return s.reencryptEntries(ctx, entries)
+}
+
+// reencryptEntries will re-encrypt the given entries for their current
+// recipients
+func (s *Store) reencryptEntries(ctx context.Context, entries []string) error {
 	// TODO: Most gnupg setups don't work well with concurrency > 1, but
 	// for other backends - e.g. age - this could very well be > 1.
 	conc := 1
@@ -0,0 +1,440 @@
+package leaf
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/cache"
+	"github.com/gopasspw/gopass/internal/recipients"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// Recipients files (and the recipient groups) are signed by one of the
+// recipients. Otherwise anyone with write access to the storage could add
+// their own key and would be able to read any secret written afterwards.
+//
+// A signature is only accepted if the signer was a recipient before the
+// change. The recipients seen during the last successful verification are
+// pinned in the local cache. Without a pin (e.g. on a fresh clone) the
+// signature is trusted on first use if it was made by a recipient of the
+// file itself or of the store root.
+
+const (
+	sigExt = ".sig"
+	// pins are refreshed whenever a recipients file changes, they
+	// should not expire in practice
+	trustTTL = 10 * 365 * 24 * time.Hour
+)
+
+// recipientSigner is implemented by crypto backends that support detached
+// signatures, e.g. GPG. Others, like age, leave recipients files unsigned.
+type recipientSigner interface {
+	Sign(ctx context.Context, id string, data []byte) ([]byte, error)
+	Verify(ctx context.Context, data, sig []byte) (string, error)
+}
+
+// SignatureStatus is the result of verifying a single recipients file
+type SignatureStatus struct {
+	File   string
+	Signed bool
+	Signer string
+	Err    error
+}
+
+// String implements fmt.Stringer
+func (s SignatureStatus) String() string {
+	switch {
+	case s.Err != nil:
+		return fmt.Sprintf("%s: %s", s.File, s.Err)
+	case !s.Signed:
+		return fmt.Sprintf("%s: not signed", s.File)
+	default:
+		return fmt.Sprintf("%s: signed by %s", s.File, s.Signer)
+	}
+}
+
+// signedFiles returns all files that should carry a signature
+func (s *Store) signedFiles(ctx context.Context) []string {
+	files, err := s.storage.List(ctx, "")
+	if err != nil {
+		debug.Log("failed to list store: %s", err)
+		return nil
+	}
+
+	fns := make([]string, 0, 2)
+	for _, fn := range files {
+		if filepath.Base(fn) == s.crypto.IDFile() {
+			fns = append(fns, filepath.FromSlash(fn))
+		}
+	}
+	if s.storage.Exists(ctx, groupsFile) {
+		fns = append(fns, groupsFile)
+	}
+	return fns
+}
+
+// VerifyRecipients checks the signatures of all recipients files of this
+// store without modifying anything
+func (s *Store) VerifyRecipients(ctx context.Context) ([]SignatureStatus, error) {
+	if _, ok := s.crypto.(recipientSigner); !ok {
+		return nil, fmt.Errorf("signatures are not supported by the %s backend", s.crypto.Name())
+	}
+
+	res := make([]SignatureStatus, 0, 2)
+	for _, fn := range s.signedFiles(ctx) {
+		st := SignatureStatus{File: fn}
+		buf, err := s.storage.Get(ctx, fn)
+		if err != nil {
+			st.Err = err
+			res = append(res, st)
+			continue
+		}
+		st.Signer, st.Signed, st.Err = s.checkSignature(ctx, fn, buf)
+		res = append(res, st)
+	}
+	return res, nil
+}
+
+// SignRecipients signs all recipients files of this store with our key. Use
+// this to accept changes after reviewing them.
+func (s *Store) SignRecipients(ctx context.Context) error {
+	if _, ok := s.crypto.(recipientSigner); !ok {
+		return fmt.Errorf("signatures are not supported by the %s backend", s.crypto.Name())
+	}
+
+	for _, fn := range s.signedFiles(ctx) {
+		buf, err := s.storage.Get(ctx, fn)
+		if err != nil {
+			return fmt.Errorf("failed to read %q: %w", fn, err)
+		}
+		if err := s.signFile(ctx, fn, buf); err != nil {
+			return err
+		}
+	}
+
+	if err := s.storage.Commit(ctx, "Signed recipients"); err != nil {
+		if !errors.Is(err, store.ErrGitNotInit) && !errors.Is(err, store.ErrGitNothingToCommit) {
+			return fmt.Errorf("failed to commit changes to git: %w", err)
+		}
+	}
+	return nil
+}
+
+// signFile signs the given content of a recipients file and stages the
+// signature. It does nothing if the crypto backend does not support signing.
+func (s *Store) signFile(ctx context.Context, fn string, buf []byte) error {
+	sg, ok := s.crypto.(recipientSigner)
+	if !ok {
+		debug.Log("not signing %s, not supported by %T", fn, s.crypto)
+		return nil
+	}
+
+	id := s.signingKey(ctx, fn, buf)
+	if id == "" {
+		debug.Log("not signing %s, none of its signers is available", fn)
+		return nil
+	}
+
+	// once the root is signed unsigned files are rejected, so we sign all
+	// existing recipients files when a store starts using signatures
+	root := s.idFile(ctx, "")
+	adopt := fn == root && !s.storage.Exists(ctx, root+sigExt)
+
+	sig, err := sg.Sign(ctx, id, buf)
+	if err != nil {
+		return fmt.Errorf("failed to sign %q: %w", fn, err)
+	}
+	if err := s.storage.Set(ctx, fn+sigExt, sig); err != nil {
+		return fmt.Errorf("failed to write signature for %q: %w", fn, err)
+	}
+	if err := s.storage.Add(ctx, fn+sigExt); err != nil {
+		if !errors.Is(err, store.ErrGitNotInit) {
+			return fmt.Errorf("failed to add %q to git: %w", fn+sigExt, err)
+		}
+	}
+
+	s.pin(ctx, fn, buf)
+	s.markVerified(fn, buf, sig)
+
+	if !adopt {
+		return nil
+	}
+	for _, other := range s.signedFiles(ctx) {
+		if other == root || s.storage.Exists(ctx, other+sigExt) {
+			continue
+		}
+		obuf, err := s.storage.Get(ctx, other)
+		if err != nil {
+			return fmt.Errorf("failed to read %q: %w", other, err)
+		}
+		if err := s.signFile(ctx, other, obuf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// signingKey returns the first of our identities that may sign the given file.
+// Recipients of the enclosing file are preferred, because only their
+// signatures are accepted by stores that never verified this file before.
+func (s *Store) signingKey(ctx context.Context, fn string, buf []byte) string {
+	for _, r := range append(s.firstUseSigners(ctx, fn, buf), s.signersFor(ctx, fn, buf)...) {
+		kl, err := s.crypto.FindIdentities(ctx, r)
+		if err != nil || len(kl) < 1 {
+			continue
+		}
+		return kl[0]
+	}
+	return ""
+}
+
+// verifyFile makes sure the given content of a recipients file is properly
+// signed. Successful verifications are cached and pinned.
+func (s *Store) verifyFile(ctx context.Context, fn string, buf []byte) error {
+	if _, ok := s.crypto.(recipientSigner); !ok {
+		return nil
+	}
+
+	sig, _ := s.storage.Get(ctx, fn+sigExt)
+	if s.isVerified(fn, buf, sig) {
+		return nil
+	}
+
+	signer, signed, err := s.checkSignature(ctx, fn, buf)
+	if err != nil {
+		return err
+	}
+	if !signed {
+		return nil
+	}
+
+	debug.Log("%s signed by %s", fn, signer)
+	// mark it first, pinning may verify other files that depend on this one
+	s.markVerified(fn, buf, sig)
+	s.pin(ctx, fn, buf)
+	return nil
+}
+
+// verifyExisting makes sure we don't overwrite a recipients file that has
+// been tampered with
+func (s *Store) verifyExisting(ctx context.Context, fn string) error {
+	if !s.storage.Exists(ctx, fn) {
+		return nil
+	}
+	buf, err := s.storage.Get(ctx, fn)
+	if err != nil {
+		return fmt.Errorf("failed to read %q: %w", fn, err)
+	}
+	return s.verifyFile(ctx, fn, buf)
+}
+
+// checkSignature verifies the signature of a recipients file and returns the
+// signer. Unsigned files are only accepted as long as this store (or this
+// file) was never signed before.
+func (s *Store) checkSignature(ctx context.Context, fn string, buf []byte) (string, bool, error) {
+	sg, ok := s.crypto.(recipientSigner)
+	if !ok {
+		return "", false, nil
+	}
+
+	pinned, hasPin := s.pinned(fn)
+
+	sig, err := s.storage.Get(ctx, fn+sigExt)
+	if err != nil {
+		if hasPin || s.storage.Exists(ctx, s.idFile(ctx, "")+sigExt) {
+			return "", false, fmt.Errorf("%s is not signed: %w", fn, store.ErrInvalidSignature)
+		}
+		return "", false, nil
+	}
+
+	signer, err := sg.Verify(ctx, buf, sig)
+	if err != nil {
+		return "", true, fmt.Errorf("%s has a bad signature (%s): %w", fn, err, store.ErrInvalidSignature)
+	}
+
+	trusted := pinned
+	if !hasPin {
+		trusted = s.firstUseSigners(ctx, fn, buf)
+	}
+	if !matchesAny(signer, trusted) {
+		return signer, true, fmt.Errorf("%s was signed by %s who is not a trusted recipient: %w", fn, signer, store.ErrInvalidSignature)
+	}
+
+	return signer, true, nil
+}
+
+// signersFor returns the recipients that may sign the given content of a
+// recipients file: the recipients listed in it and those of the store root
+func (s *Store) signersFor(ctx context.Context, fn string, buf []byte) []string {
+	var ids []string
+	if fn == groupsFile {
+		if g, err := recipients.UnmarshalGroups(buf); err == nil {
+			for _, n := range g.Names() {
+				ids = append(ids, g[n]...)
+			}
+		}
+	} else {
+		ids = recipients.Unmarshal(buf)
+		if g, err := s.Groups(ctx); err == nil {
+			if ex, err := g.Expand(ids); err == nil {
+				ids = ex
+			}
+		}
+	}
+
+	if root := s.idFile(ctx, ""); fn != root {
+		if rbuf, err := s.storage.Get(ctx, root); err == nil {
+			ids = append(ids, recipients.Unmarshal(rbuf)...)
+		}
+	}
+
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if recipients.IsGroup(id) || contains(out, id) {
+			continue
+		}
+		out = append(out, id)
+	}
+	return fingerprints(ctx, s.crypto, out)
+}
+
+// firstUseSigners returns the recipients that may sign a recipients file this
+// store never verified before: those of the enclosing recipients file, which
+// must be valid itself. Keys listed in the file are never trusted, otherwise
+// anybody could sign a new folder for themselves. Only the recipients file of
+// the store root has no enclosing file, it's trusted on first use.
+func (s *Store) firstUseSigners(ctx context.Context, fn string, buf []byte) []string {
+	root := s.idFile(ctx, "")
+	if fn == root {
+		return s.signersFor(ctx, fn, buf)
+	}
+
+	if fn == groupsFile {
+		// the root is not verified while it's trusted on first use, its
+		// signers might depend on the groups
+		if _, hasPin := s.pinned(root); !hasPin {
+			rbuf, err := s.storage.Get(ctx, root)
+			if err != nil {
+				return nil
+			}
+			return fingerprints(ctx, s.crypto, recipients.Unmarshal(rbuf))
+		}
+		ids, err := s.getRawRecipients(ctx, root)
+		if err != nil {
+			debug.Log("not trusting any signer of %s: %s", fn, err)
+			return nil
+		}
+		return fingerprints(ctx, s.crypto, ids)
+	}
+
+	encl := s.idFile(ctx, filepath.Dir(filepath.Dir(fn)))
+	ids, err := s.getRawRecipients(ctx, encl)
+	if err != nil {
+		debug.Log("not trusting any signer of %s: %s", fn, err)
+		return nil
+	}
+	if g, err := s.Groups(ctx); err == nil {
+		if ex, err := g.Expand(ids); err == nil {
+			ids = ex
+		}
+	}
+	return fingerprints(ctx, s.crypto, ids)
+}
+
+// matchesAny returns true if the signer matches one of the given IDs. The IDs
+// might be key IDs, which are a suffix of the signers fingerprint.
+func matchesAny(signer string, ids []string) bool {
+	signer = normalizeKeyID(signer)
+	for _, id := range ids {
+		id = normalizeKeyID(id)
+		if len(id) < 8 || len(signer) < 8 {
+			if id == signer {
+				return true
+			}
+			continue
+		}
+		if strings.HasSuffix(signer, id) || strings.HasSuffix(id, signer) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeKeyID(id string) string {
+	return strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(id)), "0X")
+}
+
+func (s *Store) trustCache() *cache.OnDisk {
+	s.sigMu.Lock()
+	defer s.sigMu.Unlock()
+
+	if s.trust != nil {
+		return s.trust
+	}
+	od, err := cache.NewOnDisk("recipients-trust", trustTTL)
+	if err != nil {
+		debug.Log("failed to init trust cache: %s", err)
+		return nil
+	}
+	s.trust = od
+	return od
+}
+
+func (s *Store) pinKey(fn string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(s.path+"\x00"+fn)))
+}
+
+// pinned returns the recipients that were trusted to sign the given file the
+// last time it was verified
+func (s *Store) pinned(fn string) ([]string, bool) {
+	tc := s.trustCache()
+	if tc == nil {
+		return nil, false
+	}
+	ids, err := tc.Get(s.pinKey(fn))
+	if err != nil {
+		return nil, false
+	}
+	return ids, true
+}
+
+func (s *Store) pin(ctx context.Context, fn string, buf []byte) {
+	tc := s.trustCache()
+	if tc == nil {
+		return
+	}
+	if err := tc.Set(s.pinKey(fn), s.signersFor(ctx, fn, buf)); err != nil {
+		debug.Log("failed to pin signers for %s: %s", fn, err)
+	}
+}
+
+func sigChecksum(buf, sig []byte) string {
+	h := sha256.New()
+	_, _ = h.Write(buf)
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write(sig)
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+func (s *Store) isVerified(fn string, buf, sig []byte) bool {
+	s.sigMu.Lock()
+	defer s.sigMu.Unlock()
+
+	return s.verified[fn] == sigChecksum(buf, sig)
+}
+
+func (s *Store) markVerified(fn string, buf, sig []byte) {
+	s.sigMu.Lock()
+	defer s.sigMu.Unlock()
+
+	if s.verified == nil {
+		s.verified = make(map[string]string, 1)
+	}
+	s.verified[fn] = sigChecksum(buf, sig)
+}
@@ -0,0 +1,157 @@
+package leaf
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	plain "github.com/gopasspw/gopass/internal/backend/crypto/plain"
+	"github.com/gopasspw/gopass/internal/backend/storage/fs"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchesAny(t *testing.T) {
+	for _, tc := range []struct {
+		signer string
+		ids    []string
+		ok     bool
+	}{
+		{"000000000000000000000000DEADBEEF", []string{"0xDEADBEEF"}, true},
+		{"000000000000000000000000DEADBEEF", []string{"deadbeef"}, true},
+		{"000000000000000000000000DEADBEEF", []string{"0xFEEDBEEF"}, false},
+		{"john.doe", []string{"john.doe"}, true},
+		{"john", []string{"john.doe"}, false},
+		{"beef", []string{"0xDEADBEEF"}, false},
+	} {
+		assert.Equal(t, tc.ok, matchesAny(tc.signer, tc.ids), "%s %v", tc.signer, tc.ids)
+	}
+}
+
+func TestSignedRecipients(t *testing.T) {
+	ctx := context.Background()
+	ctx = ctxutil.WithExportKeys(ctx, false)
+
+	obuf := &bytes.Buffer{}
+	out.Stdout = obuf
+	defer func() {
+		out.Stdout = os.Stdout
+	}()
+
+	tempdir, err := os.MkdirTemp("", "gopass-")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(tempdir)
+	}()
+	require.NoError(t, os.Setenv("GOPASS_HOMEDIR", tempdir))
+	defer func() {
+		_ = os.Unsetenv("GOPASS_HOMEDIR")
+	}()
+
+	storeDir := filepath.Join(tempdir, "store")
+	newStore := func() *Store {
+		return &Store{
+			alias:   "",
+			path:    storeDir,
+			crypto:  plain.New(),
+			storage: fs.New(storeDir),
+		}
+	}
+	s := newStore()
+
+	// 0xDEADBEEF is one of our identities, so it's signed
+	require.NoError(t, s.saveRecipients(ctx, []string{"0xDEADBEEF"}, "test"))
+	assert.FileExists(t, filepath.Join(storeDir, plain.IDFile+sigExt))
+
+	rs, err := s.GetRecipients(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0xDEADBEEF"}, rs)
+
+	sec := &secrets.Plain{}
+	sec.SetPassword("foo")
+	require.NoError(t, s.Set(ctx, "foo", sec))
+
+	// a subfolder is signed by a root recipient
+	require.NoError(t, s.SetFolderRecipients(ctx, "team", []string{"jane.doe"}))
+	assert.FileExists(t, filepath.Join(storeDir, "team", plain.IDFile+sigExt))
+	rs, err = s.GetRecipients(ctx, "team/foo")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"jane.doe"}, rs)
+
+	st, err := s.VerifyRecipients(ctx)
+	require.NoError(t, err)
+	require.Len(t, st, 2)
+	for _, v := range st {
+		assert.NoError(t, v.Err, v.File)
+		assert.True(t, v.Signed, v.File)
+		assert.Equal(t, "0xDEADBEEF", v.Signer, v.File)
+	}
+
+	idf := filepath.Join(storeDir, plain.IDFile)
+	orig, err := os.ReadFile(idf)
+	require.NoError(t, err)
+
+	// somebody injects their key
+	injected := []byte("0xBADC0FFE\n0xDEADBEEF\n")
+	require.NoError(t, os.WriteFile(idf, injected, 0600))
+
+	s = newStore()
+	_, err = s.GetRecipients(ctx, "")
+	assert.ErrorIs(t, err, store.ErrInvalidSignature)
+	assert.ErrorIs(t, s.Set(ctx, "bar", sec), store.ErrInvalidSignature)
+	assert.ErrorIs(t, s.AddRecipient(ctx, "0xFEEDBEEF"), store.ErrInvalidSignature)
+
+	st, err = s.VerifyRecipients(ctx)
+	require.NoError(t, err)
+	assert.ErrorIs(t, st[0].Err, store.ErrInvalidSignature)
+
+	// and signs it with their own key
+	badSig := fmt.Sprintf("plain-signature 0xBADC0FFE %x\n", sha256.Sum256(injected))
+	require.NoError(t, os.WriteFile(idf+sigExt, []byte(badSig), 0600))
+	_, err = s.GetRecipients(ctx, "")
+	assert.ErrorIs(t, err, store.ErrInvalidSignature)
+
+	// or removes the signature altogether
+	require.NoError(t, os.Remove(idf+sigExt))
+	_, err = s.GetRecipients(ctx, "")
+	assert.ErrorIs(t, err, store.ErrInvalidSignature)
+
+	// a legitimate change can be signed by an existing recipient
+	require.NoError(t, os.WriteFile(idf, append(orig, []byte("0xFEEDBEEF\n")...), 0600))
+	require.NoError(t, s.SignRecipients(ctx))
+	s = newStore()
+	rs, err = s.GetRecipients(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0xDEADBEEF", "0xFEEDBEEF"}, rs)
+	require.NoError(t, s.Set(ctx, "bar", sec))
+
+	// a new folder signed by a key that is only listed in the folder itself
+	// is rejected
+	pdir := filepath.Join(storeDir, "prod")
+	require.NoError(t, os.MkdirAll(pdir, 0700))
+	evil := []byte("0xBADC0FFE\n")
+	require.NoError(t, os.WriteFile(filepath.Join(pdir, plain.IDFile), evil, 0600))
+	evilSig := fmt.Sprintf("plain-signature 0xBADC0FFE %x\n", sha256.Sum256(evil))
+	require.NoError(t, os.WriteFile(filepath.Join(pdir, plain.IDFile+sigExt), []byte(evilSig), 0600))
+	s = newStore()
+	_, err = s.GetRecipients(ctx, "prod/db")
+	assert.ErrorIs(t, err, store.ErrInvalidSignature)
+	assert.ErrorIs(t, s.Set(ctx, "prod/db", sec), store.ErrInvalidSignature)
+
+	// but the same folder signed by a root recipient is fine
+	require.NoError(t, os.Remove(filepath.Join(pdir, plain.IDFile+sigExt)))
+	require.NoError(t, s.SignRecipients(ctx))
+	s = newStore()
+	rs, err = s.GetRecipients(ctx, "prod/db")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0xBADC0FFE"}, rs)
+}
@@ -6,8 +6,10 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/cache"
 	"github.com/gopasspw/gopass/pkg/debug"
 )
 
@@ -17,6 +19,13 @@ type Store struct {
 	path    string
 	crypto  backend.Crypto
 	storage backend.Storage
+
+	krMu     sync.Mutex
+	keyrings map[string]cachedKeyring
+
+	sigMu    sync.Mutex
+	verified map[string]string
+	trust    *cache.OnDisk
 }
 
 // Init initializes this sub store
@@ -39,6 +39,10 @@ func (s *Store) LookupTemplate(ctx context.Context, name string) (string, []byte
 			}
 		}
 	}
+	if p := s.policy(ctx); p.Template != "" {
+		debug.Log("Using policy template for %q", oName)
+		return PolicyFile, []byte(p.Template), true
+	}
 	return "", []byte{}, false
 }
 
@@ -20,22 +20,54 @@ func (s *Store) Set(ctx context.Context, name string, sec gopass.Byter) error {
 		return fmt.Errorf("invalid secret name: %s", name)
 	}
 
-	p := s.passfile(name)
+	if s.IsEnvelope(ctx) {
+		ciphertext, err := s.sealFor(ctx, name, sec.Bytes())
+		if err != nil {
+			debug.Log("Failed encrypt secret: %s", err)
+			return store.ErrEncrypt
+		}
+		return s.write(ctx, name, ciphertext)
+	}
 
 	recipients, err := s.useableKeys(ctx, name)
 	if err != nil {
-		return fmt.Errorf("failed to list useable keys for %q: %w", p, err)
+		return fmt.Errorf("failed to list useable keys for %q: %w", s.passfile(name), err)
 	}
 
 	// make sure the encryptor can decrypt later
 	recipients = s.ensureOurKeyID(ctx, recipients)
 
+	return s.encryptAndWrite(ctx, name, sec, recipients)
+}
+
+// SetFor encrypts one entry for exactly the given recipients. Unlike Set it
+// does not add our own key and never uses a data key, so the entry might not
+// be readable by us. Use
+// SetFolderRecipients to make sure future re-encryptions honor this.
+func (s *Store) SetFor(ctx context.Context, name string, sec gopass.Byter, recipients []string) error {
+	if strings.Contains(name, "//") {
+		return fmt.Errorf("invalid secret name: %s", name)
+	}
+	if len(recipients) < 1 {
+		return fmt.Errorf("no recipients given for %q", name)
+	}
+
+	return s.encryptAndWrite(ctx, name, sec, recipients)
+}
+
+func (s *Store) encryptAndWrite(ctx context.Context, name string, sec gopass.Byter, recipients []string) error {
 	ciphertext, err := s.crypto.Encrypt(ctx, sec.Bytes(), recipients)
 	if err != nil {
 		debug.Log("Failed encrypt secret: %s", err)
 		return store.ErrEncrypt
 	}
 
+	return s.write(ctx, name, ciphertext)
+}
+
+func (s *Store) write(ctx context.Context, name string, ciphertext []byte) error {
+	p := s.passfile(name)
+
 	if err := s.storage.Set(ctx, p, ciphertext); err != nil {
 		return fmt.Errorf("failed to write secret: %w", err)
 	}
@@ -79,6 +111,11 @@ func (s *Store) gitCommitAndPush(ctx context.Context, name string) error {
 		}
 	}
 
+	if !s.policy(ctx).IsAutoSync() {
+		debug.Log("commitAndPush - skipping git push - disabled by policy")
+		return nil
+	}
+
 	debug.Log("syncing with remote ...")
 	if err := s.storage.Push(ctx, "", ""); err != nil {
 		if errors.Is(err, store.ErrGitNotInit) {
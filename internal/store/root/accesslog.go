@@ -0,0 +1,46 @@
+package root
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/gopasspw/gopass/internal/store/leaf"
+)
+
+// AccessLog returns the verified access log entries of all mounted stores.
+// Names are relative to the root store. If the hash chain of any log is broken
+// the entries are returned together with leaf.ErrAccessLogTampered.
+func (r *Store) AccessLog(ctx context.Context) ([]leaf.AccessEntry, error) {
+	if r.cfg.AccessLog == "" {
+		return nil, fmt.Errorf("access log is disabled, set accesslog to %q or %q", leaf.AccessLogLocal, leaf.AccessLogStore)
+	}
+
+	var entries []leaf.AccessEntry
+	var tampered error
+
+	stores := map[string]*leaf.Store{"": r.store}
+	for alias, sub := range r.mounts {
+		stores[alias] = sub
+	}
+	for alias, sub := range stores {
+		es, err := sub.AccessLog(ctx, r.cfg.AccessLog)
+		if err != nil {
+			if !errors.Is(err, leaf.ErrAccessLogTampered) {
+				return nil, fmt.Errorf("failed to read access log of %q: %w", alias, err)
+			}
+			tampered = err
+		}
+		for _, e := range es {
+			e.Name = path.Join(alias, e.Name)
+			entries = append(entries, e)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.Before(entries[j].Time)
+	})
+	return entries, tampered
+}
@@ -16,3 +16,15 @@ func (r *Store) Crypto(ctx context.Context, name string) backend.Crypto {
 	}
 	return sub.Crypto()
 }
+
+// IsEnvelope returns true if the given store uses envelope encryption
+func (r *Store) IsEnvelope(ctx context.Context, store string) bool {
+	sub, _ := r.getStore(store)
+	return sub.IsEnvelope(ctx)
+}
+
+// Rekey rotates the data keys of the given store and re-encrypts all secrets
+func (r *Store) Rekey(ctx context.Context, store string) error {
+	sub, _ := r.getStore(store)
+	return sub.Rekey(ctx)
+}
@@ -2,9 +2,13 @@ package root
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store/leaf"
 	"github.com/gopasspw/gopass/pkg/debug"
 	multierror "github.com/hashicorp/go-multierror"
 )
@@ -34,3 +38,30 @@ func (s *Store) Fsck(ctx context.Context, path string) error {
 
 	return result
 }
+
+// CheckRecipientKeys checks the recipient keys of all mounted stores. See
+// leaf.Store.CheckRecipientKeys for details.
+func (s *Store) CheckRecipientKeys(ctx context.Context, within time.Duration) ([]leaf.KeyIssue, error) {
+	var result error
+	issues, err := s.store.CheckRecipientKeys(ctx, within)
+	if err != nil {
+		result = multierror.Append(result, err)
+	}
+
+	mps := s.MountPoints()
+	sort.Strings(mps)
+	for _, alias := range mps {
+		sub := s.mounts[alias]
+		if sub == nil {
+			continue
+		}
+		is, err := sub.CheckRecipientKeys(ctx, within)
+		if err != nil {
+			result = multierror.Append(result, fmt.Errorf("[%s] %w", alias, err))
+			continue
+		}
+		issues = append(issues, is...)
+	}
+
+	return issues, result
+}
@@ -93,6 +93,7 @@ func (r *Store) RemoveMount(ctx context.Context, alias string) error {
 	}
 	delete(r.mounts, alias)
 	delete(r.cfg.Mounts, alias)
+	delete(r.cfg.Overrides, alias)
 	return nil
 }
 
@@ -2,9 +2,14 @@ package root
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/internal/store/leaf"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
 	"github.com/gopasspw/gopass/tests/gptest"
 
 	"github.com/stretchr/testify/assert"
@@ -36,3 +41,54 @@ func TestMount(t *testing.T) {
 	// removing mounts should never fail
 	assert.NoError(t, rs.RemoveMount(ctx, "foo"))
 }
+
+func TestMountConfig(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithHidden(ctx, true)
+
+	rs, err := createRootStore(ctx, u)
+	require.NoError(t, err)
+	rs.cfg.ClipTimeout = 45
+
+	require.NoError(t, u.InitStore("team"))
+	require.NoError(t, rs.AddMount(ctx, "team", u.StoreDir("team")))
+
+	safe := true
+	timeout := 10
+	rs.cfg.Overrides = map[string]*config.MountConfig{
+		"team": {SafeContent: &safe, ClipTimeout: &timeout},
+	}
+
+	assert.Equal(t, 10, rs.Config(ctx, "team/foo").ClipTimeout)
+	assert.True(t, rs.Config(ctx, "team/foo").SafeContent)
+	assert.Equal(t, 45, rs.Config(ctx, "foo").ClipTimeout)
+	assert.True(t, ctxutil.IsShowSafeContent(rs.WithMountContext(ctx, "team/foo")))
+	assert.False(t, ctxutil.IsShowSafeContent(rs.WithMountContext(ctx, "foo")))
+
+	// the store policy takes precedence over mount overrides
+	require.NoError(t, os.WriteFile(filepath.Join(u.StoreDir("team"), leaf.PolicyFile), []byte("config:\n  cliptimeout: 5\nrequired: [username]\n"), 0600))
+	assert.Equal(t, 5, rs.Config(ctx, "team/foo").ClipTimeout)
+	assert.Equal(t, 45, rs.Config(ctx, "foo").ClipTimeout)
+
+	sec := secrets.NewKV()
+	sec.SetPassword("bar")
+	assert.Error(t, rs.CheckPolicy(ctx, "team/foo", sec))
+	assert.NoError(t, rs.CheckPolicy(ctx, "foo", sec))
+	// internal writes are not checked
+	assert.NoError(t, rs.Set(ctx, "team/bar", sec))
+	assert.NoError(t, sec.Set("username", "bob"))
+	assert.NoError(t, rs.CheckPolicy(ctx, "team/foo", sec))
+
+	p, err := rs.Policy(ctx, "team")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"username"}, p.Required)
+	_, err = rs.Policy(ctx, "other")
+	assert.Error(t, err)
+
+	assert.NoError(t, rs.RemoveMount(ctx, "team"))
+	assert.Len(t, rs.cfg.Overrides, 0)
+}
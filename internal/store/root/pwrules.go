@@ -0,0 +1,50 @@
+package root
+
+import (
+	"context"
+
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// PasswordRules returns the password rules of the given mount point
+func (r *Store) PasswordRules(ctx context.Context, alias string) (map[string]string, error) {
+	store, err := r.GetSubStore(alias)
+	if err != nil {
+		return nil, err
+	}
+	if store == nil {
+		return map[string]string{}, nil
+	}
+	return store.PasswordRules(ctx)
+}
+
+// SetPasswordRules (over)writes the password rules of the given mount point
+func (r *Store) SetPasswordRules(ctx context.Context, alias string, rules map[string]string) error {
+	store, err := r.GetSubStore(alias)
+	if err != nil {
+		return err
+	}
+	return store.SetPasswordRules(ctx, rules)
+}
+
+// AllPasswordRules returns the password rules of all stores. Rules of more
+// specific mount points take precedence. Invalid rule files are ignored.
+func (r *Store) AllPasswordRules(ctx context.Context) map[string]string {
+	mps := r.MountPoints()
+	all := map[string]string{}
+	for i := len(mps); i >= 0; i-- {
+		alias := ""
+		if i < len(mps) {
+			alias = mps[i]
+		}
+		rules, err := r.PasswordRules(ctx, alias)
+		if err != nil {
+			debug.Log("ignoring password rules of %q: %s", alias, err)
+			continue
+		}
+		for k, v := range rules {
+			all[k] = v
+		}
+	}
+	return all
+}
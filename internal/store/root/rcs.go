@@ -2,6 +2,7 @@ package root
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/gopasspw/gopass/internal/backend"
 	"github.com/gopasspw/gopass/internal/out"
@@ -55,9 +56,19 @@ func (r *Store) ListRevisions(ctx context.Context, name string) ([]backend.Revis
 
 // GetRevision will try to retrieve the given revision from the sync backend
 func (r *Store) GetRevision(ctx context.Context, name, revision string) (context.Context, gopass.Secret, error) {
-	store, name := r.getStore(name)
-	sec, err := store.GetRevision(ctx, name, revision)
-	return ctx, sec, err
+	store, sname := r.getStore(name)
+	sec, err := store.GetRevision(ctx, sname, revision)
+	if err != nil {
+		return ctx, sec, err
+	}
+
+	// old revisions expose the secret just like Get does
+	if action := ctxutil.GetAccessAction(ctx); action != "" && r.cfg.AccessLog != "" {
+		if err := store.LogAccess(ctx, r.cfg.AccessLog, action, sname); err != nil {
+			return ctx, nil, fmt.Errorf("failed to record access to %s: %w", name, err)
+		}
+	}
+	return ctx, sec, nil
 }
 
 // RCSStatus show the git status
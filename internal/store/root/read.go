@@ -2,14 +2,26 @@ package root
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/gopass"
 )
 
 // Get returns the plaintext of a single key
 func (r *Store) Get(ctx context.Context, name string) (gopass.Secret, error) {
 	// forward to substore
-	store, name := r.getStore(name)
-	sec, err := store.Get(ctx, name)
-	return sec, err
+	store, sname := r.getStore(name)
+	sec, err := store.Get(ctx, sname)
+	if err != nil {
+		return sec, err
+	}
+
+	// only record reads that expose the secret to the user
+	if action := ctxutil.GetAccessAction(ctx); action != "" && r.cfg.AccessLog != "" {
+		if err := store.LogAccess(ctx, r.cfg.AccessLog, action, sname); err != nil {
+			return nil, fmt.Errorf("failed to record access to %s: %w", name, err)
+		}
+	}
+	return sec, nil
 }
@@ -7,7 +7,9 @@ import (
 	"strings"
 
 	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/recipients"
 	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/internal/store/leaf"
 	"github.com/gopasspw/gopass/internal/tree"
 	"github.com/gopasspw/gopass/pkg/debug"
 
@@ -32,6 +34,42 @@ func (r *Store) RemoveRecipient(ctx context.Context, store, rec string) error {
 	return sub.RemoveRecipient(ctx, rec)
 }
 
+// SetFolderRecipients writes a recipients file to the given folder
+func (r *Store) SetFolderRecipients(ctx context.Context, dir string, rs []string) error {
+	sub, dir := r.getStore(dir)
+	return sub.SetFolderRecipients(ctx, dir, rs)
+}
+
+// ListGroups returns the recipient groups defined in the given store
+func (r *Store) ListGroups(ctx context.Context, store string) (recipients.Groups, error) {
+	sub, _ := r.getStore(store)
+	return sub.Groups(ctx)
+}
+
+// AddGroupMembers adds recipients to a group in the given store
+func (r *Store) AddGroupMembers(ctx context.Context, store, group string, ids ...string) error {
+	sub, _ := r.getStore(store)
+	return sub.AddGroupMembers(ctx, group, ids...)
+}
+
+// RemoveGroupMembers removes recipients from a group in the given store
+func (r *Store) RemoveGroupMembers(ctx context.Context, store, group string, ids ...string) error {
+	sub, _ := r.getStore(store)
+	return sub.RemoveGroupMembers(ctx, group, ids...)
+}
+
+// VerifyRecipients checks the signatures of the recipients files of the given store
+func (r *Store) VerifyRecipients(ctx context.Context, store string) ([]leaf.SignatureStatus, error) {
+	sub, _ := r.getStore(store)
+	return sub.VerifyRecipients(ctx)
+}
+
+// SignRecipients signs the recipients files of the given store
+func (r *Store) SignRecipients(ctx context.Context, store string) error {
+	sub, _ := r.getStore(store)
+	return sub.SignRecipients(ctx)
+}
+
 func (r *Store) addRecipient(ctx context.Context, prefix string, root *tree.Root, recp string, pretty bool) error {
 	sub, _ := r.getStore(prefix)
 	key := fmt.Sprintf("%s (missing public key)", recp)
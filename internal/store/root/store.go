@@ -7,6 +7,7 @@ import (
 
 	"github.com/gopasspw/gopass/internal/backend"
 	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/internal/store/leaf"
 )
 
@@ -35,6 +36,47 @@ func (r *Store) WithContext(ctx context.Context) context.Context {
 	return r.cfg.WithContext(ctx)
 }
 
+// Config returns the config for the given secret, i.e. the global config with
+// the overrides of its mount and the policy of its store applied
+func (r *Store) Config(ctx context.Context, name string) *config.Config {
+	store, _ := r.getStore(name)
+	cfg := r.cfg.ForMount(r.MountPoint(name))
+	if store == nil {
+		return cfg
+	}
+	return policy(ctx, store).Apply(cfg)
+}
+
+// WithMountContext populates the context with the overrides of the mount the
+// given secret belongs to and the policy of its store
+func (r *Store) WithMountContext(ctx context.Context, name string) context.Context {
+	ctx = r.cfg.WithMountContext(ctx, r.MountPoint(name))
+	if store, _ := r.getStore(name); store != nil {
+		ctx = policy(ctx, store).WithContext(ctx)
+	}
+	return ctx
+}
+
+func policy(ctx context.Context, store *leaf.Store) *leaf.Policy {
+	p, err := store.Policy(ctx)
+	if err != nil {
+		out.Warningf(ctx, "Ignoring invalid policy of store %q: %s", store.Alias(), err)
+	}
+	return p
+}
+
+// Policy returns the policy of the store mounted at alias
+func (r *Store) Policy(ctx context.Context, alias string) (*leaf.Policy, error) {
+	store, err := r.GetSubStore(alias)
+	if err != nil {
+		return nil, err
+	}
+	if store == nil {
+		return &leaf.Policy{}, nil
+	}
+	return store.Policy(ctx)
+}
+
 // Exists checks the existence of a single entry
 func (r *Store) Exists(ctx context.Context, name string) bool {
 	store, name := r.getStore(name)
@@ -53,6 +53,20 @@ func (r *Store) TemplateTree(ctx context.Context) (*tree.Root, error) {
 	return root, nil
 }
 
+// AllTemplates returns the names of the templates of all stores
+func (r *Store) AllTemplates(ctx context.Context) []string {
+	tpls := r.store.ListTemplates(ctx, "")
+	for _, alias := range r.MountPoints() {
+		substore := r.mounts[alias]
+		if substore == nil {
+			continue
+		}
+		tpls = append(tpls, substore.ListTemplates(ctx, alias)...)
+	}
+	sort.Strings(tpls)
+	return tpls
+}
+
 // HasTemplate returns true if the template exists
 func (r *Store) HasTemplate(ctx context.Context, name string) bool {
 	store, name := r.getStore(name)
@@ -35,6 +35,7 @@ func TestTemplate(t *testing.T) {
 
 	assert.NoError(t, rs.SetTemplate(ctx, "foo", []byte("foobar")))
 	assert.True(t, rs.HasTemplate(ctx, "foo"))
+	assert.Equal(t, []string{"foo"}, rs.AllTemplates(ctx))
 
 	b, err := rs.GetTemplate(ctx, "foo")
 	require.NoError(t, err)
@@ -11,3 +11,17 @@ func (r *Store) Set(ctx context.Context, name string, sec gopass.Byter) error {
 	store, name := r.getStore(name)
 	return store.Set(ctx, name, sec)
 }
+
+// SetFor encrypts one entry for exactly the given recipients
+func (r *Store) SetFor(ctx context.Context, name string, sec gopass.Byter, recipients []string) error {
+	store, name := r.getStore(name)
+	return store.SetFor(ctx, name, sec, recipients)
+}
+
+// CheckPolicy returns an error if the secret does not comply with the policy
+// of its store. It's not part of Set so internal writes, e.g. OTP counter
+// updates or binary copies, are never rejected.
+func (r *Store) CheckPolicy(ctx context.Context, name string, sec gopass.Byter) error {
+	store, name := r.getStore(name)
+	return store.CheckPolicy(ctx, name, sec)
+}
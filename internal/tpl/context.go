@@ -0,0 +1,25 @@
+package tpl
+
+import "context"
+
+type contextKey int
+
+const (
+	ctxKeyStrict contextKey = iota
+)
+
+// WithStrict returns a context with the flag for strict mode set. In strict
+// mode any error looking up a secret fails the template instead of rendering
+// the error message into the output.
+func WithStrict(ctx context.Context, strict bool) context.Context {
+	return context.WithValue(ctx, ctxKeyStrict, strict)
+}
+
+// IsStrict returns the value of strict mode or the default (false)
+func IsStrict(ctx context.Context) bool {
+	bv, ok := ctx.Value(ctxKeyStrict).(bool)
+	if !ok {
+		return false
+	}
+	return bv
+}
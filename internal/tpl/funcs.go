@@ -3,15 +3,22 @@ package tpl
 import (
 	"context"
 	"crypto/md5"
+	"crypto/rand"
 	"crypto/sha1"
+	"encoding/base64"
 	"fmt"
+	"os"
 	"strconv"
 	"text/template"
+	"time"
 
+	"github.com/gokyle/twofactor"
 	"github.com/gopasspw/gopass/internal/pwschemes/argon2i"
 	"github.com/gopasspw/gopass/internal/pwschemes/argon2id"
 	"github.com/gopasspw/gopass/internal/pwschemes/bcrypt"
 	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/otp"
+	"github.com/gopasspw/gopass/pkg/pwgen"
 	"github.com/jsimonetti/pwscheme/md5crypt"
 	"github.com/jsimonetti/pwscheme/ssha"
 	"github.com/jsimonetti/pwscheme/ssha256"
@@ -33,8 +40,19 @@ const (
 	FuncArgon2i     = "argon2i"
 	FuncArgon2id    = "argon2id"
 	FuncBcrypt      = "bcrypt"
+	FuncInclude     = "include"
+	FuncUUID        = "uuid"
+	FuncNow         = "now"
+	FuncDate        = "date"
+	FuncB64enc      = "b64enc"
+	FuncRandstr     = "randstr"
+	FuncTOTP        = "totp"
+	FuncEnv         = "env"
 )
 
+// maxRandstrLength limits the length of random strings
+const maxRandstrLength = 4096
+
 func md5sum() func(...string) (string, error) {
 	return func(s ...string) (string, error) {
 		return fmt.Sprintf("%x", md5.Sum([]byte(s[0]))), nil
@@ -117,6 +135,89 @@ func bcryptFunc() func(...string) (string, error) {
 		return bcrypt.Generate(s[0])
 	}
 }
+
+// uuid returns a random (version 4) UUID
+func uuid() func() (string, error) {
+	return func() (string, error) {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		b[6] = (b[6] & 0x0f) | 0x40
+		b[8] = (b[8] & 0x3f) | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+	}
+}
+
+func now() func() time.Time {
+	return func() time.Time {
+		return time.Now()
+	}
+}
+
+// date formats the given time (default: now) with a Go time layout, e.g.
+// {{ now | date "2006-01-02" }}
+func date() func(string, ...time.Time) string {
+	return func(layout string, t ...time.Time) string {
+		if len(t) < 1 {
+			return time.Now().Format(layout)
+		}
+		return t[0].Format(layout)
+	}
+}
+
+func b64enc() func(string) string {
+	return func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	}
+}
+
+// randstr returns a random string of the given length. The characters are
+// alphanumeric unless a charset is given.
+func randstr() func(int, ...string) (string, error) {
+	return func(length int, chars ...string) (string, error) {
+		if length < 1 || length > maxRandstrLength {
+			return "", fmt.Errorf("length must be between 1 and %d", maxRandstrLength)
+		}
+		cs := pwgen.CharAlphaNum
+		if len(chars) > 0 && chars[0] != "" {
+			cs = chars[0]
+		}
+		return pwgen.GeneratePasswordCharset(length, cs), nil
+	}
+}
+
+// totp returns the current TOTP code of the given secret
+func totp(ctx context.Context, kv kvstore) func(string) (string, error) {
+	return func(name string) (string, error) {
+		if kv == nil {
+			return "", fmt.Errorf("KV is nil")
+		}
+		sec, err := kv.Get(ctx, name)
+		if err != nil {
+			if IsStrict(ctx) {
+				return "", fmt.Errorf("failed to get %q: %w", name, err)
+			}
+			return err.Error(), nil
+		}
+		t, _, err := otp.Calculate(name, sec)
+		if err != nil {
+			return "", fmt.Errorf("no OTP entry found for %q: %w", name, err)
+		}
+		// HOTP codes would require updating the counter of the secret
+		if t.Type() != twofactor.OATH_TOTP {
+			return "", fmt.Errorf("%q does not contain a TOTP secret", name)
+		}
+		return t.OTP(), nil
+	}
+}
+
+func env() func(string) string {
+	return func(name string) string {
+		return os.Getenv(name)
+	}
+}
+
 func get(ctx context.Context, kv kvstore) func(...string) (string, error) {
 	return func(s ...string) (string, error) {
 		if len(s) < 1 {
@@ -127,6 +228,9 @@ func get(ctx context.Context, kv kvstore) func(...string) (string, error) {
 		}
 		sec, err := kv.Get(ctx, s[0])
 		if err != nil {
+			if IsStrict(ctx) {
+				return "", fmt.Errorf("failed to get %q: %w", s[0], err)
+			}
 			return err.Error(), nil
 		}
 		return string(sec.Bytes()), nil
@@ -143,6 +247,9 @@ func getPassword(ctx context.Context, kv kvstore) func(...string) (string, error
 		}
 		sec, err := kv.Get(ctx, s[0])
 		if err != nil {
+			if IsStrict(ctx) {
+				return "", fmt.Errorf("failed to get %q: %w", s[0], err)
+			}
 			return err.Error(), nil
 		}
 		return sec.Password(), nil
@@ -159,6 +266,9 @@ func getValue(ctx context.Context, kv kvstore) func(...string) (string, error) {
 		}
 		sec, err := kv.Get(ctx, s[0])
 		if err != nil {
+			if IsStrict(ctx) {
+				return "", fmt.Errorf("failed to get %q: %w", s[0], err)
+			}
 			return err.Error(), nil
 		}
 		sv, found := sec.Get(s[1])
@@ -204,5 +314,12 @@ func funcMap(ctx context.Context, kv kvstore) template.FuncMap {
 		FuncArgon2i:     argon2iFunc(),
 		FuncArgon2id:    argon2idFunc(),
 		FuncBcrypt:      bcryptFunc(),
+		FuncUUID:        uuid(),
+		FuncNow:         now(),
+		FuncDate:        date(),
+		FuncB64enc:      b64enc(),
+		FuncRandstr:     randstr(),
+		FuncTOTP:        totp(ctx, kv),
+		FuncEnv:         env(),
 	}
 }
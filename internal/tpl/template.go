@@ -3,16 +3,29 @@ package tpl
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"path/filepath"
 	"text/template"
 
+	"github.com/gopasspw/gopass/pkg/debug"
 	"github.com/gopasspw/gopass/pkg/gopass"
 )
 
+// maxIncludeDepth limits nested includes, e.g. of templates including
+// themselves
+const maxIncludeDepth = 32
+
 type kvstore interface {
 	Get(context.Context, string) (gopass.Secret, error)
 }
 
+// templateStore is implemented by stores that provide the templates used as
+// partials
+type templateStore interface {
+	AllTemplates(context.Context) []string
+	GetTemplate(context.Context, string) ([]byte, error)
+}
+
 type payload struct {
 	Dir     string
 	Path    string
@@ -20,9 +33,10 @@ type payload struct {
 	Content string
 }
 
-// Execute executes the given template
+// Execute executes the given template. If the store provides templates they
+// are available as named partials, e.g. {{ template "web" . }} or
+// {{ include "web" . }}. Blocks defined in any of them can be used as well.
 func Execute(ctx context.Context, tpl, name string, content []byte, s kvstore) ([]byte, error) {
-	funcs := funcMap(ctx, s)
 	pl := payload{
 		Dir:     filepath.Dir(name),
 		Path:    name,
@@ -30,8 +44,16 @@ func Execute(ctx context.Context, tpl, name string, content []byte, s kvstore) (
 		Content: string(content),
 	}
 
-	tmpl, err := template.New(tpl).Funcs(funcs).Parse(tpl)
-	if err != nil {
+	tmpl := template.New(tpl)
+	funcs := funcMap(ctx, s)
+	funcs[FuncInclude] = include(tmpl)
+	tmpl = tmpl.Funcs(funcs)
+
+	addPartials(ctx, tmpl, s)
+
+	// the template itself is parsed last so its blocks take precedence over
+	// those of the partials
+	if _, err := tmpl.Parse(tpl); err != nil {
 		return []byte{}, err
 	}
 
@@ -42,3 +64,44 @@ func Execute(ctx context.Context, tpl, name string, content []byte, s kvstore) (
 
 	return buff.Bytes(), nil
 }
+
+// addPartials adds the templates of the store as named templates. Invalid
+// templates are skipped, using them fails the execution.
+func addPartials(ctx context.Context, tmpl *template.Template, s kvstore) {
+	ts, ok := s.(templateStore)
+	if !ok {
+		return
+	}
+	for _, name := range ts.AllTemplates(ctx) {
+		buf, err := ts.GetTemplate(ctx, name)
+		if err != nil {
+			debug.Log("failed to read template %q: %s", name, err)
+			continue
+		}
+		if _, err := tmpl.New(name).Parse(string(buf)); err != nil {
+			debug.Log("skipping template %q: %s", name, err)
+		}
+	}
+}
+
+// include executes a named template and returns the result, so it can be
+// used in pipelines unlike the template action, e.g.
+// {{ include "footer" . | b64enc }}
+func include(tmpl *template.Template) func(string, interface{}) (string, error) {
+	depth := 0
+	return func(name string, data interface{}) (string, error) {
+		if depth >= maxIncludeDepth {
+			return "", fmt.Errorf("maximum include depth of %d exceeded in %q", maxIncludeDepth, name)
+		}
+		depth++
+		defer func() {
+			depth--
+		}()
+
+		buf := &bytes.Buffer{}
+		if err := tmpl.ExecuteTemplate(buf, name, data); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+}
@@ -2,11 +2,17 @@ package tpl
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/gopasspw/gopass/pkg/gopass"
 	"github.com/gopasspw/gopass/pkg/gopass/secrets/secparse"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type kvMock struct{}
@@ -113,3 +119,171 @@ func TestVars(t *testing.T) {
 		})
 	}
 }
+
+type kvNotFound struct{}
+
+func (k kvNotFound) Get(ctx context.Context, key string) (gopass.Secret, error) {
+	return nil, fmt.Errorf("entry is not in the password store")
+}
+
+func TestStrict(t *testing.T) {
+	ctx := context.Background()
+
+	buf, err := Execute(ctx, `{{ getpw "foo" }}`, "", nil, kvNotFound{})
+	assert.NoError(t, err)
+	assert.Equal(t, "entry is not in the password store", string(buf))
+
+	_, err = Execute(WithStrict(ctx, true), `{{ getpw "foo" }}`, "", nil, kvNotFound{})
+	assert.Error(t, err)
+}
+
+func TestFuncs(t *testing.T) {
+	ctx := context.Background()
+	require.NoError(t, os.Setenv("GOPASS_TPL_TEST", "envvalue"))
+	defer func() {
+		_ = os.Unsetenv("GOPASS_TPL_TEST")
+	}()
+
+	for _, tc := range []struct {
+		Template string
+		Output   string
+		Re       string
+	}{
+		{
+			Template: `{{uuid}}`,
+			Re:       `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`,
+		},
+		{
+			Template: `{{now | date "2006"}}`,
+			Output:   time.Now().Format("2006"),
+		},
+		{
+			Template: `{{date "2006"}}`,
+			Output:   time.Now().Format("2006"),
+		},
+		{
+			Template: `{{"foo" | b64enc}}`,
+			Output:   "Zm9v",
+		},
+		{
+			Template: `{{randstr 12}}`,
+			Re:       `^[a-zA-Z0-9]{12}$`,
+		},
+		{
+			Template: `{{randstr 8 "ab"}}`,
+			Re:       `^[ab]{8}$`,
+		},
+		{
+			Template: `{{env "GOPASS_TPL_TEST"}}`,
+			Output:   "envvalue",
+		},
+	} {
+		buf, err := Execute(ctx, tc.Template, "testdir", nil, kvMock{})
+		require.NoError(t, err, tc.Template)
+		if tc.Re != "" {
+			assert.Regexp(t, regexp.MustCompile(tc.Re), string(buf), tc.Template)
+			continue
+		}
+		assert.Equal(t, tc.Output, string(buf), tc.Template)
+	}
+
+	_, err := Execute(ctx, `{{randstr 0}}`, "testdir", nil, kvMock{})
+	assert.Error(t, err)
+}
+
+type kvOTP struct{}
+
+func (k kvOTP) Get(ctx context.Context, key string) (gopass.Secret, error) {
+	switch key {
+	case "totp":
+		return secparse.Parse([]byte("foo\n---\ntotp: JBSWY3DPEHPK3PXP\n"))
+	case "hotp":
+		return secparse.Parse([]byte("foo\n---\nhotp: JBSWY3DPEHPK3PXP\n"))
+	default:
+		return secparse.Parse([]byte("foo\n"))
+	}
+}
+
+func TestTOTP(t *testing.T) {
+	ctx := context.Background()
+
+	buf, err := Execute(ctx, `{{totp "totp"}}`, "", nil, kvOTP{})
+	require.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^\d{6}$`), string(buf))
+
+	_, err = Execute(ctx, `{{totp "hotp"}}`, "", nil, kvOTP{})
+	assert.Error(t, err)
+}
+
+type kvTemplates struct {
+	kvMock
+	tpls map[string]string
+}
+
+func (k kvTemplates) AllTemplates(context.Context) []string {
+	names := make([]string, 0, len(k.tpls))
+	for name := range k.tpls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (k kvTemplates) GetTemplate(ctx context.Context, name string) ([]byte, error) {
+	tpl, found := k.tpls[name]
+	if !found {
+		return nil, fmt.Errorf("template %q not found", name)
+	}
+	return []byte(tpl), nil
+}
+
+func TestPartials(t *testing.T) {
+	ctx := context.Background()
+
+	kv := kvTemplates{tpls: map[string]string{
+		"web":    `{{.Content}}{{"\n"}}url: https://{{.Name}}`,
+		"common": `{{define "footer"}}created by gopass{{end}}`,
+		"loop":   `{{include "loop" .}}`,
+	}}
+
+	for _, tc := range []struct {
+		Template string
+		Output   string
+	}{
+		{
+			Template: `{{template "web" .}}`,
+			Output:   "foobar\nurl: https://example.com",
+		},
+		{
+			Template: `{{include "web" . | b64enc}}`,
+			Output:   "Zm9vYmFyCnVybDogaHR0cHM6Ly9leGFtcGxlLmNvbQ==",
+		},
+		{
+			Template: `{{template "footer"}}`,
+			Output:   "created by gopass",
+		},
+		{
+			Template: `{{define "footer"}}overridden{{end}}{{template "footer"}}`,
+			Output:   "overridden",
+		},
+	} {
+		buf, err := Execute(ctx, tc.Template, "web/example.com", []byte("foobar"), kv)
+		require.NoError(t, err, tc.Template)
+		assert.Equal(t, tc.Output, string(buf), tc.Template)
+	}
+
+	_, err := Execute(ctx, `{{include "loop" .}}`, "", nil, kv)
+	assert.Error(t, err)
+
+	_, err = Execute(ctx, `{{template "missing"}}`, "", nil, kv)
+	assert.Error(t, err)
+
+	// invalid partials are skipped
+	kv.tpls["broken"] = `{{|}}`
+	buf, err := Execute(WithStrict(ctx, true), `{{template "footer"}}`, "", nil, kv)
+	require.NoError(t, err)
+	assert.Equal(t, "created by gopass", string(buf))
+
+	_, err = Execute(ctx, `{{template "broken"}}`, "", nil, kv)
+	assert.Error(t, err)
+}
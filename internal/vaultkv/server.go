@@ -0,0 +1,445 @@
+// Package vaultkv implements a subset of the HashiCorp Vault KV version 2 HTTP
+// API on top of a password store. It allows tools that speak the Vault API to
+// read and write secrets without a Vault server.
+//
+// Secrets are mapped to Vault data objects with the password in the password
+// key and every other field under its own name. Versions are mapped to the
+// revisions of the storage backend, the oldest revision is version 1.
+package vaultkv
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/store/leaf"
+	"github.com/gopasspw/gopass/internal/tree"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/gopass"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+)
+
+// PasswordKey is the key of the password in Vault data objects
+const PasswordKey = "password"
+
+type secretStore interface {
+	Get(context.Context, string) (gopass.Secret, error)
+	Set(context.Context, string, gopass.Byter) error
+	CheckPolicy(context.Context, string, gopass.Byter) error
+	Delete(context.Context, string) error
+	Exists(context.Context, string) bool
+	List(context.Context, int) ([]string, error)
+	ListRevisions(context.Context, string) ([]backend.Revision, error)
+	GetRevision(context.Context, string, string) (context.Context, gopass.Secret, error)
+}
+
+// Server serves the KV v2 API for a single mount
+type Server struct {
+	ctx   context.Context
+	store secretStore
+	token string
+	mount string
+
+	// mu serializes requests, the stores and their storage backends are not
+	// safe for concurrent use
+	mu sync.Mutex
+}
+
+// New creates a new server. Requests must carry the given token. The secrets
+// engine is mounted at mount, e.g. secret.
+func New(ctx context.Context, store secretStore, token, mount string) *Server {
+	return &Server{
+		ctx:   ctx,
+		store: store,
+		token: token,
+		mount: strings.Trim(mount, "/"),
+	}
+}
+
+type apiError struct {
+	status int
+	msg    string
+}
+
+func (e *apiError) Error() string {
+	return e.msg
+}
+
+func errorf(status int, format string, args ...interface{}) error {
+	return &apiError{status: status, msg: fmt.Sprintf(format, args...)}
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	debug.Log("%s %s", r.Method, r.URL.Path)
+
+	s.mu.Lock()
+	resp, err := s.handle(r)
+	s.mu.Unlock()
+	if err != nil {
+		status := http.StatusInternalServerError
+		var ae *apiError
+		if errors.As(err, &ae) {
+			status = ae.status
+		}
+		debug.Log("%s %s failed: %s", r.Method, r.URL.Path, err)
+		writeJSON(w, status, map[string][]string{"errors": {err.Error()}})
+		return
+	}
+	if resp == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func (s *Server) handle(r *http.Request) (interface{}, error) {
+	if !s.authorized(r) {
+		return nil, errorf(http.StatusForbidden, "permission denied")
+	}
+
+	p := strings.TrimPrefix(r.URL.Path, "/v1/")
+	if p == r.URL.Path {
+		return nil, errorf(http.StatusNotFound, "unsupported path")
+	}
+
+	// the vault CLI uses this to detect the KV version of the mount
+	if strings.HasPrefix(p, "sys/internal/ui/mounts/") {
+		return s.mountInfo(strings.TrimPrefix(p, "sys/internal/ui/mounts/"))
+	}
+
+	if !strings.HasPrefix(p, s.mount+"/") {
+		return nil, errorf(http.StatusNotFound, "no handler for route %q", p)
+	}
+	p = strings.TrimPrefix(p, s.mount+"/")
+
+	method := r.Method
+	if method == http.MethodGet && r.URL.Query().Get("list") == "true" {
+		method = "LIST"
+	}
+
+	switch {
+	case strings.HasPrefix(p, "data/"):
+		name, err := cleanName(strings.TrimPrefix(p, "data/"))
+		if err != nil {
+			return nil, err
+		}
+		switch method {
+		case http.MethodGet:
+			return s.read(name, r.URL.Query().Get("version"))
+		case http.MethodPost, http.MethodPut:
+			return s.write(r, name)
+		case http.MethodDelete:
+			// Vault only soft deletes the latest version on this route,
+			// clients expect to be able to undelete it
+			return nil, errorf(http.StatusMethodNotAllowed, "soft deletes are not supported, delete the metadata to remove the secret")
+		}
+	case strings.HasPrefix(p, "metadata/") || p == "metadata":
+		name, err := cleanName(strings.TrimPrefix(p, "metadata"))
+		if err != nil {
+			return nil, err
+		}
+		switch method {
+		case "LIST":
+			return s.list(name)
+		case http.MethodGet:
+			return s.metadata(name)
+		case http.MethodDelete:
+			return nil, s.delete(name)
+		}
+	default:
+		return nil, errorf(http.StatusNotFound, "no handler for route %q", p)
+	}
+	return nil, errorf(http.StatusMethodNotAllowed, "unsupported operation")
+}
+
+// authorized checks the token in the X-Vault-Token or Authorization header
+func (s *Server) authorized(r *http.Request) bool {
+	token := r.Header.Get("X-Vault-Token")
+	if token == "" {
+		token = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+	return s.token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+func (s *Server) mountInfo(p string) (interface{}, error) {
+	if p != s.mount && !strings.HasPrefix(p, s.mount+"/") {
+		return nil, errorf(http.StatusNotFound, "no mount found for %q", p)
+	}
+	return map[string]interface{}{
+		"data": map[string]interface{}{
+			"path":    s.mount + "/",
+			"type":    "kv",
+			"options": map[string]string{"version": "2"},
+		},
+	}, nil
+}
+
+type versionMetadata struct {
+	CreatedTime    string      `json:"created_time"`
+	CustomMetadata interface{} `json:"custom_metadata"`
+	DeletionTime   string      `json:"deletion_time"`
+	Destroyed      bool        `json:"destroyed"`
+	Version        int         `json:"version"`
+}
+
+// revisions returns the revisions of a secret, oldest first. Backends without
+// revision support still return one revision.
+func (s *Server) revisions(name string) ([]backend.Revision, error) {
+	revs, err := s.store.ListRevisions(s.ctx, name)
+	if err != nil || len(revs) < 1 {
+		debug.Log("no revisions for %s: %v", name, err)
+		return []backend.Revision{{Hash: "latest", Date: time.Now()}}, nil
+	}
+	// backends list the latest revision first
+	out := make([]backend.Revision, len(revs))
+	for i, rev := range revs {
+		out[len(revs)-1-i] = rev
+	}
+	return out, nil
+}
+
+func (s *Server) read(name, version string) (interface{}, error) {
+	if !s.store.Exists(s.ctx, name) {
+		return nil, errorf(http.StatusNotFound, "secret %q not found", name)
+	}
+	revs, err := s.revisions(name)
+	if err != nil {
+		return nil, err
+	}
+
+	v := len(revs)
+	if version != "" && version != "0" {
+		v, err = strconv.Atoi(version)
+		if err != nil || v < 1 {
+			return nil, errorf(http.StatusBadRequest, "invalid version %q", version)
+		}
+		if v > len(revs) {
+			return nil, errorf(http.StatusNotFound, "version %d of %q not found", v, name)
+		}
+	}
+
+	var sec gopass.Secret
+	if v == len(revs) {
+		sec, err = s.store.Get(s.ctx, name)
+	} else {
+		_, sec, err = s.store.GetRevision(s.ctx, name, revs[v-1].Hash)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", name, err)
+	}
+
+	return map[string]interface{}{
+		"data": map[string]interface{}{
+			"data":     secretData(sec),
+			"metadata": versionMeta(revs[v-1], v),
+		},
+	}, nil
+}
+
+func versionMeta(rev backend.Revision, v int) versionMetadata {
+	return versionMetadata{
+		CreatedTime: rev.Date.UTC().Format(time.RFC3339Nano),
+		Version:     v,
+	}
+}
+
+// secretData converts a secret to a Vault data object
+func secretData(sec gopass.Secret) map[string]string {
+	data := map[string]string{}
+	if pw := sec.Password(); pw != "" {
+		data[PasswordKey] = pw
+	}
+	for _, k := range sec.Keys() {
+		if v, found := sec.Get(k); found {
+			data[k] = v
+		}
+	}
+	return data
+}
+
+type writeRequest struct {
+	Data    map[string]interface{} `json:"data"`
+	Options struct {
+		CAS *int `json:"cas"`
+	} `json:"options"`
+}
+
+func (s *Server) write(r *http.Request, name string) (interface{}, error) {
+	if name == "" {
+		return nil, errorf(http.StatusBadRequest, "missing secret name")
+	}
+
+	var req writeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errorf(http.StatusBadRequest, "failed to parse JSON input: %s", err)
+	}
+	if req.Data == nil {
+		return nil, errorf(http.StatusBadRequest, "no data provided")
+	}
+
+	if req.Options.CAS != nil {
+		current := 0
+		if s.store.Exists(s.ctx, name) {
+			revs, err := s.revisions(name)
+			if err != nil {
+				return nil, err
+			}
+			current = len(revs)
+		}
+		if *req.Options.CAS != current {
+			return nil, errorf(http.StatusBadRequest, "check-and-set parameter did not match the current version")
+		}
+	}
+
+	sec := secrets.NewKV()
+	keys := make([]string, 0, len(req.Data))
+	for k := range req.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := req.Data[k]
+		sv, ok := v.(string)
+		if !ok {
+			buf, err := json.Marshal(v)
+			if err != nil {
+				return nil, errorf(http.StatusBadRequest, "invalid value for %q: %s", k, err)
+			}
+			sv = string(buf)
+		}
+		if strings.ContainsAny(sv, "\r\n") {
+			return nil, errorf(http.StatusBadRequest, "multi-line value for %q is not supported", k)
+		}
+		if k == PasswordKey {
+			sec.SetPassword(sv)
+			continue
+		}
+		if err := sec.Set(k, sv); err != nil {
+			return nil, errorf(http.StatusBadRequest, "invalid key %q: %s", k, err)
+		}
+	}
+
+	// there is no way to override the store policy through the API
+	if err := s.store.CheckPolicy(s.ctx, name, sec); err != nil {
+		if errors.Is(err, leaf.ErrPolicyViolation) {
+			return nil, errorf(http.StatusBadRequest, "%s", err)
+		}
+		return nil, fmt.Errorf("failed to check policy for %q: %w", name, err)
+	}
+
+	if err := s.store.Set(ctxutil.WithCommitMessage(s.ctx, "Written via Vault API"), name, sec); err != nil {
+		return nil, fmt.Errorf("failed to write %q: %w", name, err)
+	}
+
+	revs, err := s.revisions(name)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"data": versionMeta(revs[len(revs)-1], len(revs)),
+	}, nil
+}
+
+func (s *Server) delete(name string) error {
+	if !s.store.Exists(s.ctx, name) {
+		return errorf(http.StatusNotFound, "secret %q not found", name)
+	}
+	if err := s.store.Delete(ctxutil.WithCommitMessage(s.ctx, "Deleted via Vault API"), name); err != nil {
+		return fmt.Errorf("failed to delete %q: %w", name, err)
+	}
+	return nil
+}
+
+func (s *Server) list(name string) (interface{}, error) {
+	names, err := s.store.List(s.ctx, tree.INF)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list store: %w", err)
+	}
+
+	prefix := ""
+	if name != "" {
+		prefix = name + "/"
+	}
+	seen := map[string]bool{}
+	keys := []string{}
+	for _, n := range names {
+		if !strings.HasPrefix(n, prefix) {
+			continue
+		}
+		k := strings.TrimPrefix(n, prefix)
+		if i := strings.Index(k, "/"); i >= 0 {
+			k = k[:i+1]
+		}
+		if !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) < 1 {
+		return nil, errorf(http.StatusNotFound, "no secrets below %q", name)
+	}
+	sort.Strings(keys)
+
+	return map[string]interface{}{
+		"data": map[string][]string{"keys": keys},
+	}, nil
+}
+
+func (s *Server) metadata(name string) (interface{}, error) {
+	if !s.store.Exists(s.ctx, name) {
+		return nil, errorf(http.StatusNotFound, "secret %q not found", name)
+	}
+	revs, err := s.revisions(name)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make(map[string]versionMetadata, len(revs))
+	for i, rev := range revs {
+		versions[strconv.Itoa(i+1)] = versionMeta(rev, i+1)
+	}
+
+	return map[string]interface{}{
+		"data": map[string]interface{}{
+			"cas_required":         false,
+			"created_time":         revs[0].Date.UTC().Format(time.RFC3339Nano),
+			"current_version":      len(revs),
+			"custom_metadata":      nil,
+			"delete_version_after": "0s",
+			"max_versions":         0,
+			"oldest_version":       1,
+			"updated_time":         revs[len(revs)-1].Date.UTC().Format(time.RFC3339Nano),
+			"versions":             versions,
+		},
+	}, nil
+}
+
+// cleanName validates the name of a secret taken from the URL
+func cleanName(name string) (string, error) {
+	name = strings.Trim(name, "/")
+	if name == "" {
+		return "", nil
+	}
+	if c := path.Clean(name); c != name || strings.HasPrefix(c, "..") {
+		return "", errorf(http.StatusBadRequest, "invalid path %q", name)
+	}
+	return name, nil
+}
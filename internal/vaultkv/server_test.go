@@ -0,0 +1,236 @@
+package vaultkv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/store/leaf"
+	"github.com/gopasspw/gopass/pkg/gopass"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets/secparse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore keeps every version of a secret, like a git backed store
+type fakeStore map[string][][]byte
+
+func (f fakeStore) Get(_ context.Context, name string) (gopass.Secret, error) {
+	v, found := f[name]
+	if !found {
+		return nil, fmt.Errorf("not found")
+	}
+	return secparse.Parse(v[len(v)-1])
+}
+
+func (f fakeStore) Set(_ context.Context, name string, sec gopass.Byter) error {
+	f[name] = append(f[name], sec.Bytes())
+	return nil
+}
+
+// CheckPolicy rejects the password hunter2
+func (f fakeStore) CheckPolicy(_ context.Context, name string, sec gopass.Byter) error {
+	s, err := secparse.Parse(sec.Bytes())
+	if err != nil {
+		return err
+	}
+	if s.Password() == "hunter2" {
+		return fmt.Errorf("%s: password is forbidden: %w", name, leaf.ErrPolicyViolation)
+	}
+	return nil
+}
+
+func (f fakeStore) Delete(_ context.Context, name string) error {
+	delete(f, name)
+	return nil
+}
+
+func (f fakeStore) Exists(_ context.Context, name string) bool {
+	_, found := f[name]
+	return found
+}
+
+func (f fakeStore) List(context.Context, int) ([]string, error) {
+	names := make([]string, 0, len(f))
+	for k := range f {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (f fakeStore) ListRevisions(_ context.Context, name string) ([]backend.Revision, error) {
+	v := f[name]
+	revs := make([]backend.Revision, 0, len(v))
+	for i := len(v) - 1; i >= 0; i-- {
+		revs = append(revs, backend.Revision{
+			Hash: strconv.Itoa(i),
+			Date: time.Date(2021, 1, 1+i, 0, 0, 0, 0, time.UTC),
+		})
+	}
+	return revs, nil
+}
+
+func (f fakeStore) GetRevision(ctx context.Context, name, rev string) (context.Context, gopass.Secret, error) {
+	i, err := strconv.Atoi(rev)
+	if err != nil {
+		return ctx, nil, err
+	}
+	sec, err := secparse.Parse(f[name][i])
+	return ctx, sec, err
+}
+
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+	store := fakeStore{}
+	ts := httptest.NewServer(New(ctx, store, "t0ken", "secret"))
+	defer ts.Close()
+
+	do := func(method, path, token, body string) (int, map[string]interface{}) {
+		t.Helper()
+		req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("X-Vault-Token", token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		var m map[string]interface{}
+		if len(buf) > 0 {
+			require.NoError(t, json.Unmarshal(buf, &m), string(buf))
+		}
+		return resp.StatusCode, m
+	}
+
+	t.Run("auth", func(t *testing.T) {
+		code, m := do(http.MethodGet, "/v1/secret/data/foo", "", "")
+		assert.Equal(t, http.StatusForbidden, code)
+		assert.Equal(t, []interface{}{"permission denied"}, m["errors"])
+		code, _ = do(http.MethodGet, "/v1/secret/data/foo", "wrong", "")
+		assert.Equal(t, http.StatusForbidden, code)
+	})
+
+	t.Run("write and read", func(t *testing.T) {
+		code, m := do(http.MethodPost, "/v1/secret/data/prod/db", "t0ken", `{"data":{"password":"s3cret","username":"admin","port":5432}}`)
+		require.Equal(t, http.StatusOK, code, m)
+		assert.Equal(t, float64(1), m["data"].(map[string]interface{})["version"])
+
+		code, m = do(http.MethodGet, "/v1/secret/data/prod/db", "t0ken", "")
+		require.Equal(t, http.StatusOK, code, m)
+		data := m["data"].(map[string]interface{})
+		assert.Equal(t, map[string]interface{}{"password": "s3cret", "username": "admin", "port": "5432"}, data["data"])
+		assert.Equal(t, float64(1), data["metadata"].(map[string]interface{})["version"])
+	})
+
+	t.Run("versions", func(t *testing.T) {
+		code, _ := do(http.MethodPut, "/v1/secret/data/prod/db", "t0ken", `{"data":{"password":"n3w"},"options":{"cas":0}}`)
+		assert.Equal(t, http.StatusBadRequest, code)
+		code, m := do(http.MethodPut, "/v1/secret/data/prod/db", "t0ken", `{"data":{"password":"n3w"},"options":{"cas":1}}`)
+		require.Equal(t, http.StatusOK, code, m)
+		assert.Equal(t, float64(2), m["data"].(map[string]interface{})["version"])
+
+		_, m = do(http.MethodGet, "/v1/secret/data/prod/db", "t0ken", "")
+		assert.Equal(t, map[string]interface{}{"password": "n3w"}, m["data"].(map[string]interface{})["data"])
+
+		_, m = do(http.MethodGet, "/v1/secret/data/prod/db?version=1", "t0ken", "")
+		assert.Equal(t, "s3cret", m["data"].(map[string]interface{})["data"].(map[string]interface{})["password"])
+
+		code, _ = do(http.MethodGet, "/v1/secret/data/prod/db?version=3", "t0ken", "")
+		assert.Equal(t, http.StatusNotFound, code)
+
+		code, m = do(http.MethodGet, "/v1/secret/metadata/prod/db", "t0ken", "")
+		require.Equal(t, http.StatusOK, code, m)
+		md := m["data"].(map[string]interface{})
+		assert.Equal(t, float64(2), md["current_version"])
+		assert.Equal(t, float64(1), md["oldest_version"])
+		assert.Len(t, md["versions"], 2)
+	})
+
+	t.Run("policy", func(t *testing.T) {
+		code, m := do(http.MethodPost, "/v1/secret/data/prod/db", "t0ken", `{"data":{"password":"hunter2"}}`)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Contains(t, fmt.Sprint(m["errors"]), "policy violation")
+		code, m = do(http.MethodPost, "/v1/secret/data/weak", "t0ken", `{"data":{"password":"hunter2"}}`)
+		assert.Equal(t, http.StatusBadRequest, code, m)
+		assert.False(t, store.Exists(ctx, "weak"))
+
+		_, m = do(http.MethodGet, "/v1/secret/metadata/prod/db", "t0ken", "")
+		assert.Equal(t, float64(2), m["data"].(map[string]interface{})["current_version"])
+	})
+
+	t.Run("list", func(t *testing.T) {
+		do(http.MethodPost, "/v1/secret/data/prod/api/token", "t0ken", `{"data":{"password":"x"}}`)
+		do(http.MethodPost, "/v1/secret/data/dev", "t0ken", `{"data":{"password":"x"}}`)
+
+		code, m := do("LIST", "/v1/secret/metadata/prod", "t0ken", "")
+		require.Equal(t, http.StatusOK, code, m)
+		assert.Equal(t, []interface{}{"api/", "db"}, m["data"].(map[string]interface{})["keys"])
+
+		code, m = do(http.MethodGet, "/v1/secret/metadata/?list=true", "t0ken", "")
+		require.Equal(t, http.StatusOK, code, m)
+		assert.Equal(t, []interface{}{"dev", "prod/"}, m["data"].(map[string]interface{})["keys"])
+
+		code, _ = do("LIST", "/v1/secret/metadata/nothing", "t0ken", "")
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		code, _ := do(http.MethodDelete, "/v1/secret/data/dev", "t0ken", "")
+		assert.Equal(t, http.StatusMethodNotAllowed, code)
+		assert.True(t, store.Exists(ctx, "dev"))
+
+		code, _ = do(http.MethodDelete, "/v1/secret/metadata/dev", "t0ken", "")
+		assert.Equal(t, http.StatusNoContent, code)
+		code, _ = do(http.MethodGet, "/v1/secret/data/dev", "t0ken", "")
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("concurrent writes", func(t *testing.T) {
+		srv := New(ctx, store, "t0ken", "secret")
+		var wg sync.WaitGroup
+		recs := make([]*httptest.ResponseRecorder, 16)
+		for i := range recs {
+			recs[i] = httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/v1/secret/data/concurrent/%d", i), strings.NewReader(`{"data":{"password":"x"}}`))
+			req.Header.Set("X-Vault-Token", "t0ken")
+			wg.Add(1)
+			go func(rec *httptest.ResponseRecorder, req *http.Request) {
+				defer wg.Done()
+				srv.ServeHTTP(rec, req)
+			}(recs[i], req)
+		}
+		wg.Wait()
+		for i, rec := range recs {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.True(t, store.Exists(ctx, fmt.Sprintf("concurrent/%d", i)))
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		code, _ := do(http.MethodGet, "/v1/other/data/foo", "t0ken", "")
+		assert.Equal(t, http.StatusNotFound, code)
+		code, _ = do(http.MethodGet, "/v1/secret/data/../foo", "t0ken", "")
+		assert.NotEqual(t, http.StatusOK, code)
+		code, _ = do(http.MethodPost, "/v1/secret/data/foo", "t0ken", `not json`)
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("mount info", func(t *testing.T) {
+		code, m := do(http.MethodGet, "/v1/sys/internal/ui/mounts/secret/prod/db", "t0ken", "")
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, map[string]interface{}{"version": "2"}, m["data"].(map[string]interface{})["options"])
+	})
+}
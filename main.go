@@ -7,9 +7,11 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"runtime"
 	"runtime/pprof"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/gopasspw/gopass/internal/action/pwgen"
@@ -88,7 +90,7 @@ func main() {
 	q := queue.New(ctx)
 	ctx = queue.WithQueue(ctx, q)
 	ctx, app := setupApp(ctx, sv)
-	if err := app.RunContext(ctx, os.Args); err != nil {
+	if err := app.RunContext(ctx, dockerCredentialArgs(os.Args)); err != nil {
 		log.Fatal(err)
 	}
 	q.Wait(ctx)
@@ -105,6 +107,15 @@ func main() {
 	}
 }
 
+// dockerCredentialArgs rewrites the arguments if gopass is invoked as docker
+// credential helper, i.e. as docker-credential-gopass <action>
+func dockerCredentialArgs(args []string) []string {
+	if len(args) < 1 || strings.TrimSuffix(filepath.Base(args[0]), ".exe") != "docker-credential-"+name {
+		return args
+	}
+	return append([]string{args[0], "docker-credential"}, args[1:]...)
+}
+
 func setupApp(ctx context.Context, sv semver.Version) (context.Context, *cli.App) {
 	// try to read config (if it exists)
 	cfg := config.LoadWithFallback()
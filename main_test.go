@@ -51,43 +51,62 @@ func TestSetupApp(t *testing.T) {
 // commandsWithError is a list of commands that return an error when
 // invoked without arguments
 var commandsWithError = map[string]struct{}{
-	".alias.add":         {},
-	".alias.remove":      {},
-	".alias.delete":      {},
-	".audit":             {},
-	".cat":               {},
-	".clone":             {},
-	".convert":           {},
-	".copy":              {},
-	".create":            {},
-	".delete":            {},
-	".edit":              {},
-	".env":               {},
-	".find":              {},
-	".fscopy":            {},
-	".fsmove":            {},
-	".generate":          {},
-	".git.push":          {},
-	".git.pull":          {},
-	".git.remote.add":    {},
-	".git.remote.remove": {},
-	".grep":              {},
-	".history":           {},
-	".init":              {},
-	".insert":            {},
-	".link":              {},
-	".mounts.add":        {},
-	".mounts.remove":     {},
-	".move":              {},
-	".otp":               {},
-	".recipients.add":    {},
-	".recipients.remove": {},
-	".show":              {},
-	".sum":               {},
-	".templates.edit":    {},
-	".templates.remove":  {},
-	".templates.show":    {},
-	".unclip":            {},
+	".alias.add":               {},
+	".alias.remove":            {},
+	".alias.delete":            {},
+	".audit":                   {},
+	".cat":                     {},
+	".clone":                   {},
+	".combine":                 {},
+	".convert":                 {},
+	".copy":                    {},
+	".create":                  {},
+	".delete":                  {},
+	".docker-credential.store": {},
+	".docker-credential.get":   {},
+	".docker-credential.erase": {},
+	".edit":                    {},
+	".env":                     {},
+	".find":                    {},
+	".fscopy":                  {},
+	".fsmove":                  {},
+	".generate":                {},
+	".git-credential.get":      {},
+	".git-credential.store":    {},
+	".git-credential.erase":    {},
+	".git.push":                {},
+	".git.pull":                {},
+	".git.remote.add":          {},
+	".git.remote.remove":       {},
+	".grep":                    {},
+	".history":                 {},
+	".init":                    {},
+	".insert":                  {},
+	".k8s.export":              {},
+	".k8s.import":              {},
+	".link":                    {},
+	".log.access":              {},
+	".mounts.add":              {},
+	".mounts.remove":           {},
+	".move":                    {},
+	".otp":                     {},
+	".otp.enroll":              {},
+	".pwrules.add":             {},
+	".pwrules.remove":          {},
+	".pwrules.test":            {},
+	".recipients.add":          {},
+	".recipients.remove":       {},
+	".recipients.group.add":    {},
+	".recipients.group.remove": {},
+	".render":                  {},
+	".serve":                   {},
+	".show":                    {},
+	".split":                   {},
+	".sum":                     {},
+	".templates.edit":          {},
+	".templates.remove":        {},
+	".templates.show":          {},
+	".unclip":                  {},
 }
 
 func TestGetCommands(t *testing.T) {
@@ -122,7 +141,7 @@ func TestGetCommands(t *testing.T) {
 	c.Context = ctx
 
 	commands := getCommands(act, app)
-	assert.Equal(t, 37, len(commands))
+	assert.Equal(t, 48, len(commands))
 
 	prefix := ""
 	testCommands(t, c, commands, prefix)
@@ -130,7 +149,9 @@ func TestGetCommands(t *testing.T) {
 
 func testCommands(t *testing.T, c *cli.Context, commands []*cli.Command, prefix string) {
 	for _, cmd := range commands {
-		if cmd.Name == "update" {
+		switch cmd.Name {
+		case "update", "ssh-agent":
+			// these need network access or run until interrupted
 			continue
 		}
 		if len(cmd.Subcommands) > 0 {
@@ -155,6 +176,11 @@ func testCommands(t *testing.T, c *cli.Context, commands []*cli.Command, prefix
 	}
 }
 
+func TestDockerCredentialArgs(t *testing.T) {
+	assert.Equal(t, []string{"gopass", "show"}, dockerCredentialArgs([]string{"gopass", "show"}))
+	assert.Equal(t, []string{"/usr/bin/docker-credential-gopass", "docker-credential", "get"}, dockerCredentialArgs([]string{"/usr/bin/docker-credential-gopass", "get"}))
+}
+
 func TestInitContext(t *testing.T) {
 	ctx := context.Background()
 	cfg := config.New()
@@ -23,15 +23,22 @@ var (
 // CopyTo copies the given data to the clipboard and enqueues automatic
 // clearing of the clipboard
 func CopyTo(ctx context.Context, name string, content []byte, timeout int) error {
-	if clipboard.Unsupported {
-		out.Printf(ctx, "%s", ErrNotSupported)
-		_ = notify.Notify(ctx, "gopass - clipboard", fmt.Sprintf("%s", ErrNotSupported))
-		return nil
-	}
+	if useOSC52(ctx) {
+		if err := writeOSC52(content); err != nil {
+			_ = notify.Notify(ctx, "gopass - clipboard", "failed to write to clipboard")
+			return fmt.Errorf("failed to write to clipboard: %w", err)
+		}
+	} else {
+		if clipboard.Unsupported {
+			out.Printf(ctx, "%s", ErrNotSupported)
+			_ = notify.Notify(ctx, "gopass - clipboard", fmt.Sprintf("%s", ErrNotSupported))
+			return nil
+		}
 
-	if err := clipboard.WriteAll(string(content)); err != nil {
-		_ = notify.Notify(ctx, "gopass - clipboard", "failed to write to clipboard")
-		return fmt.Errorf("failed to write to clipboard: %w", err)
+		if err := clipboard.WriteAll(string(content)); err != nil {
+			_ = notify.Notify(ctx, "gopass - clipboard", "failed to write to clipboard")
+			return fmt.Errorf("failed to write to clipboard: %w", err)
+		}
 	}
 
 	if timeout < 1 {
@@ -0,0 +1,96 @@
+package clipboard
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+)
+
+// Supported values for the clipboard config option
+const (
+	// ProviderAuto uses OSC 52 in SSH sessions and the system clipboard
+	// otherwise. This is the default.
+	ProviderAuto = "auto"
+	// ProviderSystem always uses the system clipboard helpers
+	ProviderSystem = "system"
+	// ProviderOSC52 always uses OSC 52 terminal escape sequences
+	ProviderOSC52 = "osc52"
+)
+
+// screen limits the length of DCS strings, so longer sequences are split
+const screenChunkSize = 76
+
+// openTTY returns the terminal the OSC 52 sequences are written to. We don't
+// use stdout since it might be redirected.
+var openTTY = func() (io.WriteCloser, error) {
+	return os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+}
+
+// useOSC52 decides whether the OSC 52 provider should be used. It only looks
+// at the config and the environment, so the background unclip process comes
+// to the same conclusion as the process that copied the content.
+func useOSC52(ctx context.Context) bool {
+	switch strings.ToLower(ctxutil.GetClipboardProvider(ctx)) {
+	case ProviderOSC52:
+		return true
+	case ProviderSystem:
+		return false
+	}
+
+	// the system clipboard of a remote host is of no use
+	if os.Getenv("SSH_TTY") == "" && os.Getenv("SSH_CONNECTION") == "" {
+		return false
+	}
+	tty, err := openTTY()
+	if err != nil {
+		return false
+	}
+	_ = tty.Close()
+	return true
+}
+
+// writeOSC52 asks the terminal to put the content into the clipboard. An
+// empty content clears the clipboard on most terminals.
+func writeOSC52(content []byte) error {
+	tty, err := openTTY()
+	if err != nil {
+		return fmt.Errorf("no terminal available: %w", err)
+	}
+
+	if _, err := io.WriteString(tty, osc52Sequence(content)); err != nil {
+		_ = tty.Close()
+		return err
+	}
+	return tty.Close()
+}
+
+// osc52Sequence returns the escape sequence to set the clipboard. Inside of
+// tmux and screen it's wrapped in a DCS passthrough sequence so it reaches the
+// outer terminal.
+func osc52Sequence(content []byte) string {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString(content) + "\x07"
+
+	switch {
+	case os.Getenv("TMUX") != "":
+		// requires "set -g allow-passthrough on" in tmux 3.3 or later
+		return "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	case os.Getenv("STY") != "" || strings.HasPrefix(os.Getenv("TERM"), "screen"):
+		var sb strings.Builder
+		for len(seq) > 0 {
+			n := screenChunkSize
+			if n > len(seq) {
+				n = len(seq)
+			}
+			sb.WriteString("\x1bP" + seq[:n] + "\x1b\\")
+			seq = seq[n:]
+		}
+		return sb.String()
+	default:
+		return seq
+	}
+}
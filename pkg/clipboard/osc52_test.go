@@ -0,0 +1,125 @@
+package clipboard
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTTY struct {
+	*bytes.Buffer
+}
+
+func (fakeTTY) Close() error { return nil }
+
+func withTTY(t *testing.T, err error) *bytes.Buffer {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	ov := openTTY
+	t.Cleanup(func() {
+		openTTY = ov
+	})
+	openTTY = func() (io.WriteCloser, error) {
+		if err != nil {
+			return nil, err
+		}
+		return fakeTTY{buf}, nil
+	}
+	return buf
+}
+
+// setenv sets an environment variable until the end of the test, like
+// t.Setenv in newer Go versions
+func setenv(t *testing.T, key, value string) {
+	t.Helper()
+
+	old, found := os.LookupEnv(key)
+	t.Cleanup(func() {
+		if found {
+			_ = os.Setenv(key, old)
+			return
+		}
+		_ = os.Unsetenv(key)
+	})
+	require.NoError(t, os.Setenv(key, value))
+}
+
+func TestOSC52Sequence(t *testing.T) {
+	setenv(t, "TMUX", "")
+	setenv(t, "STY", "")
+	setenv(t, "TERM", "xterm-256color")
+	assert.Equal(t, "\x1b]52;c;Zm9v\x07", osc52Sequence([]byte("foo")))
+	assert.Equal(t, "\x1b]52;c;\x07", osc52Sequence(nil))
+
+	setenv(t, "TMUX", "/tmp/tmux-1000/default,1234,0")
+	assert.Equal(t, "\x1bPtmux;\x1b\x1b]52;c;Zm9v\x07\x1b\\", osc52Sequence([]byte("foo")))
+
+	setenv(t, "TMUX", "")
+	setenv(t, "TERM", "screen")
+	assert.Equal(t, "\x1bP\x1b]52;c;Zm9v\x07\x1b\\", osc52Sequence([]byte("foo")))
+
+	// long sequences are split into chunks for screen
+	seq := osc52Sequence(bytes.Repeat([]byte("a"), 200))
+	assert.Equal(t, 4, strings.Count(seq, "\x1bP"))
+	assert.Equal(t, 4, strings.Count(seq, "\x1b\\"))
+}
+
+func TestUseOSC52(t *testing.T) {
+	ctx := context.Background()
+	setenv(t, "SSH_TTY", "")
+	setenv(t, "SSH_CONNECTION", "")
+
+	withTTY(t, nil)
+	assert.False(t, useOSC52(ctx))
+	assert.True(t, useOSC52(ctxutil.WithClipboardProvider(ctx, ProviderOSC52)))
+	assert.False(t, useOSC52(ctxutil.WithClipboardProvider(ctx, ProviderSystem)))
+
+	// auto detects SSH sessions
+	setenv(t, "SSH_CONNECTION", "10.0.0.1 50000 10.0.0.2 22")
+	assert.True(t, useOSC52(ctx))
+	assert.True(t, useOSC52(ctxutil.WithClipboardProvider(ctx, ProviderAuto)))
+	assert.False(t, useOSC52(ctxutil.WithClipboardProvider(ctx, ProviderSystem)))
+
+	// but needs a terminal
+	withTTY(t, fmt.Errorf("no tty"))
+	assert.False(t, useOSC52(ctx))
+}
+
+func TestCopyToOSC52(t *testing.T) {
+	setenv(t, "GOPASS_NO_NOTIFY", "true")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctx = ctxutil.WithClipboardProvider(ctx, ProviderOSC52)
+	setenv(t, "TMUX", "")
+	setenv(t, "STY", "")
+	setenv(t, "TERM", "xterm")
+
+	obuf := &bytes.Buffer{}
+	out.Stdout = obuf
+	defer func() {
+		out.Stdout = os.Stdout
+	}()
+
+	tty := withTTY(t, nil)
+	assert.NoError(t, CopyTo(ctx, "foo", []byte("bar"), 1))
+	assert.Equal(t, "\x1b]52;c;YmFy\x07", tty.String())
+	assert.Contains(t, obuf.String(), "Copied")
+
+	tty.Reset()
+	assert.NoError(t, Clear(ctx, "", false))
+	assert.Equal(t, "\x1b]52;c;\x07", tty.String())
+
+	withTTY(t, fmt.Errorf("no tty"))
+	assert.Error(t, CopyTo(ctx, "foo", []byte("bar"), 1))
+}
@@ -12,6 +12,10 @@ import (
 
 // Clear will attempt to erase the clipboard
 func Clear(ctx context.Context, checksum string, force bool) error {
+	if useOSC52(ctx) {
+		return clearOSC52(ctx)
+	}
+
 	if clipboard.Unsupported {
 		return ErrNotSupported
 	}
@@ -42,3 +46,19 @@ func Clear(ctx context.Context, checksum string, force bool) error {
 
 	return nil
 }
+
+// clearOSC52 overwrites the clipboard using OSC 52. Terminals usually don't
+// allow reading the clipboard, so we can't compare the checksum. But any
+// newer copy kills the pending unclip process, so the clipboard still holds
+// our content.
+func clearOSC52(ctx context.Context) error {
+	if err := writeOSC52(nil); err != nil {
+		_ = notify.Notify(ctx, "gopass - clipboard", "Failed to clear clipboard")
+		return fmt.Errorf("failed to write clipboard: %w", err)
+	}
+
+	if err := notify.Notify(ctx, "gopass - clipboard", "Clipboard has been cleared"); err != nil {
+		return fmt.Errorf("failed to send unclip notification: %w", err)
+	}
+	return nil
+}
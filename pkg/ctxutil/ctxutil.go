@@ -37,6 +37,8 @@ const (
 	ctxKeyCommitTimestamp
 	ctxKeyShowParsing
 	ctxKeyHidden
+	ctxKeyClipboardProvider
+	ctxKeyAccessAction
 )
 
 // WithGlobalFlags parses any global flags from the cli context and returns
@@ -506,3 +508,39 @@ func IsHidden(ctx context.Context) bool {
 	}
 	return bv
 }
+
+// WithClipboardProvider returns a context with the clipboard provider set
+func WithClipboardProvider(ctx context.Context, sv string) context.Context {
+	return context.WithValue(ctx, ctxKeyClipboardProvider, sv)
+}
+
+// HasClipboardProvider returns true if a value for the clipboard provider
+// has been set in this context
+func HasClipboardProvider(ctx context.Context) bool {
+	_, ok := ctx.Value(ctxKeyClipboardProvider).(string)
+	return ok
+}
+
+// GetClipboardProvider returns the clipboard provider from the context
+func GetClipboardProvider(ctx context.Context) string {
+	sv, ok := ctx.Value(ctxKeyClipboardProvider).(string)
+	if !ok {
+		return ""
+	}
+	return sv
+}
+
+// WithAccessAction returns a context with the reason for reading secrets set.
+// Reads with an access action are recorded in the access log, if enabled.
+func WithAccessAction(ctx context.Context, sv string) context.Context {
+	return context.WithValue(ctx, ctxKeyAccessAction, sv)
+}
+
+// GetAccessAction returns the reason for reading secrets from the context
+func GetAccessAction(ctx context.Context) string {
+	sv, ok := ctx.Value(ctxKeyAccessAction).(string)
+	if !ok {
+		return ""
+	}
+	return sv
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/gopasspw/gopass/internal/config"
 	"github.com/gopasspw/gopass/internal/queue"
 	"github.com/gopasspw/gopass/internal/store/root"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/gopass"
 )
 
@@ -46,11 +47,15 @@ func (g *Gopass) List(ctx context.Context) ([]string, error) {
 
 // Get returns a single, encrypted secret. It must be unwrapped before use.
 func (g *Gopass) Get(ctx context.Context, name, revision string) (gopass.Secret, error) {
-	return g.rs.Get(ctx, name)
+	return g.rs.Get(ctxutil.WithAccessAction(ctx, "api"), name)
 }
 
-// Set adds a new revision to an existing secret or creates a new one.
+// Set adds a new revision to an existing secret or creates a new one. The
+// secret must comply with the policy of its store.
 func (g *Gopass) Set(ctx context.Context, name string, sec gopass.Byter) error {
+	if err := g.rs.CheckPolicy(ctx, name, sec); err != nil {
+		return err
+	}
 	return g.rs.Set(ctx, name, sec)
 }
 
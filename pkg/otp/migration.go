@@ -0,0 +1,155 @@
+package otp
+
+import (
+	"crypto"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/gokyle/twofactor"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// MigrationScheme is the URL scheme used by the export feature of Google
+// Authenticator
+const MigrationScheme = "otpauth-migration"
+
+// Field numbers and enum values of the MigrationPayload protobuf message used
+// by Google Authenticator exports.
+const (
+	migrationOTPParameters = 1
+
+	paramSecret    = 1
+	paramName      = 2
+	paramIssuer    = 3
+	paramAlgorithm = 4
+	paramDigits    = 5
+	paramType      = 6
+	paramCounter   = 7
+
+	migrationSHA256 = 2
+	migrationSHA512 = 3
+	migrationMD5    = 4
+
+	migrationEightDigits = 2
+
+	migrationHOTP = 1
+)
+
+// FromMigrationURL parses an otpauth-migration:// URL as exported by Google
+// Authenticator. A single export can contain many tokens.
+func FromMigrationURL(raw string) ([]*Token, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid migration URL: %w", err)
+	}
+	if u.Scheme != MigrationScheme {
+		return nil, fmt.Errorf("invalid migration URL: unsupported scheme %q", u.Scheme)
+	}
+
+	data := u.Query().Get("data")
+	if data == "" {
+		return nil, fmt.Errorf("invalid migration URL: missing data")
+	}
+	// some QR readers don't escape the + in the base64 data
+	buf, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(data, " ", "+"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid migration URL: %w", err)
+	}
+
+	var tokens []*Token
+	err = walkProto(buf, func(num protowire.Number, typ protowire.Type, v []byte, _ uint64) error {
+		if num != migrationOTPParameters || typ != protowire.BytesType {
+			return nil
+		}
+		t, err := parseMigrationParams(v)
+		if err != nil {
+			return err
+		}
+		tokens = append(tokens, t)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid migration URL: %w", err)
+	}
+	if len(tokens) < 1 {
+		return nil, fmt.Errorf("invalid migration URL: no tokens found")
+	}
+	return tokens, nil
+}
+
+func parseMigrationParams(buf []byte) (*Token, error) {
+	t := newToken()
+	err := walkProto(buf, func(num protowire.Number, typ protowire.Type, v []byte, n uint64) error {
+		switch num {
+		case paramSecret:
+			t.key = append([]byte{}, v...)
+		case paramName:
+			t.label = string(v)
+		case paramIssuer:
+			t.issuer = string(v)
+		case paramAlgorithm:
+			switch n {
+			case migrationSHA256:
+				t.algo = crypto.SHA256
+			case migrationSHA512:
+				t.algo = crypto.SHA512
+			case migrationMD5:
+				return fmt.Errorf("unsupported OTP algorithm MD5")
+			}
+		case paramDigits:
+			if n == migrationEightDigits {
+				t.digits = 8
+			}
+		case paramType:
+			if n == migrationHOTP {
+				t.typ = twofactor.OATH_HOTP
+			}
+		case paramCounter:
+			t.counter = n
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(t.key) < 1 {
+		return nil, fmt.Errorf("token %q has no secret", t.label)
+	}
+	return t, nil
+}
+
+// walkProto calls fn for every bytes or varint field of a protobuf message
+func walkProto(buf []byte, fn func(protowire.Number, protowire.Type, []byte, uint64) error) error {
+	for len(buf) > 0 {
+		num, typ, n := protowire.ConsumeTag(buf)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		buf = buf[n:]
+
+		var v []byte
+		var i uint64
+		switch typ {
+		case protowire.BytesType:
+			v, n = protowire.ConsumeBytes(buf)
+		case protowire.VarintType:
+			i, n = protowire.ConsumeVarint(buf)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, buf)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		buf = buf[n:]
+
+		if typ != protowire.BytesType && typ != protowire.VarintType {
+			continue
+		}
+		if err := fn(num, typ, v, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
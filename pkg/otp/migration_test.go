@@ -0,0 +1,101 @@
+package otp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gokyle/twofactor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+	"rsc.io/qr"
+)
+
+func migrationParams(secret, name, issuer string, algo, digits, typ, counter uint64) []byte {
+	var b []byte
+	b = protowire.AppendTag(b, paramSecret, protowire.BytesType)
+	b = protowire.AppendBytes(b, []byte(secret))
+	b = protowire.AppendTag(b, paramName, protowire.BytesType)
+	b = protowire.AppendString(b, name)
+	b = protowire.AppendTag(b, paramIssuer, protowire.BytesType)
+	b = protowire.AppendString(b, issuer)
+	b = protowire.AppendTag(b, paramAlgorithm, protowire.VarintType)
+	b = protowire.AppendVarint(b, algo)
+	b = protowire.AppendTag(b, paramDigits, protowire.VarintType)
+	b = protowire.AppendVarint(b, digits)
+	b = protowire.AppendTag(b, paramType, protowire.VarintType)
+	b = protowire.AppendVarint(b, typ)
+	b = protowire.AppendTag(b, paramCounter, protowire.VarintType)
+	b = protowire.AppendVarint(b, counter)
+	return b
+}
+
+func migrationURL(params ...[]byte) string {
+	var b []byte
+	for _, p := range params {
+		b = protowire.AppendTag(b, migrationOTPParameters, protowire.BytesType)
+		b = protowire.AppendBytes(b, p)
+	}
+	// version
+	b = protowire.AppendTag(b, 2, protowire.VarintType)
+	b = protowire.AppendVarint(b, 1)
+
+	return "otpauth-migration://offline?data=" + url.QueryEscape(base64.StdEncoding.EncodeToString(b))
+}
+
+func TestFromMigrationURL(t *testing.T) {
+	u := migrationURL(
+		migrationParams("12345678901234567890", "Example:alice@example.com", "Example", 1, 2, 2, 0),
+		migrationParams("12345678901234567890", "bob", "", 0, 0, migrationHOTP, 1),
+	)
+
+	tokens, err := FromMigrationURL(u)
+	require.NoError(t, err)
+	require.Len(t, tokens, 2)
+
+	assert.Equal(t, "Example:alice@example.com", tokens[0].Label())
+	assert.Equal(t, "Example", tokens[0].Issuer())
+	assert.Equal(t, 8, tokens[0].Size())
+	assert.Equal(t, twofactor.Type(twofactor.OATH_TOTP), tokens[0].Type())
+	assert.Equal(t, "94287082", tokens[0].At(time.Unix(59, 0)))
+
+	assert.Equal(t, "bob", tokens[1].Label())
+	assert.Equal(t, twofactor.Type(twofactor.OATH_HOTP), tokens[1].Type())
+	// RFC 4226 test vector for counter 1
+	assert.Equal(t, "287082", tokens[1].OTP())
+
+	// the generated URLs are valid
+	for _, tok := range tokens {
+		_, _, err := twofactor.FromURL(tok.URL(tok.Label()))
+		assert.NoError(t, err)
+	}
+
+	for _, in := range []string{
+		"otpauth://totp/foo?secret=JBSWY3DPEHPK3PXP",
+		"otpauth-migration://offline",
+		"otpauth-migration://offline?data=!!!",
+		"otpauth-migration://offline?data=" + base64.StdEncoding.EncodeToString([]byte{0xff}),
+		migrationURL(),
+		migrationURL(migrationParams("12345678901234567890", "md5", "", migrationMD5, 0, 0, 0)),
+	} {
+		_, err := FromMigrationURL(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestDecodeQR(t *testing.T) {
+	for _, in := range []string{totpURL, migrationURL(migrationParams("12345678901234567890", "bob", "", 0, 0, 0, 0))} {
+		code, err := qr.Encode(in, qr.Q)
+		require.NoError(t, err)
+
+		got, err := DecodeQR(bytes.NewReader(code.PNG()))
+		require.NoError(t, err)
+		assert.Equal(t, in, got)
+	}
+
+	_, err := DecodeQR(bytes.NewReader([]byte("not an image")))
+	assert.Error(t, err)
+}
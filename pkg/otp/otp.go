@@ -7,15 +7,19 @@ import (
 
 	"github.com/gokyle/twofactor"
 	"github.com/gopasspw/gopass/pkg/gopass"
+	"rsc.io/qr"
 )
 
 // Calculate will compute a OTP code from a given secret
-func Calculate(name string, sec gopass.Secret) (twofactor.OTP, string, error) {
-	otpURL, found := sec.Get("otpauth")
+func Calculate(name string, sec gopass.Secret) (*Token, string, error) {
+	field := "otpauth"
+	otpURL, found := sec.Get(field)
 	if found && strings.HasPrefix(otpURL, "//") {
 		otpURL = "otpauth:" + otpURL
-	} else {
+	} else if !found || !strings.HasPrefix(otpURL, "otpauth://") {
 		// check body
+		field = ""
+		otpURL = ""
 		for _, line := range strings.Split(sec.Body(), "\n") {
 			if strings.HasPrefix(line, "otpauth://") {
 				otpURL = line
@@ -25,36 +29,49 @@ func Calculate(name string, sec gopass.Secret) (twofactor.OTP, string, error) {
 	}
 
 	if otpURL != "" {
-		return twofactor.FromURL(otpURL)
+		t, err := FromURL(otpURL)
+		if err != nil {
+			return nil, "", err
+		}
+		t.field = field
+		return t, t.label, nil
 	}
 
 	// check yaml entry and fall back to password if we don't have one
-	label := name
-
-	secKey, found := sec.Get("totp")
+	field = "totp"
+	secKey, found := sec.Get(field)
+	if !found {
+		field = "hotp"
+		secKey, found = sec.Get(field)
+	}
 	if !found {
+		field = ""
 		secKey = sec.Password()
 	}
 
 	if strings.HasPrefix(secKey, "otpauth://") {
-		return twofactor.FromURL(secKey)
+		t, err := FromURL(secKey)
+		if err != nil {
+			return nil, "", err
+		}
+		return t, t.label, nil
 	}
 
-	otp, err := twofactor.NewGoogleTOTP(twofactor.Pad(secKey))
-	return otp, label, err
+	t, err := fromKeys(field, secKey, sec)
+	if err != nil {
+		return nil, "", err
+	}
+	t.label = name
+	return t, name, nil
 }
 
 // WriteQRFile writes the given OTP code as a QR image to disk
 func WriteQRFile(otp twofactor.OTP, label, file string) error {
-	var qr []byte
+	var buf []byte
 	var err error
 	switch otp.Type() {
-	case twofactor.OATH_HOTP:
-		hotp := otp.(*twofactor.HOTP)
-		qr, err = hotp.QR(label)
-	case twofactor.OATH_TOTP:
-		totp := otp.(*twofactor.TOTP)
-		qr, err = totp.QR(label)
+	case twofactor.OATH_HOTP, twofactor.OATH_TOTP:
+		buf, err = qrCode(otp, label)
 	default:
 		err = fmt.Errorf("QR codes can only be generated for OATH OTPs")
 	}
@@ -62,8 +79,25 @@ func WriteQRFile(otp twofactor.OTP, label, file string) error {
 		return fmt.Errorf("failed to write qr file: %w", err)
 	}
 
-	if err := os.WriteFile(file, qr, 0600); err != nil {
+	if err := os.WriteFile(file, buf, 0600); err != nil {
 		return fmt.Errorf("failed to write QR code: %w", err)
 	}
 	return nil
 }
+
+func qrCode(otp twofactor.OTP, label string) ([]byte, error) {
+	switch t := otp.(type) {
+	case *twofactor.HOTP:
+		return t.QR(label)
+	case *twofactor.TOTP:
+		return t.QR(label)
+	case *Token:
+		code, err := qr.Encode(t.URL(label), qr.Q)
+		if err != nil {
+			return nil, err
+		}
+		return code.PNG(), nil
+	default:
+		return nil, fmt.Errorf("unsupported OTP implementation %T", otp)
+	}
+}
@@ -0,0 +1,392 @@
+package otp
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gokyle/twofactor"
+	"github.com/gopasspw/gopass/pkg/gopass"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets/secparse"
+)
+
+const (
+	defaultDigits = 6
+	defaultPeriod = 30
+	// Steam Guard codes use 5 characters from a custom alphabet
+	steamDigits   = 5
+	steamAlphabet = "23456789BCDFGHJKMNPQRTVWXY"
+)
+
+var _ twofactor.OTP = (*Token)(nil)
+
+// Token is a HOTP (RFC 4226) or TOTP (RFC 6238) token. Unlike the tokens
+// provided by twofactor it honors all parameters of otpauth URLs and supports
+// Steam Guard codes.
+type Token struct {
+	typ     twofactor.Type
+	key     []byte
+	algo    crypto.Hash
+	digits  int
+	period  uint64
+	counter uint64
+	steam   bool
+	label   string
+	issuer  string
+
+	// field and src record where the token was found in the secret. They
+	// are used to write back HOTP counters.
+	field string
+	src   string
+}
+
+// FromURL parses an otpauth URL. Besides the standard totp and hotp types it
+// supports Steam Guard tokens, either as otpauth://steam/ URL or with the
+// encoder=steam parameter.
+func FromURL(raw string) (*Token, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid otpauth URL: %w", err)
+	}
+	if u.Scheme != "otpauth" {
+		return nil, fmt.Errorf("invalid otpauth URL: unsupported scheme %q", u.Scheme)
+	}
+
+	t := newToken()
+	t.src = raw
+	t.label = strings.TrimPrefix(u.Path, "/")
+
+	switch strings.ToLower(u.Host) {
+	case "totp":
+		t.typ = twofactor.OATH_TOTP
+	case "hotp":
+		t.typ = twofactor.OATH_HOTP
+	case "steam":
+		t.typ = twofactor.OATH_TOTP
+		t.steam = true
+	default:
+		return nil, fmt.Errorf("invalid otpauth URL: unsupported type %q", u.Host)
+	}
+
+	q := u.Query()
+	secret := q.Get("secret")
+	if secret == "" {
+		return nil, fmt.Errorf("invalid otpauth URL: missing secret")
+	}
+	t.key, err = decodeKey(secret)
+	if err != nil {
+		// same as twofactor: assume the secret isn't base32 encoded
+		t.key = []byte(secret)
+	}
+
+	if err := t.apply(q.Get); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// fromKeys builds a token from the (YAML) keys of a secret, e.g.
+//
+//	totp: JBSWY3DPEHPK3PXP
+//	digits: 8
+//	algorithm: SHA256
+func fromKeys(field, secret string, sec gopass.Secret) (*Token, error) {
+	t := newToken()
+	t.field = field
+	t.src = secret
+	if field == "hotp" {
+		t.typ = twofactor.OATH_HOTP
+	}
+
+	key, err := decodeKey(secret)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTP secret: %w", err)
+	}
+	t.key = key
+
+	// the password may be anything, so we only look at the other keys if
+	// the secret is stored in a dedicated key
+	if field == "" {
+		return t, nil
+	}
+	err = t.apply(func(k string) string {
+		v, _ := sec.Get(k)
+		return v
+	})
+	return t, err
+}
+
+func newToken() *Token {
+	return &Token{
+		typ:    twofactor.OATH_TOTP,
+		algo:   crypto.SHA1,
+		digits: defaultDigits,
+		period: defaultPeriod,
+	}
+}
+
+// apply reads the optional token parameters
+func (t *Token) apply(get func(string) string) error {
+	t.issuer = get("issuer")
+
+	if v := get("encoder"); strings.EqualFold(v, "steam") {
+		t.steam = true
+	}
+
+	if v := get("algorithm"); v != "" {
+		switch strings.ToUpper(strings.ReplaceAll(v, "-", "")) {
+		case "SHA1":
+			t.algo = crypto.SHA1
+		case "SHA256":
+			t.algo = crypto.SHA256
+		case "SHA512":
+			t.algo = crypto.SHA512
+		default:
+			return fmt.Errorf("unsupported OTP algorithm %q", v)
+		}
+	}
+
+	if v := get("digits"); v != "" {
+		d, err := strconv.Atoi(v)
+		if err != nil || d < 1 || d > 10 {
+			return fmt.Errorf("invalid number of OTP digits %q", v)
+		}
+		t.digits = d
+	}
+
+	if v := get("period"); v != "" {
+		p, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || p < 1 {
+			return fmt.Errorf("invalid OTP period %q", v)
+		}
+		t.period = p
+	}
+
+	if v := get("counter"); v != "" {
+		c, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid HOTP counter %q", v)
+		}
+		t.counter = c
+	}
+
+	if t.steam {
+		t.digits = steamDigits
+	}
+	return nil
+}
+
+func decodeKey(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	return base32.StdEncoding.DecodeString(twofactor.Pad(strings.TrimRight(secret, "=")))
+}
+
+// Type returns the type of the token
+func (t *Token) Type() twofactor.Type {
+	return t.typ
+}
+
+// Counter returns the HOTP counter. For TOTP tokens it returns the current
+// time step.
+func (t *Token) Counter() uint64 {
+	if t.typ == twofactor.OATH_TOTP {
+		return t.step(time.Now())
+	}
+	return t.counter
+}
+
+// SetCounter sets the HOTP counter
+func (t *Token) SetCounter(c uint64) {
+	t.counter = c
+}
+
+// Key returns the shared secret
+func (t *Token) Key() []byte {
+	return t.key
+}
+
+// Size returns the length of the generated codes
+func (t *Token) Size() int {
+	return t.digits
+}
+
+// Hash returns the hash function used for the HMAC
+func (t *Token) Hash() func() hash.Hash {
+	switch t.algo {
+	case crypto.SHA256:
+		return sha256.New
+	case crypto.SHA512:
+		return sha512.New
+	default:
+		return sha1.New
+	}
+}
+
+// Period returns the validity of a TOTP code. It's zero for HOTP tokens.
+func (t *Token) Period() time.Duration {
+	if t.typ != twofactor.OATH_TOTP {
+		return 0
+	}
+	return time.Duration(t.period) * time.Second
+}
+
+// Label returns the label of the token
+func (t *Token) Label() string {
+	return t.label
+}
+
+// Issuer returns the issuer of the token, if known
+func (t *Token) Issuer() string {
+	return t.issuer
+}
+
+// OTP returns the current code. For HOTP tokens this increments the counter,
+// use UpdateCounter to persist it.
+func (t *Token) OTP() string {
+	if t.typ == twofactor.OATH_TOTP {
+		return t.At(time.Now())
+	}
+	code := t.generate(t.counter)
+	t.counter++
+	return code
+}
+
+// At returns the TOTP code for the given time
+func (t *Token) At(now time.Time) string {
+	return t.generate(t.step(now))
+}
+
+func (t *Token) step(now time.Time) uint64 {
+	return uint64(now.Unix()) / t.period
+}
+
+func (t *Token) generate(counter uint64) string {
+	var ctr [8]byte
+	binary.BigEndian.PutUint64(ctr[:], counter)
+
+	h := hmac.New(t.Hash(), t.key)
+	_, _ = h.Write(ctr[:])
+	sum := h.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	if t.steam {
+		out := make([]byte, steamDigits)
+		for i := range out {
+			out[i] = steamAlphabet[code%uint32(len(steamAlphabet))]
+			code /= uint32(len(steamAlphabet))
+		}
+		return string(out)
+	}
+
+	mod := uint64(1)
+	for i := 0; i < t.digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", t.digits, uint64(code)%mod)
+}
+
+// URL returns an otpauth URL for this token
+func (t *Token) URL(label string) string {
+	typ := "totp"
+	if t.typ == twofactor.OATH_HOTP {
+		typ = "hotp"
+	}
+
+	v := url.Values{}
+	v.Set("secret", strings.TrimRight(base32.StdEncoding.EncodeToString(t.key), "="))
+	if t.issuer != "" {
+		v.Set("issuer", t.issuer)
+	}
+	switch t.algo {
+	case crypto.SHA256:
+		v.Set("algorithm", "SHA256")
+	case crypto.SHA512:
+		v.Set("algorithm", "SHA512")
+	}
+	if t.steam {
+		v.Set("encoder", "steam")
+	} else if t.digits != defaultDigits {
+		v.Set("digits", strconv.Itoa(t.digits))
+	}
+	if t.typ == twofactor.OATH_HOTP {
+		v.Set("counter", strconv.FormatUint(t.counter, 10))
+	} else if t.period != defaultPeriod {
+		v.Set("period", strconv.FormatUint(t.period, 10))
+	}
+
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     typ,
+		Path:     "/" + label,
+		RawQuery: v.Encode(),
+	}
+	return u.String()
+}
+
+// UpdateCounter writes the current counter of a HOTP token back to the secret
+// it was read from. It returns the updated secret, TOTP tokens leave the
+// secret unchanged.
+func UpdateCounter(sec gopass.Secret, t *Token) (gopass.Secret, error) {
+	if t.typ != twofactor.OATH_HOTP {
+		return sec, nil
+	}
+	ctr := strconv.FormatUint(t.counter, 10)
+
+	switch t.field {
+	case "hotp":
+		var v interface{} = ctr
+		if _, ok := sec.(*secrets.YAML); ok {
+			// keep it a number in YAML
+			v = t.counter
+		}
+		if err := sec.Set("counter", v); err != nil {
+			return nil, fmt.Errorf("failed to update counter: %w", err)
+		}
+		return sec, nil
+	case "":
+		if !strings.HasPrefix(t.src, "otpauth://") {
+			return nil, fmt.Errorf("can not store the HOTP counter, use an otpauth URL or the hotp key")
+		}
+	}
+
+	u, err := url.Parse(t.src)
+	if err != nil {
+		return nil, fmt.Errorf("invalid otpauth URL: %w", err)
+	}
+	q := u.Query()
+	q.Set("counter", ctr)
+	u.RawQuery = q.Encode()
+	nu := u.String()
+
+	if t.field == "otpauth" {
+		if v, _ := sec.Get(t.field); strings.HasPrefix(v, "//") {
+			nu = strings.TrimPrefix(nu, "otpauth:")
+		}
+		if err := sec.Set(t.field, nu); err != nil {
+			return nil, fmt.Errorf("failed to update counter: %w", err)
+		}
+		t.src = u.String()
+		return sec, nil
+	}
+
+	// the URL is part of the body (or the password)
+	nsec, err := secparse.Parse([]byte(strings.Replace(string(sec.Bytes()), t.src, nu, 1)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update counter: %w", err)
+	}
+	t.src = nu
+	return nsec, nil
+}
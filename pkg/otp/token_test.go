@@ -0,0 +1,157 @@
+package otp
+
+import (
+	"encoding/base32"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gokyle/twofactor"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets/secparse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func b32(s string) string {
+	return base32.StdEncoding.EncodeToString([]byte(s))
+}
+
+func TestTokenRFC6238(t *testing.T) {
+	// test vectors from RFC 6238, Appendix B
+	for _, tc := range []struct {
+		algo string
+		key  string
+		at   int64
+		code string
+	}{
+		{"SHA1", "12345678901234567890", 59, "94287082"},
+		{"SHA1", "12345678901234567890", 1111111109, "07081804"},
+		{"SHA256", "12345678901234567890123456789012", 59, "46119246"},
+		{"SHA256", "12345678901234567890123456789012", 1234567890, "91819424"},
+		{"SHA512", "1234567890123456789012345678901234567890123456789012345678901234", 59, "90693936"},
+		{"SHA512", "1234567890123456789012345678901234567890123456789012345678901234", 20000000000, "47863826"},
+	} {
+		u := fmt.Sprintf("otpauth://totp/test?secret=%s&algorithm=%s&digits=8", b32(tc.key), tc.algo)
+		tok, err := FromURL(u)
+		require.NoError(t, err, u)
+		assert.Equal(t, tc.code, tok.At(time.Unix(tc.at, 0)), tc.algo)
+		assert.Equal(t, 30*time.Second, tok.Period())
+	}
+
+	tok, err := FromURL("otpauth://totp/test?secret=" + b32("12345678901234567890") + "&period=60")
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, tok.Period())
+	assert.Equal(t, tok.At(time.Unix(60, 0)), tok.At(time.Unix(119, 0)))
+	assert.NotEqual(t, tok.At(time.Unix(60, 0)), tok.At(time.Unix(120, 0)))
+}
+
+func TestTokenHOTP(t *testing.T) {
+	// test vectors from RFC 4226, Appendix D
+	u := "otpauth://hotp/test?secret=" + b32("12345678901234567890")
+	tok, err := FromURL(u)
+	require.NoError(t, err)
+	assert.Equal(t, twofactor.Type(twofactor.OATH_HOTP), tok.Type())
+	assert.Equal(t, time.Duration(0), tok.Period())
+	for _, code := range []string{"755224", "287082", "359152"} {
+		assert.Equal(t, code, tok.OTP())
+	}
+	assert.Equal(t, uint64(3), tok.Counter())
+
+	tok, err = FromURL(u + "&counter=9")
+	require.NoError(t, err)
+	assert.Equal(t, "520489", tok.OTP())
+}
+
+func TestTokenSteam(t *testing.T) {
+	for _, u := range []string{
+		"otpauth://steam/Steam:user?secret=JBSWY3DPEHPK3PXP",
+		"otpauth://totp/Steam:user?secret=JBSWY3DPEHPK3PXP&encoder=steam",
+	} {
+		tok, err := FromURL(u)
+		require.NoError(t, err, u)
+		code := tok.At(time.Unix(1234567890, 0))
+		assert.Len(t, code, 5, u)
+		for _, c := range code {
+			assert.Contains(t, steamAlphabet, string(c), u)
+		}
+		assert.Contains(t, tok.URL("user"), "encoder=steam")
+	}
+}
+
+func TestTokenInvalid(t *testing.T) {
+	for _, u := range []string{
+		"http://totp/test?secret=JBSWY3DPEHPK3PXP",
+		"otpauth://motp/test?secret=JBSWY3DPEHPK3PXP",
+		"otpauth://totp/test",
+		"otpauth://totp/test?secret=JBSWY3DPEHPK3PXP&algorithm=MD5",
+		"otpauth://totp/test?secret=JBSWY3DPEHPK3PXP&digits=eleven",
+		"otpauth://totp/test?secret=JBSWY3DPEHPK3PXP&period=0",
+		"otpauth://hotp/test?secret=JBSWY3DPEHPK3PXP&counter=-1",
+	} {
+		_, err := FromURL(u)
+		assert.Error(t, err, u)
+	}
+}
+
+func TestCalculateYAMLKeys(t *testing.T) {
+	sec, err := secparse.Parse([]byte("password\n---\ntotp: " + b32("12345678901234567890") + "\ndigits: 8\nperiod: 60\n"))
+	require.NoError(t, err)
+	tok, _, err := Calculate("test", sec)
+	require.NoError(t, err)
+	assert.Equal(t, 8, tok.Size())
+	assert.Equal(t, time.Minute, tok.Period())
+}
+
+func TestUpdateCounter(t *testing.T) {
+	key := b32("12345678901234567890")
+	for _, tc := range []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "body",
+			in:   "password\notpauth://hotp/test?secret=" + key + "\n",
+			want: "counter=1",
+		},
+		{
+			name: "otpauth key",
+			in:   "password\notpauth: //hotp/test?counter=5&secret=" + key + "\n",
+			want: "counter=6",
+		},
+		{
+			name: "yaml",
+			in:   "password\n---\nhotp: " + key + "\ncounter: 3\n",
+			want: "counter: 4",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sec, err := secparse.Parse([]byte(tc.in))
+			require.NoError(t, err)
+			tok, _, err := Calculate("test", sec)
+			require.NoError(t, err)
+			first := tok.OTP()
+
+			nsec, err := UpdateCounter(sec, tok)
+			require.NoError(t, err)
+			assert.Contains(t, string(nsec.Bytes()), tc.want)
+			assert.Equal(t, "password", nsec.Password())
+
+			// the next call must not return the same code
+			tok, _, err = Calculate("test", nsec)
+			require.NoError(t, err)
+			assert.NotEqual(t, first, tok.OTP())
+			assert.False(t, strings.Contains(string(nsec.Bytes()), "\n\n\n"))
+		})
+	}
+
+	// TOTP tokens are left alone
+	sec, err := secparse.Parse([]byte("password\n" + totpURL))
+	require.NoError(t, err)
+	tok, _, err := Calculate("test", sec)
+	require.NoError(t, err)
+	nsec, err := UpdateCounter(sec, tok)
+	require.NoError(t, err)
+	assert.Equal(t, sec.Bytes(), nsec.Bytes())
+}
@@ -18,6 +18,9 @@ type Cryptic struct {
 	Length     int
 	MaxTries   int
 	Validators []func(string) error
+	// required are the character sets each password must contain one
+	// character of. They are enforced by validators and used for Entropy.
+	required []string
 }
 
 // NewCryptic creates a new generator with sane defaults.
@@ -60,6 +63,7 @@ func NewCrypticForDomain(length int, domain string) *Cryptic {
 			continue
 		}
 		debug.Log("Adding validator for %s: Requires %q -> %q", domain, req, chars)
+		c.required = append(c.required, chars)
 		c.Validators = append(c.Validators, func(pw string) error {
 			wantChars := charsFromRule(req)
 			if wantChars == "" {
@@ -0,0 +1,133 @@
+package pwgen
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// DerivedVersion identifies the key derivation and encoding of Derived. It
+// must be stored with the parameters. Changing any of the constants below
+// changes every derived password, so they require a new version.
+const DerivedVersion = "argon2id-v1"
+
+const (
+	derivedSaltPrefix = "gopass-derived-v1"
+	derivedTime       = 3
+	derivedMemory     = 64 * 1024
+	derivedThreads    = 1
+	derivedKeyLen     = 64
+	// MaxDerivedLength is the maximum length of derived passwords. Longer
+	// passwords would exhaust the derived key.
+	MaxDerivedLength = 64
+)
+
+// Derived is a stateless generator in the spirit of LessPass. The password is
+// derived from a master secret, the site, the login and a counter, so it can
+// be recomputed on any machine that knows the master secret. Increment the
+// counter to rotate the password.
+//
+// Every password contains at least one lower and upper case letter and one
+// digit, and one symbol if symbols are enabled.
+type Derived struct {
+	Site    string
+	Login   string
+	Counter int
+	Length  int
+	Symbols bool
+}
+
+// NewDerived creates a new derived generator
+func NewDerived(site, login string, counter, length int, symbols bool) *Derived {
+	return &Derived{
+		Site:    site,
+		Login:   login,
+		Counter: counter,
+		Length:  length,
+		Symbols: symbols,
+	}
+}
+
+func (d *Derived) classes() []string {
+	cls := []string{Lower, Upper, Digits}
+	if d.Symbols {
+		cls = append(cls, Syms)
+	}
+	return cls
+}
+
+func (d *Derived) validate() error {
+	if d.Site == "" {
+		return fmt.Errorf("site must not be empty")
+	}
+	if d.Counter < 1 {
+		return fmt.Errorf("counter must be at least 1")
+	}
+	if minLen := len(d.classes()); d.Length < minLen || d.Length > MaxDerivedLength {
+		return fmt.Errorf("length must be between %d and %d", minLen, MaxDerivedLength)
+	}
+	return nil
+}
+
+// salt encodes the parameters unambiguously, i.e. site "ab" with login "c"
+// differs from site "a" with login "bc"
+func (d *Derived) salt() []byte {
+	return []byte(strings.Join([]string{
+		derivedSaltPrefix,
+		d.Site,
+		d.Login,
+		strconv.Itoa(d.Counter),
+	}, "\x00"))
+}
+
+// Password derives the password from the master secret
+func (d *Derived) Password(master []byte) (string, error) {
+	if len(master) < 1 {
+		return "", fmt.Errorf("master secret must not be empty")
+	}
+	if err := d.validate(); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey(master, d.salt(), derivedTime, derivedMemory, derivedThreads, derivedKeyLen)
+	n := new(big.Int).SetBytes(key)
+	pick := func(size int) int {
+		q, r := new(big.Int).DivMod(n, big.NewInt(int64(size)), new(big.Int))
+		n = q
+		return int(r.Int64())
+	}
+
+	cls := d.classes()
+	chars := strings.Join(cls, "")
+	pw := make([]byte, 0, d.Length)
+	for i := 0; i < d.Length-len(cls); i++ {
+		pw = append(pw, chars[pick(len(chars))])
+	}
+	// insert one character of each class at a derived position
+	for _, cl := range cls {
+		c := cl[pick(len(cl))]
+		pos := pick(len(pw) + 1)
+		pw = append(pw[:pos], append([]byte{c}, pw[pos:]...)...)
+	}
+	return string(pw), nil
+}
+
+// Entropy returns the entropy of the derived passwords in bits, assuming a
+// master secret that is at least as strong. A weaker master secret limits
+// the strength of all passwords derived from it.
+func (d *Derived) Entropy() float64 {
+	if err := d.validate(); err != nil {
+		return 0
+	}
+	cls := d.classes()
+	chars := strings.Join(cls, "")
+	bits := float64(d.Length-len(cls)) * math.Log2(float64(len(chars)))
+	for _, cl := range cls {
+		bits += math.Log2(float64(len(cl)))
+	}
+	return bits
+}
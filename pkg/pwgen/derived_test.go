@@ -0,0 +1,70 @@
+package pwgen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// derivedVector is the password for the parameters in TestDerived
+const derivedVector = "89w`pv!y0PVnBC<b"
+
+func TestDerived(t *testing.T) {
+	master := []byte("correct horse battery staple")
+
+	d := NewDerived("example.com", "alice", 1, 16, true)
+	pw, err := d.Password(master)
+	require.NoError(t, err)
+	assert.Len(t, pw, 16)
+	for _, cl := range []string{Lower, Upper, Digits, Syms} {
+		assert.True(t, strings.ContainsAny(pw, cl), "%q contains one of %q", pw, cl)
+	}
+
+	// passwords must never change for the same version
+	assert.Equal(t, derivedVector, pw)
+
+	again, err := d.Password(master)
+	require.NoError(t, err)
+	assert.Equal(t, pw, again)
+
+	for _, other := range []*Derived{
+		NewDerived("example.com", "alice", 2, 16, true),
+		NewDerived("example.org", "alice", 1, 16, true),
+		NewDerived("example.com", "bob", 1, 16, true),
+		NewDerived("example.coma", "lice", 1, 16, true),
+	} {
+		opw, err := other.Password(master)
+		require.NoError(t, err)
+		assert.NotEqual(t, pw, opw, "%+v", other)
+	}
+
+	opw, err := d.Password([]byte("another master"))
+	require.NoError(t, err)
+	assert.NotEqual(t, pw, opw)
+
+	nosym := NewDerived("example.com", "alice", 1, 12, false)
+	pw, err = nosym.Password(master)
+	require.NoError(t, err)
+	assert.False(t, strings.ContainsAny(pw, Syms), pw)
+	assert.InDelta(t, 9*5.954196310386876+4.700439718141092*2+3.321928094887362, nosym.Entropy(), 0.0001)
+}
+
+func TestDerivedInvalid(t *testing.T) {
+	master := []byte("secret")
+
+	_, err := NewDerived("example.com", "", 1, 16, false).Password(nil)
+	assert.Error(t, err)
+
+	for _, d := range []*Derived{
+		NewDerived("", "alice", 1, 16, false),
+		NewDerived("example.com", "alice", 0, 16, false),
+		NewDerived("example.com", "alice", 1, 3, true),
+		NewDerived("example.com", "alice", 1, MaxDerivedLength+1, false),
+	} {
+		_, err := d.Password(master)
+		assert.Error(t, err, "%+v", d)
+		assert.Equal(t, 0.0, d.Entropy())
+	}
+}
@@ -0,0 +1,123 @@
+package pwgen
+
+import (
+	"math"
+	"math/bits"
+	"os"
+)
+
+// maxRequiredSets limits the number of required character sets considered by
+// the entropy estimation. It's exponential in the number of sets.
+const maxRequiredSets = 16
+
+// Entropy returns the entropy of the generated passwords in bits. Required
+// character sets (e.g. from password rules) are taken into account, other
+// validators are not. For generators with such validators it's an upper
+// bound.
+func (c *Cryptic) Entropy() float64 {
+	if c.Length < 1 || len(c.Chars) < 1 {
+		return 0
+	}
+
+	// characters are drawn from c.Chars byte by byte, duplicates are
+	// more likely to be picked
+	freq := make(map[byte]int, len(c.Chars))
+	for i := 0; i < len(c.Chars); i++ {
+		freq[c.Chars[i]]++
+	}
+	n := float64(len(c.Chars))
+	var perChar float64
+	for _, cnt := range freq {
+		p := float64(cnt) / n
+		perChar -= p * math.Log2(p)
+	}
+	entropy := float64(c.Length) * perChar
+
+	if len(c.required) < 1 || len(c.required) > maxRequiredSets {
+		return entropy
+	}
+
+	// the generator discards passwords that lack any of the required sets.
+	// The probability of a valid password is computed with the
+	// inclusion-exclusion principle over the sets that are missing.
+	var valid float64
+	for mask := 0; mask < 1<<len(c.required); mask++ {
+		missing := make(map[byte]bool)
+		for i, req := range c.required {
+			if mask&(1<<i) == 0 {
+				continue
+			}
+			for j := 0; j < len(req); j++ {
+				missing[req[j]] = true
+			}
+		}
+		var pMissing float64
+		for ch, cnt := range freq {
+			if missing[ch] {
+				pMissing += float64(cnt) / n
+			}
+		}
+		term := math.Pow(1-pMissing, float64(c.Length))
+		if bits.OnesCount(uint(mask))%2 == 1 {
+			term = -term
+		}
+		valid += term
+	}
+	if valid <= 0 {
+		return 0
+	}
+	return math.Max(0, entropy+math.Log2(valid))
+}
+
+// CharsetEntropy returns the entropy of GeneratePasswordCharset in bits
+func CharsetEntropy(length int, chars string) float64 {
+	c := NewCryptic(length, false)
+	c.Chars = chars
+	return c.Entropy()
+}
+
+// PasswordEntropy returns the entropy of GeneratePassword in bits
+func PasswordEntropy(length int, symbols bool) float64 {
+	chars := Digits + Upper + Lower
+	if symbols {
+		chars += Syms
+	}
+	if c := os.Getenv("GOPASS_CHARACTER_SET"); c != "" {
+		chars = c
+	}
+	return CharsetEntropy(length, chars)
+}
+
+// MemorableEntropy returns the entropy of GenerateMemorablePassword in bits.
+// The number of words depends on their lengths, so it's the expected number
+// of words times the entropy of each word.
+func MemorableEntropy(minLength int, symbols bool) float64 {
+	if minLength < 1 || len(wordlist) < 1 {
+		return 0
+	}
+
+	lengths := make(map[int]int, 16)
+	for _, w := range wordlist {
+		lengths[len(w)]++
+	}
+	suffix := 1
+	choices := float64(len(wordlist) * len(Digits))
+	if symbols {
+		suffix++
+		choices *= float64(len(Syms))
+	}
+
+	// expected number of words to reach minLength from each length
+	words := make([]float64, minLength)
+	for l := minLength - 1; l >= 0; l-- {
+		var sum float64
+		for wl, cnt := range lengths {
+			next := l + wl + suffix
+			if next < minLength {
+				sum += float64(cnt) * words[next]
+			}
+		}
+		words[l] = 1 + sum/float64(len(wordlist))
+	}
+	return words[0] * math.Log2(choices)
+}
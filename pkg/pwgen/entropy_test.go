@@ -0,0 +1,52 @@
+package pwgen
+
+import (
+	"math"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCrypticEntropy(t *testing.T) {
+	require.NoError(t, os.Unsetenv("GOPASS_CHARACTER_SET"))
+	defer func() {
+		_ = os.Unsetenv("GOPASS_CHARACTER_SET")
+	}()
+
+	assert.InDelta(t, 16*math.Log2(62), PasswordEntropy(16, false), 0.0001)
+	assert.InDelta(t, 16*math.Log2(94), PasswordEntropy(16, true), 0.0001)
+	assert.InDelta(t, 4*math.Log2(10), CharsetEntropy(4, Digits), 0.0001)
+	assert.Equal(t, 0.0, CharsetEntropy(4, "a"))
+	// duplicates are more likely
+	assert.InDelta(t, -(2.0/3*math.Log2(2.0/3) + 1.0/3*math.Log2(1.0/3)), CharsetEntropy(1, "aab"), 0.0001)
+
+	require.NoError(t, os.Setenv("GOPASS_CHARACTER_SET", "ab"))
+	assert.InDelta(t, 8, PasswordEntropy(8, true), 0.0001)
+
+	// two characters from "ab" containing a "b": aa is excluded
+	c := NewCryptic(2, false)
+	c.Chars = "ab"
+	c.required = []string{"b"}
+	assert.InDelta(t, math.Log2(3), c.Entropy(), 0.0001)
+
+	// both: only ab and ba are valid
+	c.required = []string{"a", "b"}
+	assert.InDelta(t, 1, c.Entropy(), 0.0001)
+
+	// impossible
+	c.required = []string{"c"}
+	assert.Equal(t, 0.0, c.Entropy())
+}
+
+func TestMemorableEntropy(t *testing.T) {
+	assert.Equal(t, 0.0, MemorableEntropy(0, false))
+	// a single word is enough
+	assert.InDelta(t, math.Log2(2048*10), MemorableEntropy(1, false), 0.0001)
+	assert.InDelta(t, math.Log2(2048*10*32), MemorableEntropy(1, true), 0.0001)
+	// bip39 words have at least three characters, so it takes two to four words
+	e := MemorableEntropy(12, false)
+	assert.Greater(t, e, 2*math.Log2(2048*10))
+	assert.Less(t, e, 4*math.Log2(2048*10))
+}
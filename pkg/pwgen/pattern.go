@@ -0,0 +1,196 @@
+package pwgen
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// maxPatternRepeat limits the repetition count of a single pattern element
+const maxPatternRepeat = 1024
+
+// PatternClasses are the character class placeholders of the pattern
+// generator
+var PatternClasses = map[rune]string{
+	'd': Digits,
+	'l': Lower,
+	'u': Upper,
+	'a': CharAlpha,
+	'n': CharAlphaNum,
+	's': Syms,
+	'x': CharAll,
+	'h': "0123456789abcdef",
+}
+
+// Pattern is a generator for passwords following a fixed structure, e.g.
+// four letters, a dash and four digits as required by some legacy systems.
+//
+// The pattern consists of the following elements:
+//
+//	d, l, u, a, n, s, x, h  a character of a class, see PatternClasses
+//	[a-z.]                  a character of a custom set, \ escapes ] and -
+//	\c                      the literal character c
+//	{n} or {m,n}            repeats the previous element n (m to n) times
+//
+// Any other character is used literally. For example "a{4}-d{4}" generates
+// passwords like "qXbz-0713".
+type Pattern struct {
+	parts []patternPart
+}
+
+type patternPart struct {
+	chars []rune
+	min   int
+	max   int
+}
+
+// ParsePattern parses a password pattern
+func ParsePattern(pattern string) (*Pattern, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("empty pattern")
+	}
+	p := &Pattern{}
+	in := []rune(pattern)
+	for i := 0; i < len(in); i++ {
+		r := in[i]
+		switch {
+		case r == '\\':
+			if i+1 >= len(in) {
+				return nil, fmt.Errorf("pattern ends with an escape character")
+			}
+			i++
+			p.parts = append(p.parts, patternPart{chars: []rune{in[i]}, min: 1, max: 1})
+		case r == '[':
+			end, chars, err := parsePatternSet(in, i+1)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+			p.parts = append(p.parts, patternPart{chars: chars, min: 1, max: 1})
+		case r == '{':
+			if len(p.parts) < 1 {
+				return nil, fmt.Errorf("repetition at position %d without an element", i)
+			}
+			end := i + 1
+			for end < len(in) && in[end] != '}' {
+				end++
+			}
+			if end >= len(in) {
+				return nil, fmt.Errorf("unterminated repetition at position %d", i)
+			}
+			lo, hi, err := parsePatternRepeat(string(in[i+1 : end]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid repetition at position %d: %w", i, err)
+			}
+			last := &p.parts[len(p.parts)-1]
+			last.min, last.max = lo, hi
+			i = end
+		default:
+			if chars, found := PatternClasses[r]; found {
+				p.parts = append(p.parts, patternPart{chars: []rune(chars), min: 1, max: 1})
+				continue
+			}
+			p.parts = append(p.parts, patternPart{chars: []rune{r}, min: 1, max: 1})
+		}
+	}
+	return p, nil
+}
+
+// parsePatternSet parses a custom character set starting after the opening
+// bracket. It returns the position of the closing bracket.
+func parsePatternSet(in []rune, start int) (int, []rune, error) {
+	var chars []rune
+	seen := make(map[rune]bool)
+	add := func(r rune) {
+		if !seen[r] {
+			seen[r] = true
+			chars = append(chars, r)
+		}
+	}
+	for i := start; i < len(in); i++ {
+		r := in[i]
+		switch {
+		case r == ']':
+			if len(chars) < 1 {
+				return 0, nil, fmt.Errorf("empty character set at position %d", start-1)
+			}
+			return i, chars, nil
+		case r == '\\':
+			if i+1 >= len(in) {
+				return 0, nil, fmt.Errorf("pattern ends with an escape character")
+			}
+			i++
+			add(in[i])
+		case i+2 < len(in) && in[i+1] == '-' && in[i+2] != ']':
+			to := in[i+2]
+			if to < r {
+				return 0, nil, fmt.Errorf("invalid range %c-%c at position %d", r, to, i)
+			}
+			for c := r; c <= to; c++ {
+				add(c)
+			}
+			i += 2
+		default:
+			add(r)
+		}
+	}
+	return 0, nil, fmt.Errorf("unterminated character set at position %d", start-1)
+}
+
+func parsePatternRepeat(in string) (int, int, error) {
+	p := strings.SplitN(in, ",", 2)
+	lo, err := strconv.Atoi(strings.TrimSpace(p[0]))
+	if err != nil {
+		return 0, 0, err
+	}
+	hi := lo
+	if len(p) > 1 {
+		hi, err = strconv.Atoi(strings.TrimSpace(p[1]))
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	if lo < 0 || hi < lo || hi > maxPatternRepeat {
+		return 0, 0, fmt.Errorf("count must be between 0 and %d", maxPatternRepeat)
+	}
+	return lo, hi, nil
+}
+
+// Password generates a new password matching the pattern
+func (p *Pattern) Password() string {
+	var sb strings.Builder
+	for _, part := range p.parts {
+		n := part.min
+		if part.max > part.min {
+			n += randomInteger(part.max - part.min + 1)
+		}
+		for i := 0; i < n; i++ {
+			sb.WriteRune(part.chars[randomInteger(len(part.chars))])
+		}
+	}
+	return sb.String()
+}
+
+// Entropy returns the entropy of the generated passwords in bits. For
+// variable repetitions only the minimum count is taken into account, so
+// it's a lower bound.
+func (p *Pattern) Entropy() float64 {
+	var bits float64
+	for _, part := range p.parts {
+		if len(part.chars) < 2 {
+			continue
+		}
+		bits += float64(part.min) * math.Log2(float64(len(part.chars)))
+	}
+	return bits
+}
+
+// GeneratePattern generates a password matching the given pattern
+func GeneratePattern(pattern string) (string, error) {
+	p, err := ParsePattern(pattern)
+	if err != nil {
+		return "", err
+	}
+	return p.Password(), nil
+}
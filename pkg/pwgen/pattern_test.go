@@ -0,0 +1,42 @@
+package pwgen
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ExampleGeneratePattern() {
+	fmt.Println(GeneratePattern("a{4}-d{4}"))
+}
+
+func TestPattern(t *testing.T) {
+	for _, tc := range []struct {
+		pattern string
+		re      string
+		entropy float64
+	}{
+		{pattern: "a{4}-d{4}", re: `^[a-zA-Z]{4}-[0-9]{4}$`, entropy: 4*5.700439718141092 + 4*3.321928094887362},
+		{pattern: "d", re: `^[0-9]$`, entropy: 3.321928094887362},
+		{pattern: `\d\{[ab]{2,3}`, re: `^d\{[ab]{2,3}$`, entropy: 2},
+		{pattern: "[a-c0-1]{2}", re: `^[a-c01]{2}$`, entropy: 2 * 2.321928094887362},
+		{pattern: `[a\-]`, re: `^[a-]$`, entropy: 1},
+		{pattern: "PW-h{0,2}", re: `^PW-[0-9a-f]{0,2}$`, entropy: 0},
+		{pattern: "ö{3}", re: `^ööö$`, entropy: 0},
+	} {
+		p, err := ParsePattern(tc.pattern)
+		require.NoError(t, err, tc.pattern)
+		assert.InDelta(t, tc.entropy, p.Entropy(), 0.0001, tc.pattern)
+		for i := 0; i < 20; i++ {
+			assert.Regexp(t, regexp.MustCompile(tc.re), p.Password(), tc.pattern)
+		}
+	}
+
+	for _, pattern := range []string{"", "{2}", "d{2", "d{x}", "d{3,1}", "d{4096}", "[a-", "[]", "[z-a]", `d\`} {
+		_, err := ParsePattern(pattern)
+		assert.Error(t, err, pattern)
+	}
+}
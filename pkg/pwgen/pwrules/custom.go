@@ -0,0 +1,170 @@
+package pwrules
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/gopasspw/gopass/pkg/appdir"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/fsutil"
+)
+
+// Sources of password rules, in order of precedence
+const (
+	SourceUser    = "user"
+	SourceStore   = "store"
+	SourceBuiltin = "built-in"
+)
+
+var (
+	// customRules are managed by the user, they override all other rules
+	customRules = map[string]string{}
+	// storeRules are shipped with the password stores
+	storeRules = map[string]string{}
+
+	customRulesOnce sync.Once
+)
+
+// initCustomRules loads the custom rules on first use, so importing this
+// package doesn't read any files
+func initCustomRules() {
+	customRulesOnce.Do(func() {
+		if err := loadCustomRules(); err != nil {
+			debug.Log("failed to load custom rules: %s", err)
+		}
+	})
+}
+
+func rulesFilename() string {
+	return filepath.Join(appdir.UserConfig(), "password-rules.json")
+}
+
+func loadCustomRules() error {
+	fn := rulesFilename()
+	if !fsutil.IsFile(fn) {
+		debug.Log("no custom rules found at %s", fn)
+		return nil
+	}
+	fh, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+	return json.NewDecoder(fh).Decode(&customRules)
+}
+
+func saveCustomRules() error {
+	fn := rulesFilename()
+	if err := os.MkdirAll(filepath.Dir(fn), 0700); err != nil {
+		return err
+	}
+	fh, err := os.OpenFile(fn, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+	return json.NewEncoder(fh).Encode(customRules)
+}
+
+// parseRule parses a rule and applies the defaults
+func parseRule(in string) Rule {
+	r := ParseRule(in)
+	if r.Maxlen < 1 {
+		r.Maxlen = math.MaxInt32
+	}
+	return r
+}
+
+// ValidateRule returns an error if the rule is empty or contradictory
+func ValidateRule(in string) error {
+	r := ParseRule(in)
+	if r.Minlen == 0 && r.Maxlen == 0 && r.Maxconsec == 0 && len(r.Required) == 0 && len(r.Allowed) == 0 && r.Pattern == "" {
+		return fmt.Errorf("rule %q does not contain any known property", in)
+	}
+	if r.Maxlen > 0 && r.Minlen > r.Maxlen {
+		return fmt.Errorf("minlength %d is greater than maxlength %d", r.Minlen, r.Maxlen)
+	}
+	return nil
+}
+
+// CustomRules returns the rules managed by the user
+func CustomRules() map[string]string {
+	initCustomRules()
+	return copyRules(customRules)
+}
+
+// AddCustomRule adds or replaces a rule managed by the user
+func AddCustomRule(domain, rule string) error {
+	if err := ValidateRule(rule); err != nil {
+		return err
+	}
+	initCustomRules()
+	customRules[domain] = rule
+
+	return saveCustomRules()
+}
+
+// RemoveCustomRule removes a rule managed by the user
+func RemoveCustomRule(domain string) error {
+	initCustomRules()
+	if _, found := customRules[domain]; !found {
+		return fmt.Errorf("no custom rule for %q", domain)
+	}
+	delete(customRules, domain)
+
+	return saveCustomRules()
+}
+
+// SetStoreRules replaces the rules shipped with the password stores
+func SetStoreRules(rules map[string]string) {
+	storeRules = copyRules(rules)
+}
+
+// StoreRules returns the rules shipped with the password stores
+func StoreRules() map[string]string {
+	return copyRules(storeRules)
+}
+
+// BuiltinRules returns the built-in rules
+func BuiltinRules() map[string]string {
+	return copyRules(genRules)
+}
+
+// LookupRuleSource looks up a rule like LookupRule and also returns its
+// source. User rules take precedence over store rules, which take precedence
+// over the built-in rules.
+func LookupRuleSource(domain string) (Rule, string, bool) {
+	initCustomRules()
+	domains := append([]string{domain}, LookupAliases(domain)...)
+	for _, src := range []struct {
+		name  string
+		rules map[string]string
+	}{
+		{SourceUser, customRules},
+		{SourceStore, storeRules},
+	} {
+		for _, d := range domains {
+			if v, found := src.rules[d]; found {
+				return parseRule(v), src.name, true
+			}
+		}
+	}
+	for _, d := range domains {
+		if r, found := rules[d]; found {
+			return r, SourceBuiltin, true
+		}
+	}
+	return Rule{}, "", false
+}
+
+func copyRules(in map[string]string) map[string]string {
+	out := make(map[string]string, len(in))
+	for k, v := range in {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,69 @@
+package pwrules
+
+import (
+	"math"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomRules(t *testing.T) {
+	require.NoError(t, os.Setenv("GOPASS_HOMEDIR", t.TempDir()))
+	customRules = map[string]string{}
+	customRulesOnce = sync.Once{}
+	defer func() {
+		_ = os.Unsetenv("GOPASS_HOMEDIR")
+		customRules = map[string]string{}
+		storeRules = map[string]string{}
+	}()
+
+	_, found := LookupRule("intranet.example.com")
+	assert.False(t, found)
+
+	assert.Error(t, AddCustomRule("intranet.example.com", "foo: bar;"))
+	assert.Error(t, AddCustomRule("intranet.example.com", "minlength: 20; maxlength: 10;"))
+
+	// store rules
+	SetStoreRules(map[string]string{
+		"intranet.example.com": "minlength: 8; maxlength: 16;",
+		"aa.com":               "pattern: d{6};",
+	})
+	r, src, found := LookupRuleSource("intranet.example.com")
+	assert.True(t, found)
+	assert.Equal(t, SourceStore, src)
+	assert.Equal(t, 16, r.Maxlen)
+	// store rules override built-in rules, also through aliases
+	r, src, found = LookupRuleSource("americanairlines.com")
+	assert.True(t, found)
+	assert.Equal(t, SourceStore, src)
+	assert.Equal(t, "d{6}", r.Pattern)
+
+	// user rules override store rules
+	require.NoError(t, AddCustomRule("intranet.example.com", "minlength: 10;"))
+	r, src, found = LookupRuleSource("intranet.example.com")
+	assert.True(t, found)
+	assert.Equal(t, SourceUser, src)
+	assert.Equal(t, 10, r.Minlen)
+	assert.Equal(t, math.MaxInt32, r.Maxlen)
+
+	// persisted and loaded on first use
+	customRules = map[string]string{}
+	customRulesOnce = sync.Once{}
+	assert.Equal(t, map[string]string{"intranet.example.com": "minlength: 10;"}, CustomRules())
+
+	require.NoError(t, RemoveCustomRule("intranet.example.com"))
+	assert.Error(t, RemoveCustomRule("intranet.example.com"))
+	_, src, _ = LookupRuleSource("intranet.example.com")
+	assert.Equal(t, SourceStore, src)
+
+	SetStoreRules(nil)
+	_, found = LookupRule("aa.com")
+	assert.False(t, found)
+	_, src, found = LookupRuleSource("apple.com")
+	assert.True(t, found)
+	assert.Equal(t, SourceBuiltin, src)
+	assert.NotEmpty(t, BuiltinRules()["apple.com"])
+}
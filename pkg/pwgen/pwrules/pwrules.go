@@ -1,7 +1,6 @@
 package pwrules
 
 import (
-	"math"
 	"regexp"
 	"sort"
 	"strconv"
@@ -23,11 +22,8 @@ func init() {
 		if _, found := rules[k]; found {
 			continue
 		}
-		r := ParseRule(v)
+		r := parseRule(v)
 		r.Exact = genRulesExact[k]
-		if r.Maxlen < 1 {
-			r.Maxlen = math.MaxInt32
-		}
 		rules[k] = r
 		debug.Log("added rule for %q from %q: %+v", k, v, r)
 	}
@@ -39,18 +35,11 @@ func AllRules() map[string]Rule {
 }
 
 // LookupRule looks up a rule either directly or through one of it's know
-// aliases.
+// aliases. Custom rules of the user and the stores take precedence over the
+// built-in rules.
 func LookupRule(domain string) (Rule, bool) {
-	r, found := rules[domain]
-	if found {
-		return r, true
-	}
-	for _, alias := range LookupAliases(domain) {
-		if r, found := rules[alias]; found {
-			return r, true
-		}
-	}
-	return Rule{}, false
+	r, _, found := LookupRuleSource(domain)
+	return r, found
 }
 
 // Rule is a password rule as defined by Apple at https://developer.apple.com/password-rules/
@@ -61,6 +50,9 @@ type Rule struct {
 	Allowed   []string
 	Maxconsec int
 	Exact     bool
+	// Pattern is used with the pattern generator instead of the other rules,
+	// see pwgen.Pattern
+	Pattern string
 }
 
 // ParseRule parses a password rule.
@@ -98,6 +90,8 @@ func ParseRule(in string) Rule {
 			r.Maxlen, err = strconv.Atoi(strVal)
 		case "max-consecutive":
 			r.Maxconsec, err = strconv.Atoi(strVal)
+		case "pattern":
+			r.Pattern = strings.TrimSpace(strings.Join(p[1:], ": "))
 		case "required":
 			r.Required = append(r.Required, strings.Split(strVal[0:max], ",")...)
 		case "allowed":
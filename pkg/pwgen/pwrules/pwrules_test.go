@@ -50,6 +50,14 @@ func TestParseRule(t *testing.T) {
 				Allowed:  []string{},
 			},
 		},
+		{
+			in: "pattern: a{4}-d{4};",
+			out: Rule{
+				Required: []string{},
+				Allowed:  []string{},
+				Pattern:  "a{4}-d{4}",
+			},
+		},
 	} {
 		tc := tc
 		t.Run(tc.in, func(t *testing.T) {
@@ -1,6 +1,11 @@
 package xkcdgen
 
-import "github.com/martinhoefling/goxkcdpwgen/xkcdpwgen"
+import (
+	"fmt"
+	"math"
+
+	"github.com/martinhoefling/goxkcdpwgen/xkcdpwgen"
+)
 
 // Random returns a random passphrase combined from four words
 func Random() string {
@@ -26,3 +31,24 @@ func RandomLengthDelim(length int, delim, lang string) (string, error) {
 	}
 	return string(g.GeneratePassword()), nil
 }
+
+// wordlistSizes are the number of (distinct) words of each wordlist. The
+// generator library does not expose its wordlists.
+var wordlistSizes = map[string]int{
+	"en":           7776,
+	"en_eff_short": 1296,
+	"de":           23256,
+}
+
+// Entropy returns the entropy in bits of a passphrase combined from the
+// given number of words drawn from lang
+func Entropy(length int, lang string) (float64, error) {
+	size, found := wordlistSizes[lang]
+	if !found {
+		return 0, fmt.Errorf("language %q has no matching wordlist", lang)
+	}
+	if length < 1 {
+		return 0, nil
+	}
+	return float64(length) * math.Log2(float64(size)), nil
+}
@@ -21,3 +21,17 @@ func TestRandomLengthDelim(t *testing.T) {
 	_, err := RandomLengthDelim(10, " ", "cn_ZH")
 	assert.Error(t, err)
 }
+
+func TestEntropy(t *testing.T) {
+	e, err := Entropy(4, "en")
+	assert.NoError(t, err)
+	assert.InDelta(t, 51.7, e, 0.01)
+
+	for lang := range wordlistSizes {
+		_, err := RandomLengthDelim(1, " ", lang)
+		assert.NoError(t, err, lang)
+	}
+
+	_, err = Entropy(4, "cn_ZH")
+	assert.Error(t, err)
+}
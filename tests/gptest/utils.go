@@ -53,6 +53,22 @@ func CliCtxWithFlags(ctx context.Context, t *testing.T, flags map[string]string,
 	return c
 }
 
+// CliCtxWithFlagDefs creates a new cli context with the given flag
+// definitions and parses the args. Use it for flags CliCtxWithFlags can't
+// express, e.g. slices or flags with default values.
+func CliCtxWithFlagDefs(ctx context.Context, t *testing.T, defs []cli.Flag, args ...string) *cli.Context {
+	fs := flag.NewFlagSet("default", flag.ContinueOnError)
+	for _, f := range defs {
+		assert.NoError(t, f.Apply(fs))
+	}
+	assert.NoError(t, fs.Parse(args))
+
+	c := cli.NewContext(cli.NewApp(), fs, nil)
+	c.Context = ctx
+
+	return c
+}
+
 func flagset(t *testing.T, flags map[string]string, args []string) *flag.FlagSet {
 	fs := flag.NewFlagSet("default", flag.ContinueOnError)
 	for k, v := range flags {